@@ -68,3 +68,17 @@ func GetUserByID(ctx context.Context, id string) (*User, error) {
 	}
 	return &user, nil
 }
+
+// GetUsersByIDs returns the users with the given IDs in one query; unknown IDs are skipped
+func GetUsersByIDs(ctx context.Context, ids []string) ([]*User, error) {
+	cursor, err := userCollection.Find(ctx, bson.M{"id": bson.M{"$in": ids}})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	users := []*User{}
+	if err := cursor.All(ctx, &users); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
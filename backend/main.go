@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"os"
 	"strings"
+	"time"
 
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
@@ -49,7 +50,7 @@ func CORS(next http.Handler) http.Handler {
 		
 		// Set CORS headers
 		w.Header().Set("Access-Control-Allow-Origin", allowedOrigin)
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
 		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
 		w.Header().Set("Access-Control-Allow-Credentials", "true")
 
@@ -86,9 +87,26 @@ func main() {
 	planner.Initialize(mongoClient, "zurabase")
 	auth.Initialize(mongoClient, "zurabase")
 
+	// Indexes first: installing built-in templates relies on the unique template index
+	if err := planner.CreateIndexes(context.Background()); err != nil {
+		log.Fatalf("Failed to create planner indexes: %v", err)
+	}
 	if err := planner.InitializeTemplates(context.Background()); err != nil {
 		log.Fatalf("Failed to initialize planner templates: %v", err)
 	}
+
+	// Fire time-based automation rules and create recurring cards
+	go planner.RunRuleScheduler(context.Background(), time.Minute)
+	go planner.RunRecurrenceScheduler(context.Background(), time.Minute)
+
+	// Share planner events between instances when running against a replica set
+	if os.Getenv("PLANNER_EVENT_BROKER") == "mongo" {
+		broker, err := planner.NewMongoEventBroker(context.Background(), mongoClient.Database("zurabase"))
+		if err != nil {
+			log.Fatalf("Failed to initialize planner event broker: %v", err)
+		}
+		planner.SetEventBroker(broker)
+	}
 	mux := http.NewServeMux()
 	
 	// Register health check route
@@ -145,19 +163,90 @@ func main() {
 			path := strings.TrimPrefix(r.URL.Path, "/api")
 			switch {
 			case path == "/planner/templates":
-				planner.HandleGetTemplates(w, r)
+				switch r.Method {
+				case http.MethodGet:
+					planner.HandleGetTemplates(w, r)
+				case http.MethodPost:
+					planner.HandleCreateTemplate(w, r)
+				default:
+					http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+				}
+			case path == "/planner/templates/import":
+				planner.HandleImportTemplatePackage(w, r)
+			case strings.HasPrefix(path, "/planner/templates/") && strings.HasSuffix(path, "/export"):
+				planner.HandleExportTemplatePackage(w, r)
 			case strings.HasPrefix(path, "/planner/templates/"):
-				planner.HandleGetTemplate(w, r)
+				switch r.Method {
+				case http.MethodGet:
+					planner.HandleGetTemplate(w, r)
+				case http.MethodPut:
+					planner.HandleUpdateTemplate(w, r)
+				case http.MethodDelete:
+					planner.HandleDeleteTemplate(w, r)
+				default:
+					http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+				}
+			case path == "/planner/cards/assigned":
+				planner.HandleGetAssignedCards(w, r)
+			case path == "/planner/cards/overdue":
+				planner.HandleGetOverdueCards(w, r)
+			case strings.HasPrefix(path, "/planner/card/") && strings.Contains(path, "/comments"):
+				planner.HandleCommentsRequest(w, r)
 			case path == "/planner/import":
-				planner.HandleImportPlannerMarkdown(w, r)
+				planner.HandleImportPlanner(w, r)
+			case strings.HasSuffix(path, "/members"):
+				planner.HandlePlannerMembers(w, r)
+			case strings.HasSuffix(path, "/events"):
+				planner.HandleStreamPlannerEvents(w, r)
+			case strings.HasSuffix(path, "/calendar.ics"):
+				planner.HandlePlannerCalendar(w, r)
+			case strings.HasSuffix(path, "/activity"):
+				planner.HandleGetActivity(w, r)
+			case strings.HasSuffix(path, "/undo"):
+				planner.HandleUndo(w, r)
+			case strings.HasSuffix(path, "/archive") || strings.HasSuffix(path, "/restore"):
+				planner.HandleArchive(w, r)
+			case strings.HasSuffix(path, "/settings"):
+				planner.HandlePlannerSettings(w, r)
+			case strings.HasSuffix(path, "/swimlanes"):
+				planner.HandleSwimlanes(w, r)
+			case strings.HasSuffix(path, "/rules") || strings.Contains(path, "/rules/"):
+				planner.HandleRules(w, r)
+			case strings.HasSuffix(path, "/sprints") || strings.Contains(path, "/sprints/"):
+				planner.HandleSprints(w, r)
+			case strings.HasSuffix(path, "/views") || strings.Contains(path, "/views/"):
+				planner.HandleViews(w, r)
+			case strings.HasSuffix(path, "/recurrences") || strings.Contains(path, "/recurrences/"):
+				planner.HandleRecurrences(w, r)
+			case strings.HasSuffix(path, "/save-as-template"):
+				planner.HandleSaveAsTemplate(w, r)
 			case strings.HasSuffix(path, "/export"):
-				planner.HandleExportPlannerMarkdown(w, r)
+				planner.HandleExportPlanner(w, r)
+			case strings.HasSuffix(path, "/columns/reorder"):
+				planner.HandleReorderColumns(w, r)
+			case strings.HasSuffix(path, "/column"):
+				planner.HandleAddColumn(w, r)
+			case strings.Contains(path, "/column/"):
+				switch r.Method {
+				case http.MethodPut:
+					planner.HandleUpdateColumn(w, r)
+				case http.MethodDelete:
+					planner.HandleDeleteColumn(w, r)
+				default:
+					http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+				}
 			case strings.HasSuffix(path, "/lanes/reorder"):
 				planner.HandleReorderLanes(w, r)
+			case strings.HasSuffix(path, "/metrics"):
+				planner.HandlePlannerMetrics(w, r)
+			case strings.HasSuffix(path, "/cards/bulk"):
+				planner.HandleBulkCards(w, r)
 			case strings.Contains(path, "/lane/") && strings.HasSuffix(path, "/cards/reorder"):
 				planner.HandleReorderCards(w, r)
 			case strings.Contains(path, "/lane/") && strings.HasSuffix(path, "/split"):
 				planner.HandleSplitLane(w, r)
+			case strings.Contains(path, "/lane/") && strings.HasSuffix(path, "/unsplit"):
+				planner.HandleUnsplitLane(w, r)
 			case strings.Contains(path, "/lane/") && strings.HasSuffix(path, "/card"):
 				planner.HandleAddCard(w, r)
 			case strings.Contains(path, "/lane/") && strings.Contains(path, "/card/"):
@@ -168,6 +257,8 @@ func main() {
 						planner.HandleGetCard(w, r)
 					case http.MethodPut:
 						planner.HandleUpdateCard(w, r)
+					case http.MethodPatch:
+						planner.HandlePatchCard(w, r)
 					case http.MethodDelete:
 						planner.HandleDeleteCard(w, r)
 					default:
@@ -175,6 +266,12 @@ func main() {
 					}
 					return
 				}
+			case strings.Contains(path, "/card/") && strings.HasSuffix(path, "/transitions"):
+				planner.HandleCardTransitions(w, r)
+			case strings.Contains(path, "/card/") && (strings.HasSuffix(path, "/links") || strings.Contains(path, "/links/")):
+				planner.HandleCardLinks(w, r)
+			case strings.Contains(path, "/card/") && strings.Contains(path, "/checklist"):
+				planner.HandleChecklistRequest(w, r)
 			case strings.Contains(path, "/card/") && strings.HasSuffix(path, "/move"):
 				planner.HandleMoveCard(w, r)
 			case strings.HasSuffix(path, "/lane"):
@@ -242,11 +339,19 @@ func main() {
 		{"/auth/logout", auth.HandleLogout},
 	}
 
+	meRoutes := []route{
+		{"/me/mentions", planner.HandleListMentions},
+		{"/me/mentions/", planner.HandleMarkMentionRead},
+		{"/me/calendar.ics", planner.HandleUserCalendar},
+		{"/me/calendar/token", planner.HandleCalendarToken},
+	}
+
 	// --- Register all grouped routes ---
 	// mountRoutes(mux, notesRoutes) // Removed, now explicitly registered above
 	mountRoutes(mux, plannerRoutes)
 	mountRoutes(mux, imageRoutes)
 	mountRoutes(mux, authRoutes)
+	mountRoutes(mux, meRoutes)
 
 	
 	// Register authentication routes
@@ -0,0 +1,750 @@
+package planner
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ErrNothingToUndo is returned when the caller has no operation left to undo on a planner
+var ErrNothingToUndo = errors.New("nothing to undo")
+
+// ErrCannotUndo is returned when an operation can no longer be reverted because what it touched
+// was changed or removed since. The event is marked as failed and skipped by later undos.
+var ErrCannotUndo = errors.New("cannot undo")
+
+// ActivitySnapshot captures the state of the entities touched by a mutation
+type ActivitySnapshot struct {
+	Card  *PlannerCard  `json:"card,omitempty" bson:"card,omitempty"`
+	Lane  *PlannerLane  `json:"lane,omitempty" bson:"lane,omitempty"`
+	Lanes []PlannerLane `json:"lanes,omitempty" bson:"lanes,omitempty"`
+	Links []CardLink    `json:"links,omitempty" bson:"links,omitempty"` // links removed with a deleted card or lane
+	// Comments removed with a deleted card or lane
+	Comments []CardComment `json:"comments,omitempty" bson:"comments,omitempty"`
+}
+
+// ActivityChange is a single changed attribute between two snapshots
+type ActivityChange struct {
+	Field  string      `json:"field" bson:"field"`
+	Before interface{} `json:"before,omitempty" bson:"before,omitempty"`
+	After  interface{} `json:"after,omitempty" bson:"after,omitempty"`
+}
+
+// ActivityEvent is an audit record of a card or lane mutation.
+// Action uses the same names as the published planner events.
+type ActivityEvent struct {
+	ID         string            `json:"id" bson:"id"`
+	PlannerID  string            `json:"planner_id" bson:"planner_id"`
+	ActorID    string            `json:"actor_id,omitempty" bson:"actor_id,omitempty"`
+	Action     string            `json:"action" bson:"action"`
+	TargetType string            `json:"target_type" bson:"target_type"` // card, lane, planner
+	TargetID   string            `json:"target_id" bson:"target_id"`
+	Before     *ActivitySnapshot `json:"before,omitempty" bson:"before,omitempty"`
+	After      *ActivitySnapshot `json:"after,omitempty" bson:"after,omitempty"`
+	Changes    []ActivityChange  `json:"changes,omitempty" bson:"changes,omitempty"`
+	Undone     bool              `json:"undone,omitempty" bson:"undone,omitempty"`
+	UndoneAt   *time.Time        `json:"undone_at,omitempty" bson:"undone_at,omitempty"`
+	Timestamp  time.Time         `json:"timestamp" bson:"timestamp"`
+
+	UndoFailed   bool   `json:"undo_failed,omitempty" bson:"undo_failed,omitempty"`     // reverting conflicted with later changes
+	UndoError    string `json:"undo_error,omitempty" bson:"undo_error,omitempty"`       // why the revert failed
+	UndoRevision int    `json:"undo_revision,omitempty" bson:"undo_revision,omitempty"` // card revision written by the undo
+}
+
+// ActivityPage is a page of a planner's activity log
+type ActivityPage struct {
+	Items []ActivityEvent `json:"items"`
+	Page  int             `json:"page"`
+	Limit int             `json:"limit"`
+	Total int64           `json:"total"`
+}
+
+// recordActivity stores an audit record of a mutation, logging instead of failing the mutation
+func recordActivity(ctx context.Context, plannerID, action, targetType, targetID string, before, after *ActivitySnapshot) {
+	if cardHistoryCollection == nil || plannerID == "" {
+		return
+	}
+	actorID, _ := ctx.Value("user_id").(string)
+	event := ActivityEvent{
+		ID:         GenerateID(),
+		PlannerID:  plannerID,
+		ActorID:    actorID,
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetID,
+		Before:     before,
+		After:      after,
+		Changes:    diffSnapshots(before, after),
+		Timestamp:  time.Now(),
+	}
+	if _, err := cardHistoryCollection.InsertOne(ctx, event); err != nil {
+		log.Printf("[activity] failed to record %s on %s %s: %v", action, targetType, targetID, err)
+	}
+}
+
+// diffSnapshots lists the attributes that differ between two card or lane snapshots.
+// Card fields are compared one by one; timestamps and computed values are ignored.
+func diffSnapshots(before, after *ActivitySnapshot) []ActivityChange {
+	if before == nil || after == nil {
+		return nil
+	}
+	var a, b interface{}
+	switch {
+	case before.Card != nil && after.Card != nil:
+		a, b = before.Card, after.Card
+	case before.Lane != nil && after.Lane != nil:
+		a, b = before.Lane, after.Lane
+	default:
+		return nil
+	}
+
+	flatten := func(v interface{}) map[string]interface{} {
+		m := map[string]interface{}{}
+		data, err := json.Marshal(v)
+		if err != nil {
+			return m
+		}
+		_ = json.Unmarshal(data, &m)
+		if fields, ok := m["fields"].(map[string]interface{}); ok {
+			delete(m, "fields")
+			for k, fv := range fields {
+				m["fields."+k] = fv
+			}
+		}
+		for _, ignored := range []string{"updated_at", "progress", "cards"} {
+			delete(m, ignored)
+		}
+		return m
+	}
+	am, bm := flatten(a), flatten(b)
+
+	changes := []ActivityChange{}
+	for k, av := range am {
+		if bv, ok := bm[k]; !ok || !reflect.DeepEqual(av, bv) {
+			changes = append(changes, ActivityChange{Field: k, Before: av, After: bm[k]})
+		}
+	}
+	for k, bv := range bm {
+		if _, ok := am[k]; !ok {
+			changes = append(changes, ActivityChange{Field: k, After: bv})
+		}
+	}
+	return changes
+}
+
+// getLane returns a lane and the ID of the planner it belongs to
+func getLane(ctx context.Context, laneID string) (*PlannerLane, string, error) {
+	var planner Planner
+	if err := plannerCollection.FindOne(ctx, bson.M{"lanes.id": laneID}).Decode(&planner); err != nil {
+		return nil, "", err
+	}
+	for i := range planner.Lanes {
+		if planner.Lanes[i].ID == laneID {
+			return &planner.Lanes[i], planner.ID, nil
+		}
+	}
+	return nil, "", fmt.Errorf("lane not found: %s", laneID)
+}
+
+// lanePositions returns the lanes of a planner without their cards, for position-only snapshots
+func lanePositions(planner *Planner) []PlannerLane {
+	lanes := make([]PlannerLane, 0, len(planner.Lanes))
+	for _, lane := range planner.Lanes {
+		lane.Cards = nil
+		lanes = append(lanes, lane)
+	}
+	return lanes
+}
+
+// recordLaneLayoutActivity records an operation that rearranges several lanes at once,
+// snapshotting every lane before and after so that it can be undone as a whole
+func recordLaneLayoutActivity(ctx context.Context, before *Planner, action, laneID string) {
+	var after Planner
+	if err := plannerCollection.FindOne(ctx, bson.M{"id": before.ID}).Decode(&after); err != nil {
+		log.Printf("[activity] failed to load planner %s after %s: %v", before.ID, action, err)
+		return
+	}
+
+	// Only keep the lanes the operation actually touched
+	changed := func(lanes, others []PlannerLane) []PlannerLane {
+		result := []PlannerLane{}
+		for _, lane := range lanes {
+			same := false
+			for _, other := range others {
+				if other.ID == lane.ID {
+					same = reflect.DeepEqual(other, lane)
+					break
+				}
+			}
+			if !same {
+				result = append(result, lane)
+			}
+		}
+		return result
+	}
+	recordActivity(ctx, before.ID, action, "lane", laneID,
+		&ActivitySnapshot{Lanes: changed(before.Lanes, after.Lanes)},
+		&ActivitySnapshot{Lanes: changed(after.Lanes, before.Lanes)})
+}
+
+// GetActivity returns a page of a planner's activity log, newest first
+func GetActivity(ctx context.Context, plannerID, cardID string, page, limit int) (*ActivityPage, error) {
+	log.Printf("Getting activity: plannerID=%s, cardID=%s, page=%d", plannerID, cardID, page)
+
+	filter := bson.M{"planner_id": plannerID}
+	if cardID != "" {
+		filter["target_type"] = "card"
+		filter["target_id"] = cardID
+	}
+	total, err := cardHistoryCollection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "timestamp", Value: -1}}).
+		SetSkip(int64((page - 1) * limit)).
+		SetLimit(int64(limit))
+	cursor, err := cardHistoryCollection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	items := []ActivityEvent{}
+	if err := cursor.All(ctx, &items); err != nil {
+		return nil, err
+	}
+	return &ActivityPage{Items: items, Page: page, Limit: limit, Total: total}, nil
+}
+
+// UndoLastActivity reverts the caller's most recent operation on a planner that has not been undone yet
+func UndoLastActivity(ctx context.Context, plannerID, actorID string) (*ActivityEvent, error) {
+	log.Printf("Undoing last activity: plannerID=%s, actorID=%s", plannerID, actorID)
+
+	var event ActivityEvent
+	opts := options.FindOne().SetSort(bson.D{{Key: "timestamp", Value: -1}})
+	err := cardHistoryCollection.FindOne(ctx, bson.M{
+		"planner_id":  plannerID,
+		"actor_id":    actorID,
+		"undone":      bson.M{"$ne": true},
+		"undo_failed": bson.M{"$ne": true},
+	}, opts).Decode(&event)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrNothingToUndo
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Claim the event first so that concurrent undo requests can't revert it twice
+	now := time.Now()
+	result, err := cardHistoryCollection.UpdateOne(ctx,
+		bson.M{"id": event.ID, "undone": bson.M{"$ne": true}},
+		bson.M{"$set": bson.M{"undone": true, "undone_at": now}},
+	)
+	if err != nil {
+		return nil, err
+	}
+	if result.ModifiedCount == 0 {
+		return nil, ErrNothingToUndo
+	}
+
+	if err := revertActivity(ctx, &event); err != nil {
+		release := bson.M{"$set": bson.M{"undone": false}, "$unset": bson.M{"undone_at": ""}}
+		if errors.Is(err, ErrCannotUndo) {
+			// The event will never revert, so take it off the stack instead of blocking older ones
+			err = fmt.Errorf("%s event %s: %w", event.Action, event.ID, err)
+			release["$set"] = bson.M{"undone": false, "undo_failed": true, "undo_error": err.Error()}
+		}
+		if _, uerr := cardHistoryCollection.UpdateOne(ctx, bson.M{"id": event.ID}, release); uerr != nil {
+			log.Printf("Failed to release undo of event %s: %v", event.ID, uerr)
+		}
+		return nil, err
+	}
+	if event.UndoRevision != 0 {
+		_, err := cardHistoryCollection.UpdateOne(ctx,
+			bson.M{"id": event.ID},
+			bson.M{"$set": bson.M{"undo_revision": event.UndoRevision}},
+		)
+		if err != nil {
+			log.Printf("Failed to record undo revision of event %s: %v", event.ID, err)
+		}
+	}
+
+	recordRevertTransitions(ctx, &event)
+	event.Undone = true
+	event.UndoneAt = &now
+	publishEvent(ctx, plannerID, EventActivityUndone, event)
+	return &event, nil
+}
+
+// snapshotCardLanes returns the lane of each card in a snapshot
+func snapshotCardLanes(s *ActivitySnapshot) map[string]string {
+	lanes := map[string]string{}
+	if s == nil {
+		return lanes
+	}
+	if s.Card != nil {
+		lanes[s.Card.ID] = s.Card.LaneID
+	}
+	all := s.Lanes
+	if s.Lane != nil {
+		all = append([]PlannerLane{*s.Lane}, all...)
+	}
+	for _, lane := range all {
+		for _, card := range lane.Cards {
+			lanes[card.ID] = lane.ID
+		}
+	}
+	return lanes
+}
+
+// revertedCardMoves returns the lane changes that undoing an event makes, as card ID to
+// [from, to] lane IDs. An empty lane ID means the card isn't on the board.
+func revertedCardMoves(event *ActivityEvent) map[string][2]string {
+	switch event.Action {
+	case EventCardAdded, EventCardDeleted, EventLaneAdded, EventLaneDeleted:
+	default:
+		// Only creations and deletions have a single snapshot
+		if event.Before == nil || event.After == nil {
+			return nil
+		}
+	}
+	current, restored := snapshotCardLanes(event.After), snapshotCardLanes(event.Before)
+	moves := map[string][2]string{}
+	for id, laneID := range current {
+		if restored[id] != laneID {
+			moves[id] = [2]string{laneID, restored[id]}
+		}
+	}
+	for id, laneID := range restored {
+		if _, ok := current[id]; !ok {
+			moves[id] = [2]string{"", laneID}
+		}
+	}
+	return moves
+}
+
+// recordRevertTransitions records the lane transitions of cards moved, removed or brought back by an undo
+func recordRevertTransitions(ctx context.Context, event *ActivityEvent) {
+	moves := revertedCardMoves(event)
+	if len(moves) == 0 {
+		return
+	}
+	var planner Planner
+	if err := plannerCollection.FindOne(ctx, bson.M{"id": event.PlannerID}).Decode(&planner); err != nil {
+		log.Printf("[metrics] failed to load planner %s for undo transitions: %v", event.PlannerID, err)
+		return
+	}
+	// Lanes removed by the undo are only in the snapshots
+	lanes := map[string]*PlannerLane{}
+	for _, s := range []*ActivitySnapshot{event.Before, event.After} {
+		if s == nil {
+			continue
+		}
+		if s.Lane != nil {
+			lanes[s.Lane.ID] = s.Lane
+		}
+		for i := range s.Lanes {
+			lanes[s.Lanes[i].ID] = &s.Lanes[i]
+		}
+	}
+	for i := range planner.Lanes {
+		lanes[planner.Lanes[i].ID] = &planner.Lanes[i]
+	}
+	lane := func(id string) *PlannerLane {
+		if id == "" {
+			return nil
+		}
+		if l, ok := lanes[id]; ok {
+			return l
+		}
+		return &PlannerLane{ID: id}
+	}
+	for cardID, move := range moves {
+		recordTransition(ctx, event.PlannerID, cardID, lane(move[0]), lane(move[1]))
+	}
+}
+
+// cardSwapUpdate returns an update pipeline that removes card from whichever lane holds it and
+// inserts it into its LaneID. With closeGap, the cards after closeGap's position in its lane move
+// up one place, undoing the room a move made for it. Cards are inserted as literals so that
+// values starting with $ aren't read as field paths.
+func cardSwapUpdate(card, closeGap *PlannerCard) []bson.M {
+	var cards interface{} = bson.M{"$filter": bson.M{
+		"input": bson.M{"$ifNull": bson.A{"$$lane.cards", bson.A{}}},
+		"as":    "card",
+		"cond":  bson.M{"$ne": bson.A{"$$card.id", card.ID}},
+	}}
+	if closeGap != nil {
+		cards = bson.M{"$map": bson.M{
+			"input": cards,
+			"as":    "card",
+			"in": bson.M{"$cond": bson.A{
+				bson.M{"$and": bson.A{
+					bson.M{"$eq": bson.A{"$$lane.id", closeGap.LaneID}},
+					bson.M{"$gt": bson.A{"$$card.position", closeGap.Position}},
+				}},
+				bson.M{"$mergeObjects": bson.A{"$$card", bson.M{"position": bson.M{"$subtract": bson.A{"$$card.position", 1}}}}},
+				"$$card",
+			}},
+		}}
+	}
+	return []bson.M{{"$set": bson.M{"lanes": bson.M{"$map": bson.M{
+		"input": "$lanes",
+		"as":    "lane",
+		"in": bson.M{"$mergeObjects": bson.A{"$$lane", bson.M{"cards": bson.M{"$concatArrays": bson.A{
+			cards,
+			bson.M{"$cond": bson.A{
+				bson.M{"$eq": bson.A{"$$lane.id", card.LaneID}},
+				bson.M{"$literal": []PlannerCard{*card}},
+				bson.A{},
+			}},
+		}}}}},
+	}}}}}
+}
+
+// laneSwapUpdate returns an update pipeline that replaces the lanes with the given IDs by lanes in one write
+func laneSwapUpdate(laneIDs []string, lanes []PlannerLane) []bson.M {
+	return []bson.M{{"$set": bson.M{"lanes": bson.M{"$concatArrays": bson.A{
+		bson.M{"$filter": bson.M{
+			"input": bson.M{"$ifNull": bson.A{"$lanes", bson.A{}}},
+			"as":    "lane",
+			"cond":  bson.M{"$not": bson.A{bson.M{"$in": bson.A{"$$lane.id", laneIDs}}}},
+		}},
+		bson.M{"$literal": lanes},
+	}}}}}
+}
+
+// revertCardChanges returns current with the attributes that changed between before and after set
+// back to their before values, leaving changes made by other operations in place
+func revertCardChanges(current, before, after *PlannerCard) *PlannerCard {
+	restored := *current
+	restored.Fields = map[string]interface{}{}
+	for k, v := range current.Fields {
+		restored.Fields[k] = v
+	}
+	for k := range mergeKeys(before.Fields, after.Fields) {
+		bv, inBefore := before.Fields[k]
+		if av, inAfter := after.Fields[k]; inBefore == inAfter && reflect.DeepEqual(bv, av) {
+			continue
+		}
+		if inBefore {
+			restored.Fields[k] = bv
+		} else {
+			delete(restored.Fields, k)
+		}
+	}
+	revertField(&restored.LaneID, before.LaneID, after.LaneID)
+	revertField(&restored.Position, before.Position, after.Position)
+	revertField(&restored.Assignees, before.Assignees, after.Assignees)
+	revertField(&restored.DueDate, before.DueDate, after.DueDate)
+	revertField(&restored.StartDate, before.StartDate, after.StartDate)
+	revertField(&restored.Priority, before.Priority, after.Priority)
+	revertField(&restored.Labels, before.Labels, after.Labels)
+	revertField(&restored.Checklist, before.Checklist, after.Checklist)
+	revertField(&restored.ArchivedAt, before.ArchivedAt, after.ArchivedAt)
+	revertField(&restored.SprintID, before.SprintID, after.SprintID)
+	restored.UpdatedAt = time.Now()
+	return &restored
+}
+
+// revertField sets *dst back to before when an operation changed it from before to after
+func revertField[T any](dst *T, before, after T) {
+	if !reflect.DeepEqual(before, after) {
+		*dst = before
+	}
+}
+
+// mergeKeys returns the keys present in either map
+func mergeKeys(a, b map[string]interface{}) map[string]struct{} {
+	keys := map[string]struct{}{}
+	for k := range a {
+		keys[k] = struct{}{}
+	}
+	for k := range b {
+		keys[k] = struct{}{}
+	}
+	return keys
+}
+
+// expectedCardRevision returns the revision a card must still have for event to be reverted: the one
+// the event left, or the one written by undoing the latest of the card's later events
+func expectedCardRevision(ctx context.Context, event *ActivityEvent, card *PlannerCard) (int, error) {
+	var later ActivityEvent
+	opts := options.FindOne().SetSort(bson.D{{Key: "undone_at", Value: -1}})
+	err := cardHistoryCollection.FindOne(ctx, bson.M{
+		"planner_id":    event.PlannerID,
+		"target_id":     card.ID,
+		"timestamp":     bson.M{"$gt": event.Timestamp},
+		"undo_revision": bson.M{"$exists": true},
+	}, opts).Decode(&later)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return card.Revision, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return later.UndoRevision, nil
+}
+
+// revertActivity restores the "before" state recorded by an activity event
+func revertActivity(ctx context.Context, event *ActivityEvent) error {
+	plannerFilter := bson.M{"id": event.PlannerID}
+	before, after := event.Before, event.After
+	if before == nil {
+		before = &ActivitySnapshot{}
+	}
+	if after == nil {
+		after = &ActivitySnapshot{}
+	}
+
+	pullCard := func(cardID string) error {
+		_, err := plannerCollection.UpdateOne(ctx, plannerFilter,
+			bson.M{"$pull": bson.M{"lanes.$[].cards": bson.M{"id": cardID}}})
+		return err
+	}
+	pushCard := func(card *PlannerCard) error {
+		result, err := plannerCollection.UpdateOne(ctx,
+			bson.M{"id": event.PlannerID, "lanes.id": card.LaneID},
+			bson.M{"$push": bson.M{"lanes.$.cards": card}})
+		if err != nil {
+			return err
+		}
+		if result.MatchedCount == 0 {
+			return fmt.Errorf("%w: lane %s no longer exists", ErrCannotUndo, card.LaneID)
+		}
+		return nil
+	}
+	// revertCard sets the attributes the event changed back in one write, provided the card is
+	// unchanged since and its lane still exists
+	revertCard := func(closeGap *PlannerCard) error {
+		current, err := GetCard(ctx, before.Card.ID)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return fmt.Errorf("%w: card %s no longer exists", ErrCannotUndo, before.Card.ID)
+		}
+		if err != nil {
+			return err
+		}
+		revision, err := expectedCardRevision(ctx, event, after.Card)
+		if err != nil {
+			return err
+		}
+		if current.Revision != revision {
+			return fmt.Errorf("%w: card %s was changed since", ErrCannotUndo, current.ID)
+		}
+		// The restored version is a new revision, so calendar clients pick it up
+		restored := revertCardChanges(current, before.Card, after.Card)
+		restored.Revision = current.Revision + 1
+		result, err := plannerCollection.UpdateOne(ctx,
+			bson.M{
+				"id":          event.PlannerID,
+				"lanes.id":    restored.LaneID,
+				"lanes.cards": bson.M{"$elemMatch": bson.M{"id": current.ID, "revision": current.Revision}},
+			},
+			cardSwapUpdate(restored, closeGap))
+		if err != nil {
+			return err
+		}
+		if result.MatchedCount == 0 {
+			return fmt.Errorf("%w: card %s or lane %s was changed since", ErrCannotUndo, current.ID, restored.LaneID)
+		}
+		event.UndoRevision = restored.Revision
+		return nil
+	}
+	replaceLanes := func(laneIDs []string, lanes []PlannerLane) error {
+		_, err := plannerCollection.UpdateOne(ctx, plannerFilter, laneSwapUpdate(laneIDs, lanes))
+		return err
+	}
+
+	switch event.Action {
+	case EventCardAdded:
+		if after.Card == nil {
+			break
+		}
+		return pullCard(after.Card.ID)
+	case EventCardUpdated, EventCardArchived, EventCardRestored:
+		if before.Card == nil || after.Card == nil {
+			break
+		}
+		return revertCard(nil)
+	case EventCardMoved:
+		if before.Card == nil || after.Card == nil {
+			break
+		}
+		// Moving shifted the cards below the target position down, so move them back up
+		return revertCard(after.Card)
+	case EventCardDeleted:
+		if before.Card == nil {
+			break
+		}
+		if err := pushCard(before.Card); err != nil {
+			return err
+		}
+		if err := restoreCardComments(ctx, before.Comments); err != nil {
+			return err
+		}
+		return restoreCardLinks(ctx, before.Links)
+	case EventCardsReordered:
+		if before.Lane == nil {
+			break
+		}
+		for _, card := range before.Lane.Cards {
+			_, err := plannerCollection.UpdateOne(ctx, plannerFilter,
+				bson.M{"$set": bson.M{"lanes.$[].cards.$[elem].position": card.Position}},
+				options.Update().SetArrayFilters(options.ArrayFilters{
+					Filters: []interface{}{bson.M{"elem.id": card.ID}},
+				}),
+			)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	case EventLaneAdded:
+		if after.Lane == nil {
+			break
+		}
+		_, err := plannerCollection.UpdateOne(ctx, plannerFilter,
+			bson.M{"$pull": bson.M{"lanes": bson.M{"id": after.Lane.ID}}})
+		return err
+	case EventLaneUpdated:
+		if before.Lane == nil {
+			break
+		}
+		_, err := plannerCollection.UpdateOne(ctx,
+			bson.M{"id": event.PlannerID, "lanes.id": before.Lane.ID},
+			bson.M{"$set": bson.M{
+				"lanes.$.title":       before.Lane.Title,
+				"lanes.$.description": before.Lane.Description,
+				"lanes.$.color":       before.Lane.Color,
+				"lanes.$.wip_limit":   before.Lane.WIPLimit,
+				"lanes.$.category":    before.Lane.Category,
+				"lanes.$.updated_at":  time.Now(),
+			}})
+		return err
+	case EventLaneDeleted:
+		if before.Lane == nil {
+			break
+		}
+		_, err := plannerCollection.UpdateOne(ctx, plannerFilter,
+			bson.M{"$push": bson.M{"lanes": before.Lane}})
+		if err != nil {
+			return err
+		}
+		if err := restoreCardComments(ctx, before.Comments); err != nil {
+			return err
+		}
+		return restoreCardLinks(ctx, before.Links)
+	case EventLaneArchived, EventLaneRestored, EventLaneCardsArchived:
+		if before.Lane == nil {
+			break
+		}
+		return replaceLanes([]string{before.Lane.ID}, []PlannerLane{*before.Lane})
+	case EventLanesReordered:
+		for _, lane := range before.Lanes {
+			_, err := plannerCollection.UpdateOne(ctx,
+				bson.M{"id": event.PlannerID, "lanes.id": lane.ID},
+				bson.M{"$set": bson.M{"lanes.$.position": lane.Position}})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	case EventLaneSplit, EventLaneUnsplit:
+		// Replace every lane touched by the operation with its previous version
+		laneIDs := []string{}
+		for _, lane := range append(append([]PlannerLane{}, before.Lanes...), after.Lanes...) {
+			if !containsString(laneIDs, lane.ID) {
+				laneIDs = append(laneIDs, lane.ID)
+			}
+		}
+		return replaceLanes(laneIDs, before.Lanes)
+	}
+	return fmt.Errorf("%w: no snapshot recorded", ErrCannotUndo)
+}
+
+// HandleGetActivity handles GET /planner/{id}/activity?page={n}&limit={n}&card_id={id}
+func HandleGetActivity(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Extract planner ID from path
+	path := r.URL.Path
+	parts := strings.Split(path, "/")
+	if len(parts) != 4 || parts[1] != "planner" || parts[3] != "activity" {
+		http.Error(w, "Invalid path", http.StatusBadRequest)
+		return
+	}
+	plannerID := parts[2]
+
+	page, limit := 1, 50
+	if v, err := strconv.Atoi(r.URL.Query().Get("page")); err == nil && v > 0 {
+		page = v
+	}
+	if v, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && v > 0 && v <= 200 {
+		limit = v
+	}
+
+	activity, err := GetActivity(r.Context(), plannerID, r.URL.Query().Get("card_id"), page, limit)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(activity); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// HandleUndo handles POST /planner/{id}/undo
+func HandleUndo(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	userID, _ := r.Context().Value("user_id").(string)
+	if userID == "" {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// Extract planner ID from path
+	path := r.URL.Path
+	parts := strings.Split(path, "/")
+	if len(parts) != 4 || parts[1] != "planner" || parts[3] != "undo" {
+		http.Error(w, "Invalid path", http.StatusBadRequest)
+		return
+	}
+	plannerID := parts[2]
+
+	event, err := UndoLastActivity(r.Context(), plannerID, userID)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, ErrNothingToUndo) || errors.Is(err, ErrCannotUndo) {
+			status = http.StatusConflict
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(event); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,220 @@
+package planner
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestDiffSnapshots(t *testing.T) {
+	now := time.Now()
+	before := &PlannerCard{ID: "c1", LaneID: "l1", Position: 1, UpdatedAt: now,
+		Fields: map[string]interface{}{FieldTitle: "Old", "points": 3.0, "gone": "x"}}
+	after := &PlannerCard{ID: "c1", LaneID: "l2", Position: 1, UpdatedAt: now.Add(time.Minute), Progress: 50,
+		Fields: map[string]interface{}{FieldTitle: "New", "points": 3.0, "added": "y"}}
+
+	changes := diffSnapshots(&ActivitySnapshot{Card: before}, &ActivitySnapshot{Card: after})
+	fields := []string{}
+	for _, c := range changes {
+		fields = append(fields, c.Field)
+		if c.Field == "fields.title" && (c.Before != "Old" || c.After != "New") {
+			t.Errorf("unexpected title change %+v", c)
+		}
+		if c.Field == "fields.gone" && c.After != nil {
+			t.Errorf("removed field should have no after value, got %+v", c)
+		}
+	}
+	sort.Strings(fields)
+	want := []string{"fields.added", "fields.gone", "fields.title", "lane_id"}
+	if len(fields) != len(want) {
+		t.Fatalf("expected changes %v, got %v", want, fields)
+	}
+	for i := range want {
+		if fields[i] != want[i] {
+			t.Errorf("expected changes %v, got %v", want, fields)
+			break
+		}
+	}
+
+	lanes := diffSnapshots(&ActivitySnapshot{Lane: &PlannerLane{ID: "l1", Title: "A", Cards: []PlannerCard{{ID: "c1"}}}},
+		&ActivitySnapshot{Lane: &PlannerLane{ID: "l1", Title: "B"}})
+	if len(lanes) != 1 || lanes[0].Field != "title" {
+		t.Errorf("expected only the lane title to change, got %+v", lanes)
+	}
+	if diffSnapshots(nil, &ActivitySnapshot{Card: after}) != nil {
+		t.Error("creations have no changes")
+	}
+	if diffSnapshots(&ActivitySnapshot{Card: before}, &ActivitySnapshot{Lane: &PlannerLane{}}) != nil {
+		t.Error("snapshots of different kinds have no changes")
+	}
+}
+
+func TestCardSwapUpdate(t *testing.T) {
+	card := &PlannerCard{ID: "c1", LaneID: "l1", Position: 2, Fields: map[string]interface{}{FieldContent: "$notAPath"}}
+
+	update := cardSwapUpdate(card, nil)
+	if len(update) != 1 {
+		t.Fatalf("expected a single $set stage, got %v", update)
+	}
+	in := update[0]["$set"].(bson.M)["lanes"].(bson.M)["$map"].(bson.M)["in"].(bson.M)
+	parts := in["$mergeObjects"].(bson.A)[1].(bson.M)["cards"].(bson.M)["$concatArrays"].(bson.A)
+	if _, ok := parts[0].(bson.M)["$filter"]; !ok {
+		t.Errorf("without a gap to close the lane's cards should only be filtered, got %v", parts[0])
+	}
+	inserted := parts[1].(bson.M)["$cond"].(bson.A)[1].(bson.M)["$literal"].([]PlannerCard)
+	if len(inserted) != 1 || inserted[0].ID != "c1" {
+		t.Errorf("expected the card to be inserted as a literal, got %v", inserted)
+	}
+
+	update = cardSwapUpdate(card, &PlannerCard{ID: "c1", LaneID: "l2", Position: 0})
+	in = update[0]["$set"].(bson.M)["lanes"].(bson.M)["$map"].(bson.M)["in"].(bson.M)
+	parts = in["$mergeObjects"].(bson.A)[1].(bson.M)["cards"].(bson.M)["$concatArrays"].(bson.A)
+	if _, ok := parts[0].(bson.M)["$map"]; !ok {
+		t.Errorf("expected positions after the gap to be shifted back, got %v", parts[0])
+	}
+}
+
+func TestRevertedCardMoves(t *testing.T) {
+	card := func(id, lane string) *PlannerCard { return &PlannerCard{ID: id, LaneID: lane} }
+	cases := []struct {
+		name  string
+		event ActivityEvent
+		want  map[string][2]string
+	}{
+		{"move", ActivityEvent{Action: EventCardMoved,
+			Before: &ActivitySnapshot{Card: card("c1", "l1")}, After: &ActivitySnapshot{Card: card("c1", "l2")}},
+			map[string][2]string{"c1": {"l2", "l1"}}},
+		{"update", ActivityEvent{Action: EventCardUpdated,
+			Before: &ActivitySnapshot{Card: card("c1", "l1")}, After: &ActivitySnapshot{Card: card("c1", "l1")}},
+			map[string][2]string{}},
+		{"add", ActivityEvent{Action: EventCardAdded, After: &ActivitySnapshot{Card: card("c1", "l1")}},
+			map[string][2]string{"c1": {"l1", ""}}},
+		{"lane delete", ActivityEvent{Action: EventLaneDeleted,
+			Before: &ActivitySnapshot{Lane: &PlannerLane{ID: "l1", Cards: []PlannerCard{{ID: "c1"}, {ID: "c2"}}}}},
+			map[string][2]string{"c1": {"", "l1"}, "c2": {"", "l1"}}},
+		{"unsplit", ActivityEvent{Action: EventLaneUnsplit,
+			Before: &ActivitySnapshot{Lanes: []PlannerLane{{ID: "l1", Cards: []PlannerCard{{ID: "c1"}}}, {ID: "l2", Cards: []PlannerCard{{ID: "c2"}}}}},
+			After:  &ActivitySnapshot{Lanes: []PlannerLane{{ID: "l1", Cards: []PlannerCard{{ID: "c1"}, {ID: "c2"}}}}}},
+			map[string][2]string{"c2": {"l1", "l2"}}},
+		{"one-sided reorder", ActivityEvent{Action: EventLanesReordered,
+			Before: &ActivitySnapshot{Lanes: []PlannerLane{{ID: "l1", Cards: []PlannerCard{{ID: "c1"}}}}}},
+			nil},
+	}
+	for _, c := range cases {
+		got := revertedCardMoves(&c.event)
+		if len(got) != len(c.want) {
+			t.Errorf("%s: expected %v, got %v", c.name, c.want, got)
+			continue
+		}
+		for id, move := range c.want {
+			if got[id] != move {
+				t.Errorf("%s: card %s expected %v, got %v", c.name, id, move, got[id])
+			}
+		}
+	}
+}
+
+func TestRevertCardChanges(t *testing.T) {
+	due := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	before := &PlannerCard{ID: "c1", LaneID: "l1", Position: 2, Priority: "low", Revision: 1,
+		Fields: map[string]interface{}{FieldTitle: "Old", "points": 3.0}}
+	after := &PlannerCard{ID: "c1", LaneID: "l1", Position: 2, Priority: "high", DueDate: &due, Revision: 2,
+		Fields: map[string]interface{}{FieldTitle: "New", "points": 3.0, "added": "x"}}
+	// Another operation changed the labels and points since, which the revert must keep
+	current := *after
+	current.Labels = []string{"urgent"}
+	current.Fields = map[string]interface{}{FieldTitle: "New", "points": 5.0, "added": "x"}
+
+	restored := revertCardChanges(&current, before, after)
+	if restored.Priority != "low" || restored.DueDate != nil {
+		t.Errorf("expected priority and due date reverted, got %q and %v", restored.Priority, restored.DueDate)
+	}
+	if restored.Fields[FieldTitle] != "Old" {
+		t.Errorf("expected title reverted, got %v", restored.Fields[FieldTitle])
+	}
+	if _, ok := restored.Fields["added"]; ok {
+		t.Errorf("expected the added field removed, got %v", restored.Fields)
+	}
+	if restored.Fields["points"] != 5.0 || len(restored.Labels) != 1 {
+		t.Errorf("expected later changes kept, got points %v and labels %v", restored.Fields["points"], restored.Labels)
+	}
+	if current.Fields[FieldTitle] != "New" {
+		t.Errorf("expected the current card left untouched")
+	}
+}
+
+func TestUndoSkipsFailedRevert(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	mt.Run("failed revert", func(mt *mtest.T) {
+		defer func(p, h *mongo.Collection) { plannerCollection, cardHistoryCollection = p, h }(plannerCollection, cardHistoryCollection)
+		plannerCollection = mt.DB.Collection("planners")
+		cardHistoryCollection = mt.DB.Collection("card_history")
+
+		now := time.Now()
+		card := PlannerCard{ID: "c1", LaneID: "l1", Revision: 3, Fields: map[string]interface{}{FieldTitle: "Old"}}
+		updated := card
+		updated.Revision = 4
+		updated.Fields = map[string]interface{}{FieldTitle: "New"}
+		stale := ActivityEvent{ID: "e2", PlannerID: "p1", ActorID: "u1", Action: EventCardUpdated, TargetType: "card",
+			TargetID: "c1", Before: &ActivitySnapshot{Card: &card}, After: &ActivitySnapshot{Card: &updated}, Timestamp: now}
+		older := ActivityEvent{ID: "e1", PlannerID: "p1", ActorID: "u1", Action: EventCardAdded, TargetType: "card",
+			TargetID: "c0", After: &ActivitySnapshot{Card: &PlannerCard{ID: "c0", LaneID: "l1"}}, Timestamp: now.Add(-time.Minute)}
+		// Someone else edited the card after the update, so it can't be reverted
+		edited := updated
+		edited.Revision = 5
+
+		doc := func(v interface{}) bson.D {
+			data, err := bson.Marshal(v)
+			if err != nil {
+				mt.Fatal(err)
+			}
+			var d bson.D
+			if err := bson.Unmarshal(data, &d); err != nil {
+				mt.Fatal(err)
+			}
+			return d
+		}
+		updateOK := mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 1})
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(0, "test.card_history", mtest.FirstBatch, doc(stale)),
+			updateOK,
+			mtest.CreateCursorResponse(0, "test.planners", mtest.FirstBatch, doc(edited)),
+			mtest.CreateCursorResponse(0, "test.card_history", mtest.FirstBatch),
+			updateOK,
+		)
+		if _, err := UndoLastActivity(context.Background(), "p1", "u1"); !errors.Is(err, ErrCannotUndo) || !strings.Contains(err.Error(), "e2") {
+			t.Fatalf("expected the stale revert to fail naming e2, got %v", err)
+		}
+		mark := mt.GetAllStartedEvents()
+		update := mark[len(mark)-1].Command.Lookup("updates").Array().Index(0).Value().Document().Lookup("u").Document()
+		if failed, ok := update.Lookup("$set", "undo_failed").BooleanOK(); !ok || !failed {
+			t.Fatalf("expected the event marked as failed, got %v", update)
+		}
+
+		mt.ClearEvents()
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(0, "test.card_history", mtest.FirstBatch, doc(older)),
+			updateOK,
+			updateOK,
+			mtest.CreateCursorResponse(0, "test.planners", mtest.FirstBatch),
+		)
+		event, err := UndoLastActivity(context.Background(), "p1", "u1")
+		if err != nil {
+			t.Fatalf("expected the next undo to go through, got %v", err)
+		}
+		if event.ID != "e1" || !event.Undone {
+			t.Errorf("expected e1 undone, got %s", event.ID)
+		}
+		lookup := mt.GetStartedEvent().Command.Lookup("filter", "undo_failed").Document()
+		if _, ok := lookup.Lookup("$ne").BooleanOK(); !ok {
+			t.Errorf("expected the lookup to skip failed events, got %v", lookup)
+		}
+	})
+}
@@ -0,0 +1,488 @@
+package planner
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ErrNoActiveLane is returned when an archived card has no lane to be restored to
+var ErrNoActiveLane = errors.New("no active lane to restore the card to")
+
+// ArchivedItem is an archived card or lane as listed by the archive browser
+type ArchivedItem struct {
+	Type       string       `json:"type"` // card or lane
+	ArchivedAt time.Time    `json:"archived_at"`
+	LaneID     string       `json:"lane_id"`
+	LaneTitle  string       `json:"lane_title"`
+	Card       *PlannerCard `json:"card,omitempty"`
+	Lane       *PlannerLane `json:"lane,omitempty"` // with the cards archived along with it
+}
+
+// ArchivePage is a page of archived items, newest first
+type ArchivePage struct {
+	Items []ArchivedItem `json:"items"`
+	Total int            `json:"total"`
+	Page  int            `json:"page"`
+	Limit int            `json:"limit"`
+}
+
+// ArchiveCard marks a card as archived; archived cards stay in their lane but are hidden from GetPlanner
+func ArchiveCard(ctx context.Context, cardID string) (*PlannerCard, error) {
+	log.Printf("Archiving card: id=%s", cardID)
+
+	before, err := GetCard(ctx, cardID)
+	if err != nil {
+		return nil, err
+	}
+	plannerID, err := findPlannerIDByCard(ctx, cardID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	result, err := plannerCollection.UpdateOne(ctx,
+		bson.M{"lanes.cards.id": cardID},
+		bson.M{
+			"$set": bson.M{
+				"lanes.$[].cards.$[elem].archived_at": now,
+				"lanes.$[].cards.$[elem].updated_at":  now,
+			},
+			"$inc": bson.M{"lanes.$[].cards.$[elem].revision": 1},
+		},
+		options.Update().SetArrayFilters(options.ArrayFilters{
+			Filters: []interface{}{bson.M{"elem.id": cardID}},
+		}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if result.MatchedCount == 0 {
+		return nil, mongo.ErrNoDocuments
+	}
+
+	archived, err := GetCard(ctx, cardID)
+	if err != nil {
+		return nil, err
+	}
+	recordActivity(ctx, plannerID, EventCardArchived, "card", cardID,
+		&ActivitySnapshot{Card: before}, &ActivitySnapshot{Card: archived})
+	publishEvent(ctx, plannerID, EventCardArchived, archived)
+	return archived, nil
+}
+
+// restoreTarget picks the lane an archived card is restored to: its own lane if still active,
+// otherwise the requested fallback lane, otherwise the first active lane
+func restoreTarget(planner *Planner, card *PlannerCard, fallbackLaneID string) (*PlannerLane, error) {
+	var first *PlannerLane
+	var fallback *PlannerLane
+	for i := range planner.Lanes {
+		lane := &planner.Lanes[i]
+		if lane.ArchivedAt != nil {
+			continue
+		}
+		if lane.ID == card.LaneID {
+			return lane, nil
+		}
+		if lane.ID == fallbackLaneID {
+			fallback = lane
+		}
+		if first == nil || lane.Position < first.Position {
+			first = lane
+		}
+	}
+	if fallbackLaneID != "" {
+		if fallback == nil {
+			return nil, fmt.Errorf("%w: fallback lane %s is archived or missing", ErrNoActiveLane, fallbackLaneID)
+		}
+		return fallback, nil
+	}
+	if first == nil {
+		return nil, ErrNoActiveLane
+	}
+	return first, nil
+}
+
+// RestoreCard brings an archived card back. If its lane is archived or gone it is moved to the end of
+// fallbackLaneID, or of the first active lane when no fallback is given.
+func RestoreCard(ctx context.Context, cardID, fallbackLaneID string) (*PlannerCard, error) {
+	log.Printf("Restoring card: id=%s, fallbackLaneID=%s", cardID, fallbackLaneID)
+
+	var planner Planner
+	if err := plannerCollection.FindOne(ctx, bson.M{"lanes.cards.id": cardID}).Decode(&planner); err != nil {
+		return nil, err
+	}
+	before, err := GetCard(ctx, cardID)
+	if err != nil {
+		return nil, err
+	}
+	if before.ArchivedAt == nil {
+		return before, nil
+	}
+	lane, err := restoreTarget(&planner, before, fallbackLaneID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkWIPLimit(&planner, lane.ID, []PlannerCard{*before}); err != nil {
+		return nil, err
+	}
+	warning := wipLimitWarning(&planner, lane.ID, []PlannerCard{*before})
+	guard := wipLimitGuard(&planner, lane.ID, []PlannerCard{*before})
+
+	now := time.Now()
+	var result *mongo.UpdateResult
+	if lane.ID == before.LaneID {
+		result, err = plannerCollection.UpdateOne(ctx,
+			withWIPLimitGuard(bson.M{"lanes.cards.id": cardID}, guard),
+			bson.M{
+				"$set":   bson.M{"lanes.$[].cards.$[elem].updated_at": now},
+				"$unset": bson.M{"lanes.$[].cards.$[elem].archived_at": ""},
+				"$inc":   bson.M{"lanes.$[].cards.$[elem].revision": 1},
+			},
+			options.Update().SetArrayFilters(options.ArrayFilters{
+				Filters: []interface{}{bson.M{"elem.id": cardID}},
+			}),
+		)
+	} else {
+		card := *before
+		card.LaneID = lane.ID
+		card.ArchivedAt = nil
+		card.UpdatedAt = now
+		card.Revision++
+		card.Position = 1
+		for _, c := range lane.Cards {
+			if c.Position >= card.Position {
+				card.Position = c.Position + 1
+			}
+		}
+		// Moved in one write, so the card stays archived in its old lane if the target lane was removed meanwhile
+		result, err = plannerCollection.UpdateOne(ctx,
+			withWIPLimitGuard(bson.M{"id": planner.ID, "lanes.id": lane.ID, "lanes.cards.id": cardID}, guard),
+			cardSwapUpdate(&card, nil))
+	}
+	if err != nil {
+		return nil, err
+	}
+	if result.MatchedCount == 0 {
+		if lane.ID != before.LaneID && guard == nil {
+			return nil, fmt.Errorf("%w: lane %s was removed", ErrNoActiveLane, lane.ID)
+		}
+		return nil, wipLimitConflict(ctx, planner.ID, lane.ID, []PlannerCard{*before})
+	}
+
+	restored, err := GetCard(ctx, cardID)
+	if err != nil {
+		return nil, err
+	}
+	recordActivity(ctx, planner.ID, EventCardRestored, "card", cardID,
+		&ActivitySnapshot{Card: before}, &ActivitySnapshot{Card: restored})
+	if lane.ID != before.LaneID {
+		from := findLane(&planner, before.LaneID)
+		if from == nil {
+			from = &PlannerLane{ID: before.LaneID}
+		}
+		recordTransition(ctx, planner.ID, cardID, from, lane)
+	}
+	publishEvent(ctx, planner.ID, EventCardRestored, restored)
+	restored.WIPWarning = warning
+	return restored, nil
+}
+
+// setLaneArchived archives or restores a lane together with the cards it holds
+func setLaneArchived(ctx context.Context, laneID string, archived bool) (*PlannerLane, error) {
+	before, plannerID, err := getLane(ctx, laneID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	update := bson.M{"$set": bson.M{"lanes.$.archived_at": now, "lanes.$.updated_at": now}}
+	action := EventLaneArchived
+	if !archived {
+		update = bson.M{"$set": bson.M{"lanes.$.updated_at": now}, "$unset": bson.M{"lanes.$.archived_at": ""}}
+		action = EventLaneRestored
+	}
+	if _, err := plannerCollection.UpdateOne(ctx, bson.M{"lanes.id": laneID}, update); err != nil {
+		return nil, err
+	}
+
+	after, _, err := getLane(ctx, laneID)
+	if err != nil {
+		return nil, err
+	}
+	recordActivity(ctx, plannerID, action, "lane", laneID, &ActivitySnapshot{Lane: before}, &ActivitySnapshot{Lane: after})
+	publishEvent(ctx, plannerID, action, after)
+	return after, nil
+}
+
+// ArchiveLane hides a lane and its cards from GetPlanner
+func ArchiveLane(ctx context.Context, laneID string) (*PlannerLane, error) {
+	log.Printf("Archiving lane: id=%s", laneID)
+	return setLaneArchived(ctx, laneID, true)
+}
+
+// RestoreLane brings an archived lane back at its previous position. Cards archived on their own stay archived.
+func RestoreLane(ctx context.Context, laneID string) (*PlannerLane, error) {
+	log.Printf("Restoring lane: id=%s", laneID)
+	return setLaneArchived(ctx, laneID, false)
+}
+
+// ArchiveLaneCards archives every card of a lane in one write and returns the number archived
+func ArchiveLaneCards(ctx context.Context, laneID string) (int, error) {
+	log.Printf("Archiving all cards in lane: id=%s", laneID)
+
+	before, plannerID, err := getLane(ctx, laneID)
+	if err != nil {
+		return 0, err
+	}
+	count := 0
+	for _, card := range before.Cards {
+		if card.ArchivedAt == nil {
+			count++
+		}
+	}
+	if count == 0 {
+		return 0, nil
+	}
+
+	now := time.Now()
+	_, err = plannerCollection.UpdateOne(ctx,
+		bson.M{"lanes.id": laneID},
+		bson.M{
+			"$set": bson.M{
+				"lanes.$[lane].cards.$[elem].archived_at": now,
+				"lanes.$[lane].cards.$[elem].updated_at":  now,
+			},
+			"$inc": bson.M{"lanes.$[lane].cards.$[elem].revision": 1},
+		},
+		options.Update().SetArrayFilters(options.ArrayFilters{
+			Filters: []interface{}{bson.M{"lane.id": laneID}, bson.M{"elem.archived_at": nil}},
+		}),
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	after, _, err := getLane(ctx, laneID)
+	if err != nil {
+		return 0, err
+	}
+	recordActivity(ctx, plannerID, EventLaneCardsArchived, "lane", laneID,
+		&ActivitySnapshot{Lane: before}, &ActivitySnapshot{Lane: after})
+	publishEvent(ctx, plannerID, EventLaneCardsArchived, bson.M{"lane_id": laneID, "count": count})
+	return count, nil
+}
+
+// hideArchived removes archived lanes and archived cards from a planner
+func hideArchived(planner *Planner) {
+	lanes := []PlannerLane{}
+	for _, lane := range planner.Lanes {
+		if lane.ArchivedAt != nil {
+			continue
+		}
+		cards := []PlannerCard{}
+		for _, card := range lane.Cards {
+			if card.ArchivedAt == nil {
+				cards = append(cards, card)
+			}
+		}
+		lane.Cards = cards
+		lanes = append(lanes, lane)
+	}
+	planner.Lanes = lanes
+}
+
+// archivedItems lists the archived lanes and cards of a planner, newest first.
+// query matches card titles and content, and lane titles and descriptions, case-insensitively.
+func archivedItems(planner *Planner, itemType, query string) []ArchivedItem {
+	query = strings.ToLower(strings.TrimSpace(query))
+	matches := func(texts ...string) bool {
+		if query == "" {
+			return true
+		}
+		for _, t := range texts {
+			if strings.Contains(strings.ToLower(t), query) {
+				return true
+			}
+		}
+		return false
+	}
+
+	items := []ArchivedItem{}
+	for i := range planner.Lanes {
+		lane := planner.Lanes[i]
+		if lane.ArchivedAt != nil && itemType != "card" && matches(lane.Title, lane.Description) {
+			archivedLane := lane
+			archivedLane.Cards = []PlannerCard{}
+			for _, card := range lane.Cards {
+				if card.ArchivedAt == nil {
+					archivedLane.Cards = append(archivedLane.Cards, card)
+				}
+			}
+			items = append(items, ArchivedItem{
+				Type: "lane", ArchivedAt: *lane.ArchivedAt, LaneID: lane.ID, LaneTitle: lane.Title, Lane: &archivedLane,
+			})
+		}
+		if itemType == "lane" {
+			continue
+		}
+		for j := range lane.Cards {
+			card := lane.Cards[j]
+			if card.ArchivedAt == nil {
+				continue
+			}
+			title, _ := card.Fields[FieldTitle].(string)
+			content, _ := card.Fields[FieldContent].(string)
+			if !matches(title, content) {
+				continue
+			}
+			normalizeCard(&card)
+			items = append(items, ArchivedItem{
+				Type: "card", ArchivedAt: *card.ArchivedAt, LaneID: lane.ID, LaneTitle: lane.Title, Card: &card,
+			})
+		}
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].ArchivedAt.After(items[j].ArchivedAt)
+	})
+	return items
+}
+
+// GetArchive returns a page of the archived cards and lanes of a planner.
+// itemType limits the result to "card" or "lane"; query searches titles and content.
+func GetArchive(ctx context.Context, plannerID, itemType, query string, page, limit int) (*ArchivePage, error) {
+	log.Printf("Getting archive: plannerID=%s, type=%s, query=%s, page=%d", plannerID, itemType, query, page)
+
+	planner, err := loadPlanner(ctx, plannerID, true)
+	if err != nil {
+		return nil, err
+	}
+	items := archivedItems(planner, itemType, query)
+
+	result := &ArchivePage{Items: []ArchivedItem{}, Total: len(items), Page: page, Limit: limit}
+	start := (page - 1) * limit
+	if start < len(items) {
+		end := start + limit
+		if end > len(items) {
+			end = len(items)
+		}
+		result.Items = items[start:end]
+	}
+	return result, nil
+}
+
+// archiveErrorStatus maps archive errors to HTTP status codes
+func archiveErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, mongo.ErrNoDocuments):
+		return http.StatusNotFound
+	case errors.Is(err, ErrNoActiveLane):
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
+
+// HandleArchive handles the archive routes:
+//
+//	GET  /planner/{id}/archive?type={card|lane}&q={text}&page={n}&limit={n}
+//	POST /planner/{id}/card/{cardId}/archive
+//	POST /planner/{id}/card/{cardId}/restore   (optional body {"lane_id": fallback})
+//	POST /planner/{id}/lane/{laneId}/archive
+//	POST /planner/{id}/lane/{laneId}/restore
+//	POST /planner/{id}/lane/{laneId}/cards/archive
+func HandleArchive(w http.ResponseWriter, r *http.Request) {
+	// Extract planner, target and action from path
+	path := r.URL.Path
+	parts := strings.Split(path, "/")
+	if len(parts) < 4 || parts[1] != "planner" {
+		http.Error(w, "Invalid path", http.StatusBadRequest)
+		return
+	}
+	plannerID := parts[2]
+
+	if len(parts) == 4 && parts[3] == "archive" {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		page, limit := 1, 50
+		if v, err := strconv.Atoi(r.URL.Query().Get("page")); err == nil && v > 0 {
+			page = v
+		}
+		if v, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && v > 0 && v <= 200 {
+			limit = v
+		}
+		itemType := r.URL.Query().Get("type")
+		if itemType != "" && itemType != "card" && itemType != "lane" {
+			http.Error(w, "type must be card or lane", http.StatusBadRequest)
+			return
+		}
+		archive, err := GetArchive(r.Context(), plannerID, itemType, r.URL.Query().Get("q"), page, limit)
+		if err != nil {
+			http.Error(w, err.Error(), archiveErrorStatus(err))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(archive); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var response interface{}
+	var err error
+	switch {
+	case len(parts) == 6 && parts[3] == "card" && parts[5] == "archive":
+		response, err = ArchiveCard(r.Context(), parts[4])
+	case len(parts) == 6 && parts[3] == "card" && parts[5] == "restore":
+		var request struct {
+			LaneID string `json:"lane_id"`
+		}
+		if r.ContentLength > 0 {
+			if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+		response, err = RestoreCard(r.Context(), parts[4], request.LaneID)
+	case len(parts) == 6 && parts[3] == "lane" && parts[5] == "archive":
+		response, err = ArchiveLane(r.Context(), parts[4])
+	case len(parts) == 6 && parts[3] == "lane" && parts[5] == "restore":
+		response, err = RestoreLane(r.Context(), parts[4])
+	case len(parts) == 7 && parts[3] == "lane" && parts[5] == "cards" && parts[6] == "archive":
+		var count int
+		count, err = ArchiveLaneCards(r.Context(), parts[4])
+		response = map[string]int{"archived": count}
+	default:
+		http.Error(w, "Invalid path", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		if writeWIPLimitError(w, err) {
+			return
+		}
+		http.Error(w, err.Error(), archiveErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
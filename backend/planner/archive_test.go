@@ -0,0 +1,79 @@
+package planner
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func archiveFixture() *Planner {
+	day := func(d int) *time.Time {
+		t := time.Date(2024, 5, d, 12, 0, 0, 0, time.UTC)
+		return &t
+	}
+	return &Planner{
+		Lanes: []PlannerLane{
+			{ID: "todo", Title: "To Do", Position: 2, Cards: []PlannerCard{
+				{ID: "c1", LaneID: "todo", Fields: map[string]interface{}{"title": "Write docs"}},
+				{ID: "c2", LaneID: "todo", Fields: map[string]interface{}{"title": "Old spike", "content": "Try the Docs generator"}, ArchivedAt: day(2)},
+			}},
+			{ID: "backlog", Title: "Backlog", Position: 1},
+			{ID: "icebox", Title: "Icebox", Position: 3, ArchivedAt: day(3), Cards: []PlannerCard{
+				{ID: "c3", LaneID: "icebox", Fields: map[string]interface{}{"title": "Someday"}},
+				{ID: "c4", LaneID: "icebox", Fields: map[string]interface{}{"title": "Never"}, ArchivedAt: day(1)},
+			}},
+		},
+	}
+}
+
+func TestHideArchived(t *testing.T) {
+	p := archiveFixture()
+	hideArchived(p)
+	if len(p.Lanes) != 2 {
+		t.Fatalf("expected archived lane to be hidden, got %d lanes", len(p.Lanes))
+	}
+	if len(p.Lanes[0].Cards) != 1 || p.Lanes[0].Cards[0].ID != "c1" {
+		t.Errorf("expected only the active card, got %+v", p.Lanes[0].Cards)
+	}
+}
+
+func TestArchivedItems(t *testing.T) {
+	items := archivedItems(archiveFixture(), "", "")
+	if len(items) != 3 {
+		t.Fatalf("expected 3 archived items, got %d", len(items))
+	}
+	if items[0].Type != "lane" || items[1].Card.ID != "c2" || items[2].Card.ID != "c4" {
+		t.Errorf("expected newest first, got %+v", items)
+	}
+	if len(items[0].Lane.Cards) != 1 || items[0].Lane.Cards[0].ID != "c3" {
+		t.Errorf("archived lane should hold the cards archived with it, got %+v", items[0].Lane.Cards)
+	}
+
+	if items := archivedItems(archiveFixture(), "card", "docs"); len(items) != 1 || items[0].Card.ID != "c2" {
+		t.Errorf("expected search to match card content, got %+v", items)
+	}
+	if items := archivedItems(archiveFixture(), "lane", ""); len(items) != 1 || items[0].LaneID != "icebox" {
+		t.Errorf("expected only lanes, got %+v", items)
+	}
+}
+
+func TestRestoreTarget(t *testing.T) {
+	p := archiveFixture()
+
+	lane, err := restoreTarget(p, &p.Lanes[0].Cards[1], "")
+	if err != nil || lane.ID != "todo" {
+		t.Errorf("expected card to go back to its lane, got %v, %v", lane, err)
+	}
+	// The icebox lane is archived, so its cards go to the first active lane or the fallback
+	lane, err = restoreTarget(p, &p.Lanes[2].Cards[1], "")
+	if err != nil || lane.ID != "backlog" {
+		t.Errorf("expected first active lane, got %v, %v", lane, err)
+	}
+	lane, err = restoreTarget(p, &p.Lanes[2].Cards[1], "todo")
+	if err != nil || lane.ID != "todo" {
+		t.Errorf("expected fallback lane, got %v, %v", lane, err)
+	}
+	if _, err := restoreTarget(p, &p.Lanes[2].Cards[1], "icebox"); !errors.Is(err, ErrNoActiveLane) {
+		t.Errorf("expected ErrNoActiveLane for archived fallback, got %v", err)
+	}
+}
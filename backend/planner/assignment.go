@@ -0,0 +1,376 @@
+package planner
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Card priorities, from lowest to highest
+const (
+	PriorityLow    = "low"
+	PriorityMedium = "medium"
+	PriorityHigh   = "high"
+	PriorityUrgent = "urgent"
+)
+
+// CardPatch is a partial card update. Nil attributes are left unchanged;
+// an empty slice, empty string or zero time clears the attribute.
+type CardPatch struct {
+	Fields    map[string]interface{}
+	Assignees *[]string
+	Labels    *[]string
+	Priority  *string
+	DueDate   *time.Time
+	StartDate *time.Time
+	SprintID  *string
+}
+
+func (p CardPatch) isEmpty() bool {
+	return len(p.Fields) == 0 && p.Assignees == nil && p.Labels == nil &&
+		p.Priority == nil && p.DueDate == nil && p.StartDate == nil && p.SprintID == nil
+}
+
+// applyAttributes validates the attribute part of a patch and adds it to a $set/$unset update.
+// prefix is the positional path of the card, e.g. "lanes.$[].cards.$[elem]."
+func (p CardPatch) applyAttributes(planner *Planner, prefix string, set, unset bson.M) error {
+	if p.Assignees != nil {
+		assignees, err := validateAssignees(planner, *p.Assignees)
+		if err != nil {
+			return err
+		}
+		if len(assignees) == 0 {
+			unset[prefix+"assignees"] = ""
+		} else {
+			set[prefix+"assignees"] = assignees
+		}
+	}
+	if p.Labels != nil {
+		labels := normalizeLabels(*p.Labels)
+		if len(labels) == 0 {
+			unset[prefix+"labels"] = ""
+		} else {
+			set[prefix+"labels"] = labels
+		}
+	}
+	if p.Priority != nil {
+		if *p.Priority == "" {
+			unset[prefix+"priority"] = ""
+		} else if !isValidPriority(*p.Priority) {
+			return fmt.Errorf("%w: priority must be one of low, medium, high, urgent", ErrInvalidCardField)
+		} else {
+			set[prefix+"priority"] = *p.Priority
+		}
+	}
+	if p.SprintID != nil {
+		if *p.SprintID == "" {
+			unset[prefix+"sprint_id"] = ""
+		} else if sprint := findSprint(planner, *p.SprintID); sprint == nil || sprint.Status == SprintStatusCompleted {
+			return fmt.Errorf("%w: sprint_id must be a planned or active sprint", ErrInvalidCardField)
+		} else {
+			set[prefix+"sprint_id"] = *p.SprintID
+		}
+	}
+	for name, value := range map[string]*time.Time{"due_date": p.DueDate, "start_date": p.StartDate} {
+		if value == nil {
+			continue
+		}
+		if value.IsZero() {
+			unset[prefix+name] = ""
+		} else {
+			set[prefix+name] = value.UTC()
+		}
+	}
+	return nil
+}
+
+// checkDateOrder checks that a card's start date is not after its due date once the patch is applied.
+// A date the patch leaves unchanged is taken from the card.
+func (p CardPatch) checkDateOrder(card *PlannerCard) error {
+	start, due := card.StartDate, card.DueDate
+	if p.StartDate != nil {
+		start = p.StartDate
+	}
+	if p.DueDate != nil {
+		due = p.DueDate
+	}
+	if start != nil && due != nil && !start.IsZero() && !due.IsZero() && start.After(*due) {
+		return fmt.Errorf("%w: start_date must not be after due_date", ErrInvalidCardField)
+	}
+	return nil
+}
+
+// dateOrderGuard returns the condition on the stored card that keeps checkDateOrder valid until the write:
+// the date the patch doesn't change must still be the one it was checked against
+func (p CardPatch) dateOrderGuard(card *PlannerCard) bson.M {
+	switch {
+	case p.DueDate != nil && p.StartDate == nil:
+		return bson.M{"start_date": card.StartDate}
+	case p.StartDate != nil && p.DueDate == nil:
+		return bson.M{"due_date": card.DueDate}
+	}
+	return bson.M{}
+}
+
+// validateAssignees checks that every assignee is a member of the planner and removes duplicates
+func validateAssignees(planner *Planner, assignees []string) ([]string, error) {
+	result := []string{}
+	for _, a := range assignees {
+		a = strings.TrimSpace(a)
+		if a == "" || containsString(result, a) {
+			continue
+		}
+		if !IsPlannerMember(planner, a) {
+			return nil, fmt.Errorf("%w: user %q cannot be assigned on this planner", ErrNotPlannerMember, a)
+		}
+		result = append(result, a)
+	}
+	return result, nil
+}
+
+// validateUserFields checks that values of user-typed columns refer to planner members
+func validateUserFields(planner *Planner, fields map[string]interface{}) error {
+	for _, col := range planner.Columns {
+		if col.Type != ColumnTypeUser {
+			continue
+		}
+		if userID, ok := fields[col.ID].(string); ok && userID != "" && !IsPlannerMember(planner, userID) {
+			return fmt.Errorf("%w: user %q in column %q", ErrNotPlannerMember, userID, col.Name)
+		}
+	}
+	return nil
+}
+
+// normalizeLabels trims labels and removes empty and duplicate entries
+func normalizeLabels(labels []string) []string {
+	result := []string{}
+	for _, l := range labels {
+		l = strings.TrimSpace(l)
+		if l != "" && !containsString(result, l) {
+			result = append(result, l)
+		}
+	}
+	return result
+}
+
+func isValidPriority(p string) bool {
+	switch p {
+	case PriorityLow, PriorityMedium, PriorityHigh, PriorityUrgent:
+		return true
+	}
+	return false
+}
+
+// cardPatchRequest is the JSON body of PATCH card requests.
+// Attributes are kept raw so that an explicit null can be told apart from an absent key.
+type cardPatchRequest struct {
+	Fields    map[string]interface{} `json:"fields"`
+	Assignees json.RawMessage        `json:"assignees"`
+	Labels    json.RawMessage        `json:"labels"`
+	Priority  json.RawMessage        `json:"priority"`
+	DueDate   json.RawMessage        `json:"due_date"`
+	StartDate json.RawMessage        `json:"start_date"`
+	SprintID  json.RawMessage        `json:"sprint_id"`
+}
+
+// toPatch converts the request into a CardPatch, treating null as "clear"
+func (r cardPatchRequest) toPatch() (CardPatch, error) {
+	patch := CardPatch{Fields: r.Fields}
+	if r.Assignees != nil {
+		var v []string
+		if err := json.Unmarshal(r.Assignees, &v); err != nil {
+			return patch, fmt.Errorf("assignees: %w", err)
+		}
+		patch.Assignees = &v
+	}
+	if r.Labels != nil {
+		var v []string
+		if err := json.Unmarshal(r.Labels, &v); err != nil {
+			return patch, fmt.Errorf("labels: %w", err)
+		}
+		patch.Labels = &v
+	}
+	if r.Priority != nil {
+		var v string
+		if err := json.Unmarshal(r.Priority, &v); err != nil {
+			return patch, fmt.Errorf("priority: %w", err)
+		}
+		patch.Priority = &v
+	}
+	if r.SprintID != nil {
+		var v *string
+		if err := json.Unmarshal(r.SprintID, &v); err != nil {
+			return patch, fmt.Errorf("sprint_id: %w", err)
+		}
+		if v == nil {
+			v = new(string)
+		}
+		patch.SprintID = v
+	}
+	var err error
+	if patch.DueDate, err = parseOptionalDate(r.DueDate); err != nil {
+		return patch, fmt.Errorf("due_date: %w", err)
+	}
+	if patch.StartDate, err = parseOptionalDate(r.StartDate); err != nil {
+		return patch, fmt.Errorf("start_date: %w", err)
+	}
+	return patch, nil
+}
+
+// parseOptionalDate parses a JSON date (YYYY-MM-DD or RFC 3339); null yields a zero time
+func parseOptionalDate(raw json.RawMessage) (*time.Time, error) {
+	if raw == nil {
+		return nil, nil
+	}
+	var s *string
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return nil, err
+	}
+	if s == nil || *s == "" {
+		return &time.Time{}, nil
+	}
+	t, err := parseDate(*s)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// CardSummary is a card together with the planner and lane it belongs to
+type CardSummary struct {
+	PlannerID    string      `json:"planner_id" bson:"planner_id"`
+	PlannerTitle string      `json:"planner_title" bson:"planner_title"`
+	LaneID       string      `json:"lane_id" bson:"lane_id"`
+	LaneTitle    string      `json:"lane_title" bson:"lane_title"`
+	LaneCategory string      `json:"lane_category" bson:"lane_category,omitempty"`
+	Card         PlannerCard `json:"card" bson:"card"`
+}
+
+// findCardSummaries unwinds cards of the planners matching plannerFilter and returns those matching cardFilter.
+// cardFilter keys are relative to the card, e.g. "assignees".
+func findCardSummaries(ctx context.Context, plannerFilter bson.M, cardFilter bson.M) ([]CardSummary, error) {
+	cardMatch := bson.M{}
+	for k, v := range cardFilter {
+		cardMatch["lanes.cards."+k] = v
+	}
+	// The card filter is applied before unwinding too, so it can use the multikey indexes
+	pipeline := []bson.M{
+		{"$match": plannerFilter},
+		{"$match": cardMatch},
+		{"$unwind": "$lanes"},
+		{"$unwind": "$lanes.cards"},
+		{"$match": cardMatch},
+		{"$match": bson.M{"lanes.archived_at": nil, "lanes.cards.archived_at": nil}},
+		{"$project": bson.M{
+			"_id":           0,
+			"planner_id":    "$id",
+			"planner_title": "$title",
+			"lane_id":       "$lanes.id",
+			"lane_title":    "$lanes.title",
+			"lane_category": "$lanes.category",
+			"card":          "$lanes.cards",
+		}},
+		{"$sort": bson.D{{Key: "card.due_date", Value: 1}, {Key: "card.updated_at", Value: -1}}},
+	}
+	cursor, err := plannerCollection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	summaries := []CardSummary{}
+	if err := cursor.All(ctx, &summaries); err != nil {
+		return nil, err
+	}
+	for i := range summaries {
+		normalizeCard(&summaries[i].Card)
+		summaries[i].LaneCategory = laneCategory(&PlannerLane{Title: summaries[i].LaneTitle, Category: summaries[i].LaneCategory})
+	}
+	return summaries, nil
+}
+
+// withoutDoneCards drops the cards of done lanes, which can't be overdue
+func withoutDoneCards(summaries []CardSummary) []CardSummary {
+	open := []CardSummary{}
+	for _, s := range summaries {
+		if s.LaneCategory != LaneCategoryDone {
+			open = append(open, s)
+		}
+	}
+	return open
+}
+
+// GetCardsAssignedToUser returns the cards assigned to a user across all planners they can access
+func GetCardsAssignedToUser(ctx context.Context, userID string) ([]CardSummary, error) {
+	log.Printf("Getting cards assigned to user=%s", userID)
+	return findCardSummaries(ctx, plannerAccessFilter(userID), bson.M{"assignees": userID})
+}
+
+// GetOverdueCards returns cards due before now in the planners a user can access, leaving out archived cards
+// and cards in done lanes. If plannerID is set, only that planner is searched.
+func GetOverdueCards(ctx context.Context, userID, plannerID string, now time.Time) ([]CardSummary, error) {
+	log.Printf("Getting overdue cards for user=%s, plannerID=%s", userID, plannerID)
+	filter := plannerAccessFilter(userID)
+	if plannerID != "" {
+		filter = bson.M{"$and": []bson.M{filter, {"id": plannerID}}}
+	}
+	summaries, err := findCardSummaries(ctx, filter, bson.M{"due_date": bson.M{"$lt": now}})
+	if err != nil {
+		return nil, err
+	}
+	return withoutDoneCards(summaries), nil
+}
+
+// HandleGetAssignedCards handles GET /planner/cards/assigned
+func HandleGetAssignedCards(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	userID, _ := r.Context().Value("user_id").(string)
+	if userID == "" {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	cards, err := GetCardsAssignedToUser(r.Context(), userID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(cards); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// HandleGetOverdueCards handles GET /planner/cards/overdue?planner_id={id}
+func HandleGetOverdueCards(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	userID, _ := r.Context().Value("user_id").(string)
+	if userID == "" {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	cards, err := GetOverdueCards(r.Context(), userID, r.URL.Query().Get("planner_id"), time.Now())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(cards); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
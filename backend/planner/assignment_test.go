@@ -0,0 +1,133 @@
+package planner
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestCardPatchRequestToPatch(t *testing.T) {
+	var req cardPatchRequest
+	req.Assignees = []byte(`["u1"]`)
+	req.Labels = []byte(`null`)
+	req.Priority = []byte(`"high"`)
+	req.DueDate = []byte(`"2024-05-01"`)
+	req.StartDate = []byte(`null`)
+	patch, err := req.toPatch()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if patch.Assignees == nil || (*patch.Assignees)[0] != "u1" || *patch.Priority != PriorityHigh {
+		t.Errorf("unexpected patch %+v", patch)
+	}
+	if patch.Labels == nil || len(*patch.Labels) != 0 {
+		t.Errorf("expected null labels to clear them, got %v", patch.Labels)
+	}
+	if !patch.DueDate.Equal(time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)) || !patch.StartDate.IsZero() {
+		t.Errorf("unexpected dates %v %v", patch.DueDate, patch.StartDate)
+	}
+	if patch.SprintID != nil {
+		t.Error("absent keys should be left unchanged")
+	}
+
+	req = cardPatchRequest{DueDate: []byte(`"next week"`)}
+	if _, err := req.toPatch(); err == nil {
+		t.Error("expected an error for an unparsable date")
+	}
+}
+
+func TestApplyAttributes(t *testing.T) {
+	planner := &Planner{UserID: "owner", Members: []string{"u1"}}
+	labels := []string{" bug ", "bug", ""}
+	assignees := []string{"u1", "u1", "owner"}
+	empty := ""
+	due := time.Date(2024, 5, 1, 12, 0, 0, 0, time.FixedZone("CEST", 2*3600))
+	patch := CardPatch{Labels: &labels, Assignees: &assignees, Priority: &empty, DueDate: &due, StartDate: &time.Time{}}
+
+	set, unset := bson.M{}, bson.M{}
+	if err := patch.applyAttributes(planner, "card.", set, unset); err != nil {
+		t.Fatal(err)
+	}
+	if got := set["card.labels"].([]string); len(got) != 1 || got[0] != "bug" {
+		t.Errorf("expected labels to be trimmed and deduplicated, got %v", got)
+	}
+	if got := set["card.assignees"].([]string); len(got) != 2 {
+		t.Errorf("expected duplicate assignees removed, got %v", got)
+	}
+	if set["card.due_date"] != due.UTC() {
+		t.Errorf("expected due date in UTC, got %v", set["card.due_date"])
+	}
+	for _, key := range []string{"card.priority", "card.start_date"} {
+		if _, ok := unset[key]; !ok {
+			t.Errorf("expected %s to be cleared", key)
+		}
+	}
+
+	bad := "someday"
+	if err := (CardPatch{Priority: &bad}).applyAttributes(planner, "", bson.M{}, bson.M{}); !errors.Is(err, ErrInvalidCardField) {
+		t.Errorf("expected ErrInvalidCardField for priority, got %v", err)
+	}
+	stranger := []string{"stranger"}
+	if err := (CardPatch{Assignees: &stranger}).applyAttributes(planner, "", bson.M{}, bson.M{}); !errors.Is(err, ErrNotPlannerMember) {
+		t.Errorf("expected ErrNotPlannerMember, got %v", err)
+	}
+}
+
+func TestCheckDateOrder(t *testing.T) {
+	due := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	start := due.AddDate(0, 0, 1)
+	if err := (CardPatch{DueDate: &due, StartDate: &start}).checkDateOrder(&PlannerCard{}); !errors.Is(err, ErrInvalidCardField) {
+		t.Errorf("expected ErrInvalidCardField for start after due, got %v", err)
+	}
+	// Against the stored start or due date
+	if err := (CardPatch{DueDate: &due}).checkDateOrder(&PlannerCard{StartDate: &start}); !errors.Is(err, ErrInvalidCardField) {
+		t.Errorf("expected ErrInvalidCardField for due before the stored start, got %v", err)
+	}
+	if err := (CardPatch{StartDate: &start}).checkDateOrder(&PlannerCard{DueDate: &due}); !errors.Is(err, ErrInvalidCardField) {
+		t.Errorf("expected ErrInvalidCardField for start after the stored due, got %v", err)
+	}
+	// Clearing one of them is fine
+	if err := (CardPatch{DueDate: &time.Time{}}).checkDateOrder(&PlannerCard{StartDate: &start, DueDate: &due}); err != nil {
+		t.Errorf("expected clearing the due date to pass, got %v", err)
+	}
+
+	guard := (CardPatch{DueDate: &due}).dateOrderGuard(&PlannerCard{StartDate: &start})
+	if len(guard) != 1 || guard["start_date"] != &start {
+		t.Errorf("expected a guard on the stored start date, got %v", guard)
+	}
+	if guard := (CardPatch{DueDate: &due, StartDate: &start}).dateOrderGuard(&PlannerCard{}); len(guard) != 0 {
+		t.Errorf("expected no guard when both dates are set, got %v", guard)
+	}
+}
+
+func TestNormalizeDate(t *testing.T) {
+	cases := map[string]string{
+		"2024-05-01":                "2024-05-01",
+		" 2024-05-01 ":              "2024-05-01",
+		"2024-05-01T10:00:00+02:00": "2024-05-01T08:00:00Z",
+	}
+	for in, want := range cases {
+		if got, err := normalizeDate(in); err != nil || got != want {
+			t.Errorf("%q: expected %q, got %q (%v)", in, want, got, err)
+		}
+	}
+	for _, bad := range []string{"", "2024-5-1", "May 1"} {
+		if _, err := normalizeDate(bad); err == nil {
+			t.Errorf("%q: expected an error", bad)
+		}
+	}
+}
+
+func TestWithoutDoneCards(t *testing.T) {
+	summaries := []CardSummary{
+		{LaneID: "l1", LaneCategory: LaneCategoryTodo},
+		{LaneID: "l2", LaneCategory: LaneCategoryDone},
+		{LaneID: "l3", LaneCategory: LaneCategoryInProgress},
+	}
+	got := withoutDoneCards(summaries)
+	if len(got) != 2 || got[0].LaneID != "l1" || got[1].LaneID != "l3" {
+		t.Errorf("expected the done lane's card to be dropped, got %+v", got)
+	}
+}
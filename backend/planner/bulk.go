@@ -0,0 +1,575 @@
+package planner
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Bulk card operations
+const (
+	BulkOpMove    = "move"
+	BulkOpUpdate  = "update"
+	BulkOpArchive = "archive"
+	BulkOpDelete  = "delete"
+	BulkOpAssign  = "assign"
+)
+
+// Bulk modes
+const (
+	BulkModeAtomic     = "atomic"      // nothing is written if any operation fails
+	BulkModeBestEffort = "best_effort" // operations that succeed are written, failures are reported
+)
+
+// Bulk operation result statuses
+const (
+	BulkStatusOK      = "ok"
+	BulkStatusFailed  = "failed"
+	BulkStatusSkipped = "skipped" // valid, but not written because another operation failed in atomic mode
+)
+
+const (
+	maxBulkOperations = 500
+	maxBulkAttempts   = 3
+)
+
+var (
+	// ErrInvalidBulkRequest is returned when a bulk request is malformed
+	ErrInvalidBulkRequest = errors.New("invalid bulk request")
+	// ErrBulkConflict is returned when the cards kept changing while a bulk request was being applied
+	ErrBulkConflict = errors.New("cards were modified concurrently, please retry")
+	// ErrBulkCardArchived is returned for a bulk operation on an archived card other than archive or delete
+	ErrBulkCardArchived = errors.New("card is archived")
+)
+
+// BulkCardOperation is one operation of a bulk request. Update takes the same fields and attributes as PATCH;
+// assign takes assignees only.
+type BulkCardOperation struct {
+	Op       string `json:"op"`
+	CardID   string `json:"card_id"`
+	LaneID   string `json:"lane_id,omitempty"`  // move target
+	Position int    `json:"position,omitempty"` // move position, 0 appends
+	cardPatchRequest
+}
+
+// BulkCardResult is the outcome of one bulk operation
+type BulkCardResult struct {
+	Index  int          `json:"index"`
+	Op     string       `json:"op"`
+	CardID string       `json:"card_id"`
+	Status string       `json:"status"`
+	Error  string       `json:"error,omitempty"`
+	Card   *PlannerCard `json:"card,omitempty"`
+}
+
+// BulkCardResponse reports whether a bulk request was written and the result of each operation
+type BulkCardResponse struct {
+	Mode    string           `json:"mode"`
+	Applied int              `json:"applied"` // operations written
+	Failed  int              `json:"failed"`
+	Results []BulkCardResult `json:"results"`
+}
+
+// bulkChange is a successful operation with the card before and after it, for activity, events and rules
+type bulkChange struct {
+	op     string
+	before *PlannerCard
+	after  *PlannerCard // nil when deleted
+}
+
+// cloneCard returns a deep copy of a card
+func cloneCard(card *PlannerCard) *PlannerCard {
+	c := *card
+	if card.Fields != nil {
+		c.Fields = map[string]interface{}{}
+		for k, v := range card.Fields {
+			c.Fields[k] = v
+		}
+	}
+	if card.Assignees != nil {
+		c.Assignees = append([]string{}, card.Assignees...)
+	}
+	if card.Labels != nil {
+		c.Labels = append([]string{}, card.Labels...)
+	}
+	if card.Checklist != nil {
+		c.Checklist = append([]ChecklistItem{}, card.Checklist...)
+	}
+	return &c
+}
+
+// clonePlanner returns a copy of a planner whose lanes and cards can be changed without touching the original
+func clonePlanner(planner *Planner) *Planner {
+	p := *planner
+	p.Lanes = make([]PlannerLane, len(planner.Lanes))
+	for i, lane := range planner.Lanes {
+		p.Lanes[i] = lane
+		p.Lanes[i].Cards = make([]PlannerCard, len(lane.Cards))
+		for j := range lane.Cards {
+			p.Lanes[i].Cards[j] = *cloneCard(&lane.Cards[j])
+		}
+	}
+	return &p
+}
+
+// findPlannerCard returns the lane and card index of a card in a planner
+func findPlannerCard(planner *Planner, cardID string) (int, int, bool) {
+	for i := range planner.Lanes {
+		for j := range planner.Lanes[i].Cards {
+			if planner.Lanes[i].Cards[j].ID == cardID {
+				return i, j, true
+			}
+		}
+	}
+	return 0, 0, false
+}
+
+// applyCardPatch validates a patch and applies it to a card in memory, like PatchCard does in MongoDB
+func applyCardPatch(planner *Planner, card *PlannerCard, patch CardPatch) error {
+	if patch.isEmpty() {
+		return fmt.Errorf("%w: no fields provided", ErrInvalidCardField)
+	}
+	validated, err := ValidateCardFields(planner.Columns, patch.Fields)
+	if err != nil {
+		return err
+	}
+	if err := validateUserFields(planner, validated); err != nil {
+		return err
+	}
+	set, unset := bson.M{}, bson.M{}
+	if err := patch.applyAttributes(planner, "", set, unset); err != nil {
+		return err
+	}
+	if err := patch.checkDateOrder(card); err != nil {
+		return err
+	}
+
+	if card.Fields == nil {
+		card.Fields = map[string]interface{}{}
+	}
+	for key, value := range validated {
+		if value == nil {
+			delete(card.Fields, key)
+		} else {
+			card.Fields[key] = value
+		}
+	}
+	for key := range unset {
+		switch key {
+		case "assignees":
+			card.Assignees = nil
+		case "labels":
+			card.Labels = nil
+		case "priority":
+			card.Priority = ""
+		case "due_date":
+			card.DueDate = nil
+		case "start_date":
+			card.StartDate = nil
+		case "sprint_id":
+			card.SprintID = ""
+		}
+	}
+	for key, value := range set {
+		switch key {
+		case "assignees":
+			card.Assignees = value.([]string)
+		case "labels":
+			card.Labels = value.([]string)
+		case "priority":
+			card.Priority = value.(string)
+		case "due_date":
+			t := value.(time.Time)
+			card.DueDate = &t
+		case "start_date":
+			t := value.(time.Time)
+			card.StartDate = &t
+		case "sprint_id":
+			card.SprintID = value.(string)
+		}
+	}
+	return nil
+}
+
+// applyBulkOperation applies one operation to the in-memory planner.
+// blocked holds the unresolved blockers of the planner's cards, see unresolvedBlockers.
+func applyBulkOperation(planner *Planner, op BulkCardOperation, now time.Time, blocked map[string][]string) error {
+	li, ci, ok := findPlannerCard(planner, op.CardID)
+	if !ok {
+		return fmt.Errorf("card not found: %s", op.CardID)
+	}
+	lane := &planner.Lanes[li]
+	card := &lane.Cards[ci]
+	// Archived cards are off the board, so they can only be deleted; archiving them again changes nothing
+	if card.ArchivedAt != nil && op.Op != BulkOpArchive && op.Op != BulkOpDelete {
+		return fmt.Errorf("%w: %s", ErrBulkCardArchived, op.CardID)
+	}
+
+	switch op.Op {
+	case BulkOpMove:
+		ti := -1
+		for i := range planner.Lanes {
+			if planner.Lanes[i].ID == op.LaneID && planner.Lanes[i].ArchivedAt == nil {
+				ti = i
+			}
+		}
+		if ti < 0 {
+			return fmt.Errorf("target lane not found: %s", op.LaneID)
+		}
+		if err := checkWIPLimit(planner, op.LaneID, []PlannerCard{*card}); err != nil {
+			return err
+		}
+		if err := checkBlockedDone(planner, &planner.Lanes[ti], op.CardID, blocked); err != nil {
+			return err
+		}
+		moved := *card
+		lane.Cards = append(lane.Cards[:ci], lane.Cards[ci+1:]...)
+		target := &planner.Lanes[ti]
+
+		position := op.Position
+		if position <= 0 {
+			position = 1
+			for _, c := range target.Cards {
+				if c.Position >= position {
+					position = c.Position + 1
+				}
+			}
+		}
+		for i := range target.Cards {
+			if target.Cards[i].Position >= position {
+				target.Cards[i].Position++
+			}
+		}
+		moved.LaneID = op.LaneID
+		moved.Position = position
+		moved.UpdatedAt = now
+		moved.Revision++
+		if moved.Fields == nil {
+			moved.Fields = map[string]interface{}{}
+		}
+		moved.Fields["lane_id"] = op.LaneID
+		moved.Fields["moved_at"] = now.Format(time.RFC3339)
+		target.Cards = append(target.Cards, moved)
+		return nil
+	case BulkOpUpdate, BulkOpAssign:
+		patch, err := op.toPatch()
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidCardField, err)
+		}
+		if op.Op == BulkOpAssign {
+			if patch.Assignees == nil {
+				return fmt.Errorf("%w: assign needs assignees", ErrInvalidCardField)
+			}
+			patch = CardPatch{Assignees: patch.Assignees}
+		}
+		if err := applyCardPatch(planner, card, patch); err != nil {
+			return err
+		}
+		card.UpdatedAt = now
+		card.Revision++
+		return nil
+	case BulkOpArchive:
+		if card.ArchivedAt == nil {
+			card.ArchivedAt = &now
+			card.UpdatedAt = now
+			card.Revision++
+		}
+		return nil
+	case BulkOpDelete:
+		lane.Cards = append(lane.Cards[:ci], lane.Cards[ci+1:]...)
+		return nil
+	}
+	return fmt.Errorf("%w: unsupported op %q", ErrInvalidBulkRequest, op.Op)
+}
+
+// writeBulkChanges replaces the changed cards of a planner in a single atomic update.
+// Cards that were not changed are left as they are in the database, so concurrent changes to them are kept;
+// the update only matches if none of the changed cards was modified or repositioned since they were loaded.
+func writeBulkChanges(ctx context.Context, original, working *Planner) (bool, error) {
+	originalCards := map[string]*PlannerCard{}
+	for i := range original.Lanes {
+		for j := range original.Lanes[i].Cards {
+			originalCards[original.Lanes[i].Cards[j].ID] = &original.Lanes[i].Cards[j]
+		}
+	}
+
+	touched := []string{}
+	incoming := map[string][]PlannerCard{}
+	laneIDs := []string{}
+	for _, lane := range working.Lanes {
+		for _, card := range lane.Cards {
+			before, ok := originalCards[card.ID]
+			if ok && reflect.DeepEqual(*before, card) {
+				delete(originalCards, card.ID)
+				continue
+			}
+			delete(originalCards, card.ID)
+			touched = append(touched, card.ID)
+			incoming[lane.ID] = append(incoming[lane.ID], card)
+			if !containsString(laneIDs, lane.ID) {
+				laneIDs = append(laneIDs, lane.ID)
+			}
+		}
+	}
+	// Cards left over were deleted
+	for id := range originalCards {
+		touched = append(touched, id)
+	}
+	if len(touched) == 0 {
+		return true, nil
+	}
+
+	guards := []bson.M{}
+	for i := range original.Lanes {
+		for _, card := range original.Lanes[i].Cards {
+			if containsString(touched, card.ID) {
+				// Reordering and making room for moved cards change positions without touching updated_at
+				guards = append(guards, bson.M{"lanes.cards": bson.M{"$elemMatch": bson.M{
+					"id": card.ID, "updated_at": card.UpdatedAt, "position": card.Position,
+				}}})
+			}
+		}
+	}
+	// Lanes receiving cards must still fit under their WIP limits when the write lands
+	for laneID := range incoming {
+		group, _ := wipGroup(working, findLane(working, laneID))
+		after := 0
+		for _, lane := range group {
+			for _, card := range lane.Cards {
+				if lane.ArchivedAt == nil && card.ArchivedAt == nil && containsString(touched, card.ID) {
+					after++
+				}
+			}
+		}
+		if guard := wipLimitGuardCount(working, laneID, touched, after); guard != nil {
+			guards = append(guards, guard)
+		}
+	}
+	filter := bson.M{"id": original.ID, "$and": guards}
+	if len(laneIDs) > 0 {
+		filter["lanes.id"] = bson.M{"$all": laneIDs}
+	}
+
+	var added interface{} = bson.A{}
+	if len(incoming) > 0 {
+		branches := bson.A{}
+		for laneID, cards := range incoming {
+			branches = append(branches, bson.M{
+				"case": bson.M{"$eq": bson.A{"$$lane.id", laneID}},
+				"then": bson.M{"$literal": cards},
+			})
+		}
+		added = bson.M{"$switch": bson.M{"branches": branches, "default": bson.A{}}}
+	}
+	update := []bson.M{{"$set": bson.M{"lanes": bson.M{"$map": bson.M{
+		"input": "$lanes",
+		"as":    "lane",
+		"in": bson.M{"$mergeObjects": bson.A{"$$lane", bson.M{"cards": bson.M{"$concatArrays": bson.A{
+			bson.M{"$filter": bson.M{
+				"input": bson.M{"$ifNull": bson.A{"$$lane.cards", bson.A{}}},
+				"as":    "card",
+				"cond":  bson.M{"$not": bson.A{bson.M{"$in": bson.A{"$$card.id", touched}}}},
+			}},
+			added,
+		}}}}},
+	}}}}}
+
+	result, err := plannerCollection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, err
+	}
+	return result.MatchedCount > 0, nil
+}
+
+// ApplyBulkCardOperations applies a list of card operations to a planner in one atomic write.
+// In atomic mode nothing is written if any operation fails; in best-effort mode failed operations are skipped.
+// Operations run in order, so later operations see the effect of earlier ones.
+func ApplyBulkCardOperations(ctx context.Context, plannerID, mode string, ops []BulkCardOperation) (*BulkCardResponse, error) {
+	log.Printf("Applying bulk card operations: plannerID=%s, mode=%s, count=%d", plannerID, mode, len(ops))
+
+	if mode == "" {
+		mode = BulkModeAtomic
+	}
+	if mode != BulkModeAtomic && mode != BulkModeBestEffort {
+		return nil, fmt.Errorf("%w: mode must be atomic or best_effort", ErrInvalidBulkRequest)
+	}
+	if len(ops) == 0 || len(ops) > maxBulkOperations {
+		return nil, fmt.Errorf("%w: between 1 and %d operations are required", ErrInvalidBulkRequest, maxBulkOperations)
+	}
+
+	for attempt := 0; attempt < maxBulkAttempts; attempt++ {
+		var original Planner
+		if err := plannerCollection.FindOne(ctx, bson.M{"id": plannerID}).Decode(&original); err != nil {
+			return nil, err
+		}
+		working := clonePlanner(&original)
+		blocked, err := unresolvedBlockers(ctx, &original)
+		if err != nil {
+			return nil, err
+		}
+
+		now := time.Now()
+		response := &BulkCardResponse{Mode: mode, Results: []BulkCardResult{}}
+		changes := []bulkChange{}
+		for i, op := range ops {
+			result := BulkCardResult{Index: i, Op: op.Op, CardID: op.CardID, Status: BulkStatusOK}
+			var before *PlannerCard
+			var warning *WIPLimitError
+			if li, ci, ok := findPlannerCard(working, op.CardID); ok {
+				before = cloneCard(&working.Lanes[li].Cards[ci])
+				if op.Op == BulkOpMove {
+					warning = wipLimitWarning(working, op.LaneID, []PlannerCard{*before})
+				}
+			}
+			if err := applyBulkOperation(working, op, now, blocked); err != nil {
+				result.Status = BulkStatusFailed
+				result.Error = err.Error()
+				response.Failed++
+			} else {
+				change := bulkChange{op: op.Op, before: before}
+				if li, ci, ok := findPlannerCard(working, op.CardID); ok {
+					change.after = cloneCard(&working.Lanes[li].Cards[ci])
+					normalizeCard(change.after)
+					result.Card = cloneCard(change.after)
+					result.Card.WIPWarning = warning
+				}
+				changes = append(changes, change)
+			}
+			response.Results = append(response.Results, result)
+		}
+
+		if mode == BulkModeAtomic && response.Failed > 0 {
+			for i := range response.Results {
+				if response.Results[i].Status == BulkStatusOK {
+					response.Results[i].Status = BulkStatusSkipped
+					response.Results[i].Card = nil
+				}
+			}
+			return response, nil
+		}
+
+		written, err := writeBulkChanges(ctx, &original, working)
+		if err != nil {
+			return nil, err
+		}
+		if !written {
+			log.Printf("Bulk card operations conflicted, retrying: plannerID=%s, attempt=%d", plannerID, attempt+1)
+			continue
+		}
+		response.Applied = len(changes)
+		afterBulkWrite(ctx, working, changes)
+		return response, nil
+	}
+	return nil, ErrBulkConflict
+}
+
+// afterBulkWrite records activity and lane transitions, publishes events and fires rules for each written operation
+func afterBulkWrite(ctx context.Context, planner *Planner, changes []bulkChange) {
+	plannerID := planner.ID
+	for _, change := range changes {
+		action := EventCardUpdated
+		switch change.op {
+		case BulkOpMove:
+			action = EventCardMoved
+		case BulkOpArchive:
+			action = EventCardArchived
+		case BulkOpDelete:
+			action = EventCardDeleted
+		}
+		if change.after == nil {
+			links, err := deleteCardLinks(ctx, change.before.ID)
+			if err != nil {
+				log.Printf("Failed to delete links of card %s: %v", change.before.ID, err)
+			}
+			comments, err := deleteCardComments(ctx, change.before.ID)
+			if err != nil {
+				log.Printf("Failed to delete comments of card %s: %v", change.before.ID, err)
+			}
+			recordActivity(ctx, plannerID, action, "card", change.before.ID,
+				&ActivitySnapshot{Card: change.before, Links: links, Comments: comments}, nil)
+			recordTransition(ctx, plannerID, change.before.ID, findLane(planner, change.before.LaneID), nil)
+			publishEvent(ctx, plannerID, action, bson.M{"id": change.before.ID})
+			continue
+		}
+		recordActivity(ctx, plannerID, action, "card", change.after.ID,
+			&ActivitySnapshot{Card: change.before}, &ActivitySnapshot{Card: change.after})
+		if change.before.LaneID != change.after.LaneID {
+			recordTransition(ctx, plannerID, change.after.ID,
+				findLane(planner, change.before.LaneID), findLane(planner, change.after.LaneID))
+		}
+		publishEvent(ctx, plannerID, action, change.after)
+	}
+
+	for _, change := range changes {
+		switch {
+		case change.after == nil || change.after.ArchivedAt != nil:
+		case change.op == BulkOpMove && change.before.LaneID != change.after.LaneID:
+			fireCardRules(ctx, plannerID, change.after,
+				ruleEvent{Trigger: RuleTriggerCardLeftLane, CardID: change.after.ID, LaneID: change.before.LaneID},
+				ruleEvent{Trigger: RuleTriggerCardEnteredLane, CardID: change.after.ID, LaneID: change.after.LaneID})
+		case change.op == BulkOpUpdate || change.op == BulkOpAssign:
+			if changed := changedRuleFields(change.before, change.after); len(changed) > 0 {
+				fireCardRules(ctx, plannerID, change.after,
+					ruleEvent{Trigger: RuleTriggerFieldChanged, CardID: change.after.ID, Changed: changed})
+			}
+		}
+	}
+}
+
+// HandleBulkCards handles POST /planner/{id}/cards/bulk
+func HandleBulkCards(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Extract planner ID from path
+	path := r.URL.Path
+	parts := strings.Split(path, "/")
+	if len(parts) != 5 || parts[1] != "planner" || parts[3] != "cards" || parts[4] != "bulk" {
+		http.Error(w, "Invalid path", http.StatusBadRequest)
+		return
+	}
+	plannerID := parts[2]
+
+	var request struct {
+		Mode       string              `json:"mode"`
+		Operations []BulkCardOperation `json:"operations"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	response, err := ApplyBulkCardOperations(r.Context(), plannerID, request.Mode, request.Operations)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, ErrInvalidBulkRequest):
+			status = http.StatusBadRequest
+		case errors.Is(err, ErrBulkConflict):
+			status = http.StatusConflict
+		case errors.Is(err, mongo.ErrNoDocuments):
+			status = http.StatusNotFound
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if response.Mode == BulkModeAtomic && response.Failed > 0 {
+		w.WriteHeader(http.StatusBadRequest)
+	}
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
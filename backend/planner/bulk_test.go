@@ -0,0 +1,132 @@
+package planner
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func bulkFixture() *Planner {
+	return &Planner{
+		UserID:  "alice",
+		Members: []string{"bob"},
+		Lanes: []PlannerLane{
+			{ID: "todo", Cards: []PlannerCard{
+				{ID: "c1", LaneID: "todo", Position: 1, Fields: map[string]interface{}{"title": "One"}},
+				{ID: "c2", LaneID: "todo", Position: 2, Fields: map[string]interface{}{"title": "Two"}},
+			}},
+			{ID: "done", WIPLimit: 1, Cards: []PlannerCard{
+				{ID: "c3", LaneID: "done", Position: 1, Fields: map[string]interface{}{"title": "Three"}},
+			}},
+		},
+	}
+}
+
+func bulkOp(t *testing.T, raw string) BulkCardOperation {
+	t.Helper()
+	var op BulkCardOperation
+	if err := json.Unmarshal([]byte(raw), &op); err != nil {
+		t.Fatalf("decode %s: %v", raw, err)
+	}
+	return op
+}
+
+func TestApplyBulkOperation(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	p := bulkFixture()
+
+	if err := applyBulkOperation(p, bulkOp(t, `{"op":"move","card_id":"c2","lane_id":"done","position":1}`), now, nil); err != nil {
+		t.Fatalf("move: %v", err)
+	}
+	if len(p.Lanes[0].Cards) != 1 || len(p.Lanes[1].Cards) != 2 {
+		t.Fatalf("expected card to change lanes, got %+v", p.Lanes)
+	}
+	li, ci, _ := findPlannerCard(p, "c3")
+	if p.Lanes[li].Cards[ci].Position != 2 {
+		t.Errorf("expected existing card to shift down, got position %d", p.Lanes[li].Cards[ci].Position)
+	}
+	li, ci, _ = findPlannerCard(p, "c2")
+	if moved := p.Lanes[li].Cards[ci]; moved.LaneID != "done" || moved.Position != 1 || moved.Fields["lane_id"] != "done" {
+		t.Errorf("unexpected moved card: %+v", moved)
+	}
+
+	if err := applyBulkOperation(p, bulkOp(t, `{"op":"update","card_id":"c1","fields":{"title":"Uno"},"labels":["x"],"due_date":"2024-06-30"}`), now, nil); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	li, ci, _ = findPlannerCard(p, "c1")
+	if c := p.Lanes[li].Cards[ci]; c.Fields["title"] != "Uno" || len(c.Labels) != 1 || c.DueDate == nil {
+		t.Errorf("unexpected updated card: %+v", c)
+	}
+	if err := applyBulkOperation(p, bulkOp(t, `{"op":"update","card_id":"c1","priority":null}`), now, nil); err != nil {
+		t.Errorf("clearing an attribute should succeed: %v", err)
+	}
+
+	if err := applyBulkOperation(p, bulkOp(t, `{"op":"assign","card_id":"c1","assignees":["bob","bob"]}`), now, nil); err != nil {
+		t.Fatalf("assign: %v", err)
+	}
+	if c := p.Lanes[li].Cards[ci]; len(c.Assignees) != 1 || c.Assignees[0] != "bob" {
+		t.Errorf("unexpected assignees: %v", c.Assignees)
+	}
+	if err := applyBulkOperation(p, bulkOp(t, `{"op":"assign","card_id":"c1","assignees":["mallory"]}`), now, nil); !errors.Is(err, ErrNotPlannerMember) {
+		t.Errorf("expected ErrNotPlannerMember, got %v", err)
+	}
+
+	if err := applyBulkOperation(p, bulkOp(t, `{"op":"archive","card_id":"c1"}`), now, nil); err != nil || p.Lanes[li].Cards[ci].ArchivedAt == nil {
+		t.Errorf("expected card to be archived: %v", err)
+	}
+	if err := applyBulkOperation(p, bulkOp(t, `{"op":"delete","card_id":"c3"}`), now, nil); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, _, ok := findPlannerCard(p, "c3"); ok {
+		t.Error("expected card to be deleted")
+	}
+
+	if err := applyBulkOperation(p, bulkOp(t, `{"op":"copy","card_id":"c2"}`), now, nil); !errors.Is(err, ErrInvalidBulkRequest) {
+		t.Errorf("expected ErrInvalidBulkRequest, got %v", err)
+	}
+	if err := applyBulkOperation(p, bulkOp(t, `{"op":"move","card_id":"c2","lane_id":"gone"}`), now, nil); err == nil {
+		t.Error("expected error for unknown lane")
+	}
+}
+
+func TestApplyBulkOperation_WIPLimit(t *testing.T) {
+	p := bulkFixture()
+	p.Settings.WIPMode = WIPModeBlock
+
+	var wipErr *WIPLimitError
+	err := applyBulkOperation(p, bulkOp(t, `{"op":"move","card_id":"c1","lane_id":"done"}`), time.Now(), nil)
+	if !errors.As(err, &wipErr) {
+		t.Fatalf("expected WIP limit error, got %v", err)
+	}
+	if len(p.Lanes[0].Cards) != 2 {
+		t.Error("failed move should leave the planner unchanged")
+	}
+}
+
+func TestApplyBulkOperation_Archived(t *testing.T) {
+	now := time.Now()
+	p := bulkFixture()
+	archived := now.Add(-time.Hour)
+	p.Lanes[0].Cards[0].ArchivedAt = &archived
+
+	for _, raw := range []string{
+		`{"op":"move","card_id":"c1","lane_id":"done"}`,
+		`{"op":"update","card_id":"c1","fields":{"title":"Uno"}}`,
+		`{"op":"assign","card_id":"c1","assignees":["bob"]}`,
+	} {
+		if err := applyBulkOperation(p, bulkOp(t, raw), now, nil); !errors.Is(err, ErrBulkCardArchived) {
+			t.Errorf("%s: expected ErrBulkCardArchived, got %v", raw, err)
+		}
+	}
+	if card := p.Lanes[0].Cards[0]; card.LaneID != "todo" || card.Fields["title"] != "One" || len(card.Assignees) != 0 {
+		t.Errorf("expected the archived card untouched, got %+v", card)
+	}
+
+	if err := applyBulkOperation(p, bulkOp(t, `{"op":"archive","card_id":"c1"}`), now, nil); err != nil || !p.Lanes[0].Cards[0].ArchivedAt.Equal(archived) {
+		t.Errorf("expected archiving again to change nothing, got %v", err)
+	}
+	if err := applyBulkOperation(p, bulkOp(t, `{"op":"delete","card_id":"c1"}`), now, nil); err != nil || len(p.Lanes[0].Cards) != 1 {
+		t.Errorf("expected an archived card to be deletable, got %v", err)
+	}
+}
@@ -0,0 +1,391 @@
+package planner
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ErrInvalidCalendarToken is returned when a calendar feed is requested with an unknown token
+var ErrInvalidCalendarToken = errors.New("invalid calendar token")
+
+// CalendarToken is a secret that lets calendar clients read a user's feeds without a session
+type CalendarToken struct {
+	Token     string    `json:"token" bson:"token"`
+	UserID    string    `json:"user_id" bson:"user_id"`
+	CreatedAt time.Time `json:"created_at" bson:"created_at"`
+}
+
+var calendarTokenCollection *mongo.Collection
+
+// GetCalendarToken returns the calendar token of a user, creating one on first use
+func GetCalendarToken(ctx context.Context, userID string) (*CalendarToken, error) {
+	var token CalendarToken
+	err := calendarTokenCollection.FindOne(ctx, bson.M{"user_id": userID}).Decode(&token)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return RotateCalendarToken(ctx, userID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// RotateCalendarToken replaces a user's calendar token, invalidating subscribed feed URLs
+func RotateCalendarToken(ctx context.Context, userID string) (*CalendarToken, error) {
+	log.Printf("Rotating calendar token for user=%s", userID)
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+	token := CalendarToken{Token: hex.EncodeToString(secret), UserID: userID, CreatedAt: time.Now()}
+	_, err := calendarTokenCollection.ReplaceOne(ctx, bson.M{"user_id": userID}, token, options.Replace().SetUpsert(true))
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// calendarUser resolves the user of a feed request from its token, falling back to the session user
+func calendarUser(r *http.Request) (string, error) {
+	if token := r.URL.Query().Get("token"); token != "" {
+		var ct CalendarToken
+		err := calendarTokenCollection.FindOne(r.Context(), bson.M{"token": token}).Decode(&ct)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return "", ErrInvalidCalendarToken
+		}
+		if err != nil {
+			return "", err
+		}
+		return ct.UserID, nil
+	}
+	if userID, _ := r.Context().Value("user_id").(string); userID != "" {
+		return userID, nil
+	}
+	return "", ErrInvalidCalendarToken
+}
+
+// calendarEntry is a VEVENT or VTODO generated from a card
+type calendarEntry struct {
+	Component    string // VEVENT or VTODO
+	UID          string
+	Summary      string
+	Description  string
+	Start        string // DTSTART value, with its parameters
+	End          string // DTEND or DUE value, with its parameters
+	Categories   []string
+	Completed    bool
+	Sequence     int
+	LastModified time.Time
+}
+
+// calendarDate formats a stored date as an iCalendar property value with parameters.
+// Plain dates become all-day values; RFC 3339 times are written in UTC.
+func calendarDate(s string, addDay bool) (string, bool) {
+	if t, err := time.Parse("2006-01-02", s); err == nil {
+		if addDay {
+			t = t.AddDate(0, 0, 1)
+		}
+		return ";VALUE=DATE:" + t.Format("20060102"), true
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		if addDay {
+			t = t.Add(time.Hour)
+		}
+		return ":" + t.UTC().Format("20060102T150405Z"), true
+	}
+	return "", false
+}
+
+// cardCalendarEntries returns a VTODO for a card's due date and a VEVENT for each date column value.
+// UIDs only depend on the card and column, so clients update entries in place.
+func cardCalendarEntries(summary CardSummary, columns []PlannerColumn) []calendarEntry {
+	card := summary.Card
+	title, _ := card.Fields[FieldTitle].(string)
+	content, _ := card.Fields[FieldContent].(string)
+	description := fmt.Sprintf("%s / %s", summary.PlannerTitle, summary.LaneTitle)
+	if content != "" {
+		description += "\n\n" + content
+	}
+
+	entries := []calendarEntry{}
+	if card.DueDate != nil {
+		todo := calendarEntry{
+			Component:    "VTODO",
+			UID:          "card-" + card.ID + "@zurabase",
+			Summary:      title,
+			Description:  description,
+			Categories:   card.Labels,
+			Completed:    len(card.Checklist) > 0 && card.Progress == 100,
+			Sequence:     card.Revision,
+			LastModified: card.UpdatedAt,
+		}
+		todo.End, _ = calendarDate(card.DueDate.UTC().Format(time.RFC3339), false)
+		if card.StartDate != nil {
+			todo.Start, _ = calendarDate(card.StartDate.UTC().Format(time.RFC3339), false)
+		}
+		entries = append(entries, todo)
+	}
+	for _, col := range columns {
+		if col.Type != ColumnTypeDate {
+			continue
+		}
+		value, _ := card.Fields[col.ID].(string)
+		start, ok := calendarDate(value, false)
+		if !ok {
+			continue
+		}
+		end, _ := calendarDate(value, true)
+		entries = append(entries, calendarEntry{
+			Component:    "VEVENT",
+			UID:          "card-" + card.ID + "-" + col.ID + "@zurabase",
+			Summary:      title + " (" + col.Name + ")",
+			Description:  description,
+			Start:        start,
+			End:          end,
+			Categories:   card.Labels,
+			Sequence:     card.Revision,
+			LastModified: card.UpdatedAt,
+		})
+	}
+	return entries
+}
+
+// escapeCalendarText escapes a TEXT value (RFC 5545 section 3.3.11)
+func escapeCalendarText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`, "\r", `\n`).Replace(s)
+}
+
+// foldCalendarLine folds a content line at 75 octets without splitting UTF-8 sequences
+func foldCalendarLine(line string) string {
+	var b strings.Builder
+	width := 0
+	for _, r := range line {
+		size := len(string(r))
+		if width+size > 75 {
+			b.WriteString("\r\n ")
+			width = 1
+		}
+		b.WriteRune(r)
+		width += size
+	}
+	b.WriteString("\r\n")
+	return b.String()
+}
+
+// renderCalendar renders entries as a VCALENDAR document
+func renderCalendar(name string, entries []calendarEntry) string {
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].UID < entries[j].UID })
+
+	var b strings.Builder
+	line := func(s string) { b.WriteString(foldCalendarLine(s)) }
+	line("BEGIN:VCALENDAR")
+	line("VERSION:2.0")
+	line("PRODID:-//ZuraBase//Planner//EN")
+	line("CALSCALE:GREGORIAN")
+	line("X-WR-CALNAME:" + escapeCalendarText(name))
+	for _, e := range entries {
+		stamp := e.LastModified.UTC().Format("20060102T150405Z")
+		line("BEGIN:" + e.Component)
+		line("UID:" + e.UID)
+		line("DTSTAMP:" + stamp)
+		line("LAST-MODIFIED:" + stamp)
+		line(fmt.Sprintf("SEQUENCE:%d", e.Sequence))
+		line("SUMMARY:" + escapeCalendarText(e.Summary))
+		if e.Description != "" {
+			line("DESCRIPTION:" + escapeCalendarText(e.Description))
+		}
+		if e.Start != "" {
+			line("DTSTART" + e.Start)
+		}
+		if e.Component == "VTODO" {
+			line("DUE" + e.End)
+			if e.Completed {
+				line("STATUS:COMPLETED")
+			} else {
+				line("STATUS:NEEDS-ACTION")
+			}
+		} else {
+			line("DTEND" + e.End)
+			line("TRANSP:TRANSPARENT")
+		}
+		if len(e.Categories) > 0 {
+			categories := make([]string, len(e.Categories))
+			for i, c := range e.Categories {
+				categories[i] = escapeCalendarText(c)
+			}
+			line("CATEGORIES:" + strings.Join(categories, ","))
+		}
+		line("END:" + e.Component)
+	}
+	line("END:VCALENDAR")
+	return b.String()
+}
+
+// calendarEntriesFor loads the cards of the matching planners and converts those with dates
+func calendarEntriesFor(ctx context.Context, plannerFilter, cardFilter bson.M) ([]calendarEntry, error) {
+	summaries, err := findCardSummaries(ctx, plannerFilter, cardFilter)
+	if err != nil {
+		return nil, err
+	}
+
+	columns := map[string][]PlannerColumn{}
+	entries := []calendarEntry{}
+	for _, summary := range summaries {
+		cols, ok := columns[summary.PlannerID]
+		if !ok {
+			planner, err := getPlannerColumns(ctx, summary.PlannerID)
+			if err != nil {
+				return nil, err
+			}
+			cols = planner.Columns
+			columns[summary.PlannerID] = cols
+		}
+		entries = append(entries, cardCalendarEntries(summary, cols)...)
+	}
+	return entries, nil
+}
+
+// GetPlannerCalendar renders the calendar of one planner the user can access
+func GetPlannerCalendar(ctx context.Context, plannerID, userID string) (string, error) {
+	log.Printf("Getting planner calendar: plannerID=%s, userID=%s", plannerID, userID)
+
+	planner, err := getPlannerColumns(ctx, plannerID)
+	if err != nil {
+		return "", err
+	}
+	if planner.UserID != "" && !IsPlannerMember(planner, userID) {
+		return "", fmt.Errorf("%w: not a member of this planner", ErrForbidden)
+	}
+	entries, err := calendarEntriesFor(ctx, bson.M{"id": plannerID}, bson.M{})
+	if err != nil {
+		return "", err
+	}
+	return renderCalendar(planner.Title, entries), nil
+}
+
+// GetUserCalendar renders the calendar of cards assigned to a user, or of every card
+// in the planners they can access if all is set
+func GetUserCalendar(ctx context.Context, userID string, all bool) (string, error) {
+	log.Printf("Getting user calendar: userID=%s, all=%t", userID, all)
+
+	cardFilter := bson.M{"assignees": userID}
+	if all {
+		cardFilter = bson.M{}
+	}
+	entries, err := calendarEntriesFor(ctx, plannerAccessFilter(userID), cardFilter)
+	if err != nil {
+		return "", err
+	}
+	return renderCalendar("ZuraBase", entries), nil
+}
+
+// calendarErrorStatus maps calendar errors to HTTP status codes
+func calendarErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, ErrInvalidCalendarToken):
+		return http.StatusUnauthorized
+	case errors.Is(err, ErrForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, mongo.ErrNoDocuments):
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
+
+func writeCalendar(w http.ResponseWriter, ics string) {
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", "inline; filename=\"calendar.ics\"")
+	w.Write([]byte(ics))
+}
+
+// HandlePlannerCalendar handles GET /planner/{id}/calendar.ics?token={token}
+func HandlePlannerCalendar(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Extract planner ID from path
+	path := r.URL.Path
+	parts := strings.Split(path, "/")
+	if len(parts) != 4 || parts[1] != "planner" || parts[3] != "calendar.ics" {
+		http.Error(w, "Invalid path", http.StatusBadRequest)
+		return
+	}
+
+	userID, err := calendarUser(r)
+	if err != nil {
+		http.Error(w, err.Error(), calendarErrorStatus(err))
+		return
+	}
+	ics, err := GetPlannerCalendar(r.Context(), parts[2], userID)
+	if err != nil {
+		http.Error(w, err.Error(), calendarErrorStatus(err))
+		return
+	}
+	writeCalendar(w, ics)
+}
+
+// HandleUserCalendar handles GET /me/calendar.ics?token={token}[&all=true]
+func HandleUserCalendar(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID, err := calendarUser(r)
+	if err != nil {
+		http.Error(w, err.Error(), calendarErrorStatus(err))
+		return
+	}
+	ics, err := GetUserCalendar(r.Context(), userID, r.URL.Query().Get("all") == "true")
+	if err != nil {
+		http.Error(w, err.Error(), calendarErrorStatus(err))
+		return
+	}
+	writeCalendar(w, ics)
+}
+
+// HandleCalendarToken handles GET (fetch) and POST (rotate) /me/calendar/token
+func HandleCalendarToken(w http.ResponseWriter, r *http.Request) {
+	userID, _ := r.Context().Value("user_id").(string)
+	if userID == "" {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var token *CalendarToken
+	var err error
+	switch r.Method {
+	case http.MethodGet:
+		token, err = GetCalendarToken(r.Context(), userID)
+	case http.MethodPost:
+		token, err = RotateCalendarToken(r.Context(), userID)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(token); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
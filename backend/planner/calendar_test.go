@@ -0,0 +1,38 @@
+package planner
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCardCalendarEntries(t *testing.T) {
+	due := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	created := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+	summary := CardSummary{PlannerTitle: "Ops", LaneTitle: "To Do", Card: PlannerCard{
+		ID: "c1", DueDate: &due, Revision: 3, CreatedAt: created, UpdatedAt: created.AddDate(0, 0, 20),
+		Fields: map[string]interface{}{FieldTitle: "Ship", "launch": "2024-05-03", "notes": "2024-05-04"},
+	}}
+	columns := []PlannerColumn{
+		{ID: "launch", Name: "Launch", Type: ColumnTypeDate},
+		{ID: "notes", Name: "Notes", Type: ColumnTypeText},
+	}
+
+	entries := cardCalendarEntries(summary, columns)
+	if len(entries) != 2 || entries[0].Component != "VTODO" || entries[1].Component != "VEVENT" {
+		t.Fatalf("expected a VTODO and a VEVENT, got %+v", entries)
+	}
+	for _, e := range entries {
+		if e.Sequence != 3 {
+			t.Errorf("%s: expected the card revision as SEQUENCE, got %d", e.UID, e.Sequence)
+		}
+	}
+	if entries[1].UID != "card-c1-launch@zurabase" || entries[1].Summary != "Ship (Launch)" {
+		t.Errorf("unexpected event %+v", entries[1])
+	}
+
+	ics := renderCalendar("Ops", entries)
+	if !strings.Contains(ics, "SEQUENCE:3\r\n") {
+		t.Errorf("expected SEQUENCE in the feed, got %q", ics)
+	}
+}
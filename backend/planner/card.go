@@ -3,6 +3,7 @@ package planner
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
@@ -16,8 +17,12 @@ import (
 
 var cardHistoryCollection *mongo.Collection
 
-// AddCard adds a new card to a lane in MongoDB at the specified position
-func AddCard(ctx context.Context, laneID, title, content string, position int) (*PlannerCard, error) {
+// ErrCardConflict is returned when a card changed between reading and writing it
+var ErrCardConflict = errors.New("card was modified concurrently, please retry")
+
+// AddCard adds a new card to a lane in MongoDB at the specified position.
+// Extra fields are validated against the planner's columns.
+func AddCard(ctx context.Context, laneID, title, content string, position int, fields map[string]interface{}) (*PlannerCard, error) {
 	log.Printf("Adding card: laneID=%s, title=%s, position=%d", laneID, title, position)
 
 	// First, get the current lane to determine proper positioning
@@ -39,6 +44,14 @@ func AddCard(ctx context.Context, laneID, title, content string, position int) (
 		return nil, fmt.Errorf("lane not found: %s", laneID)
 	}
 
+	validated, err := ValidateCardFields(planner.Columns, fields)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateUserFields(&planner, validated); err != nil {
+		return nil, err
+	}
+
 	// Create the new card
 	cardID := GenerateID()
 	card := PlannerCard{
@@ -52,6 +65,28 @@ func AddCard(ctx context.Context, laneID, title, content string, position int) (
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 	}
+	for k, v := range validated {
+		if v != nil && k != FieldTitle && k != FieldContent {
+			card.Fields[k] = v
+		}
+	}
+	if err := checkWIPLimit(&planner, laneID, []PlannerCard{card}); err != nil {
+		return nil, err
+	}
+	warning := wipLimitWarning(&planner, laneID, []PlannerCard{card})
+
+	// Insert the new card first, so that nothing is written if the lane filled up meanwhile
+	result, err := plannerCollection.UpdateOne(
+		ctx,
+		withWIPLimitGuard(bson.M{"lanes.id": laneID}, wipLimitGuard(&planner, laneID, []PlannerCard{card})),
+		bson.M{"$push": bson.M{"lanes.$.cards": card}},
+	)
+	if err != nil {
+		return nil, err
+	}
+	if result.MatchedCount == 0 {
+		return nil, wipLimitConflict(ctx, planner.ID, laneID, []PlannerCard{card})
+	}
 
 	// Shift positions of existing cards to make room for the new card
 	for i := range lane.Cards {
@@ -70,16 +105,13 @@ func AddCard(ctx context.Context, laneID, title, content string, position int) (
 		}
 	}
 
-	// Insert the new card
-	_, err = plannerCollection.UpdateOne(
-		ctx,
-		bson.M{"lanes.id": laneID},
-		bson.M{"$push": bson.M{"lanes.$.cards": card}},
-	)
-	if err != nil {
-		return nil, err
-	}
-	return &card, nil
+	recordActivity(ctx, planner.ID, EventCardAdded, "card", card.ID, nil, &ActivitySnapshot{Card: &card})
+	recordTransition(ctx, planner.ID, card.ID, nil, lane)
+	publishEvent(ctx, planner.ID, EventCardAdded, card)
+	added := fireCardRules(ctx, planner.ID, &card,
+		ruleEvent{Trigger: RuleTriggerCardEnteredLane, CardID: card.ID, LaneID: laneID})
+	added.WIPWarning = warning
+	return added, nil
 }
 
 // GetCard retrieves a card by ID from MongoDB
@@ -103,6 +135,7 @@ func GetCard(ctx context.Context, cardID string) (*PlannerCard, error) {
  	if err := cursor.Decode(&card); err != nil {
  		return nil, err
  	}
+ 	normalizeCard(&card)
  	return &card, nil
  }
  return nil, mongo.ErrNoDocuments
@@ -112,32 +145,129 @@ func GetCard(ctx context.Context, cardID string) (*PlannerCard, error) {
 func UpdateCard(ctx context.Context, cardID, title, content string) (*PlannerCard, error) {
 	log.Printf("Updating card: id=%s, title=%s", cardID, title)
 
-	filter := bson.M{"lanes.cards.id": cardID}
-	update := bson.M{"$set": bson.M{
-		"lanes.$[].cards.$[elem].fields.title":   title,
-		"lanes.$[].cards.$[elem].fields.content": content,
-		"lanes.$[].cards.$[elem].updated_at":     time.Now(),
-	}}
+	return PatchCardFields(ctx, cardID, map[string]interface{}{
+		FieldTitle:   title,
+		FieldContent: content,
+	})
+}
+
+// loadCardPlanner loads the columns and membership of the planner that owns a card
+func loadCardPlanner(ctx context.Context, cardID string) (*Planner, error) {
+	var planner Planner
+	opts := options.FindOne().SetProjection(bson.M{"id": 1, "user_id": 1, "members": 1, "columns": 1})
+	if err := plannerCollection.FindOne(ctx, bson.M{"lanes.cards.id": cardID}, opts).Decode(&planner); err != nil {
+		return nil, err
+	}
+	return &planner, nil
+}
+
+// validateFieldsForCard validates a partial fields map against the columns of the card's planner
+func validateFieldsForCard(ctx context.Context, cardID string, fields map[string]interface{}) (string, map[string]interface{}, error) {
+	planner, err := loadCardPlanner(ctx, cardID)
+	if err != nil {
+		return "", nil, err
+	}
+	validated, err := ValidateCardFields(planner.Columns, fields)
+	if err != nil {
+		return "", nil, err
+	}
+	if err := validateUserFields(planner, validated); err != nil {
+		return "", nil, err
+	}
+	return planner.ID, validated, nil
+}
+
+// PatchCardFields applies a partial fields update to a card in a single atomic write.
+// Keys with a nil value are removed from the card.
+func PatchCardFields(ctx context.Context, cardID string, fields map[string]interface{}) (*PlannerCard, error) {
+	return PatchCard(ctx, cardID, CardPatch{Fields: fields})
+}
+
+// cardPatchUpdate validates a patch against the card's planner and builds the update for the card
+// matched by the elem array filter
+func cardPatchUpdate(planner *Planner, patch CardPatch, now time.Time) (bson.M, error) {
+	validated, err := ValidateCardFields(planner.Columns, patch.Fields)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateUserFields(planner, validated); err != nil {
+		return nil, err
+	}
+
+	set := bson.M{"lanes.$[].cards.$[elem].updated_at": now}
+	unset := bson.M{}
+	for key, value := range validated {
+		path := "lanes.$[].cards.$[elem].fields." + key
+		if value == nil {
+			unset[path] = ""
+		} else {
+			set[path] = value
+		}
+	}
+	if err := patch.applyAttributes(planner, "lanes.$[].cards.$[elem].", set, unset); err != nil {
+		return nil, err
+	}
+
+	update := bson.M{"$set": set, "$inc": bson.M{"lanes.$[].cards.$[elem].revision": 1}}
+	if len(unset) > 0 {
+		update["$unset"] = unset
+	}
+	return update, nil
+}
+
+// PatchCard applies a partial update of fields and attributes to a card in a single atomic write
+func PatchCard(ctx context.Context, cardID string, patch CardPatch) (*PlannerCard, error) {
+	log.Printf("Patching card: id=%s, fieldCount=%d", cardID, len(patch.Fields))
+
+	if patch.isEmpty() {
+		return nil, fmt.Errorf("%w: no fields provided", ErrInvalidCardField)
+	}
+
+	planner, err := loadCardPlanner(ctx, cardID)
+	if err != nil {
+		return nil, err
+	}
+	update, err := cardPatchUpdate(planner, patch, time.Now())
+	if err != nil {
+		return nil, err
+	}
 	arrayFilters := options.Update().SetArrayFilters(options.ArrayFilters{
 		Filters: []interface{}{bson.M{"elem.id": cardID}},
 	})
 
-	_, err := plannerCollection.UpdateOne(ctx, filter, update, arrayFilters)
+	before, err := GetCard(ctx, cardID)
+	if err != nil {
+		return nil, err
+	}
+	// Dates are checked against the stored card, which must not change before the write
+	if err := patch.checkDateOrder(before); err != nil {
+		return nil, err
+	}
+	guard := patch.dateOrderGuard(before)
+	guard["id"] = cardID
+	result, err := plannerCollection.UpdateOne(ctx, bson.M{"lanes.cards": bson.M{"$elemMatch": guard}}, update, arrayFilters)
 	if err != nil {
 		return nil, err
 	}
+	if result.MatchedCount == 0 {
+		if len(guard) > 1 {
+			return nil, ErrCardConflict
+		}
+		return nil, mongo.ErrNoDocuments
+	}
 
-	// Re-fetch card to return
+	// Re-fetch authoritative card
 	updated, err := GetCard(ctx, cardID)
 	if err != nil {
 		return nil, err
 	}
-	// Ensure updated fields include latest title/content
-	if updated.Fields == nil {
-		updated.Fields = map[string]interface{}{}
+	recordActivity(ctx, planner.ID, EventCardUpdated, "card", cardID,
+		&ActivitySnapshot{Card: before}, &ActivitySnapshot{Card: updated})
+	publishEvent(ctx, planner.ID, EventCardUpdated, updated)
+	if changed := changedRuleFields(before, updated); len(changed) > 0 {
+		updated = fireCardRules(ctx, planner.ID, updated,
+			ruleEvent{Trigger: RuleTriggerFieldChanged, CardID: cardID, Changed: changed})
 	}
-	updated.Fields["title"] = title
-	updated.Fields["content"] = content
 	return updated, nil
 }
 
@@ -145,18 +275,49 @@ func UpdateCard(ctx context.Context, cardID, title, content string) (*PlannerCar
 func DeleteCard(ctx context.Context, cardID string) error {
 	log.Printf("Deleting card: id=%s", cardID)
 
-	_, err := plannerCollection.UpdateOne(
+	plannerID, err := findPlannerIDByCard(ctx, cardID)
+	if err != nil {
+		return err
+	}
+	before, err := GetCard(ctx, cardID)
+	if err != nil {
+		return err
+	}
+
+	_, err = plannerCollection.UpdateOne(
 		ctx,
 		bson.M{"lanes.cards.id": cardID},
 		bson.M{"$pull": bson.M{"lanes.$[].cards": bson.M{"id": cardID}}},
 	)
-	return err
+	if err != nil {
+		return err
+	}
+	links, err := deleteCardLinks(ctx, cardID)
+	if err != nil {
+		return err
+	}
+	comments, err := deleteCardComments(ctx, cardID)
+	if err != nil {
+		return err
+	}
+	recordActivity(ctx, plannerID, EventCardDeleted, "card", cardID,
+		&ActivitySnapshot{Card: before, Links: links, Comments: comments}, nil)
+	if lane, _, err := getLane(ctx, before.LaneID); err == nil {
+		recordTransition(ctx, plannerID, cardID, lane, nil)
+	}
+	publishEvent(ctx, plannerID, EventCardDeleted, bson.M{"id": cardID})
+	return nil
 }
 
  // ReorderCards updates the positions of cards inside a lane in MongoDB
 func ReorderCards(ctx context.Context, laneID string, cardIDs []string) error {
 	log.Printf("Reordering cards: laneID=%s, cardCount=%d", laneID, len(cardIDs))
 
+	before, plannerID, err := getLane(ctx, laneID)
+	if err != nil {
+		return err
+	}
+
 	for i, cardID := range cardIDs {
 		filter := bson.M{"lanes.id": laneID, "lanes.cards.id": cardID}
 		update := bson.M{"$set": bson.M{"lanes.$[].cards.$[elem].position": i + 1}}
@@ -168,11 +329,33 @@ func ReorderCards(ctx context.Context, laneID string, cardIDs []string) error {
 			return err
 		}
 	}
+
+	if after, _, err := getLane(ctx, laneID); err == nil {
+		recordActivity(ctx, plannerID, EventCardsReordered, "lane", laneID,
+			&ActivitySnapshot{Lane: before}, &ActivitySnapshot{Lane: after})
+	}
+	publishEvent(ctx, plannerID, EventCardsReordered, bson.M{"lane_id": laneID, "card_ids": cardIDs})
 	return nil
 }
 
  // MoveCard moves a card to a different lane in MongoDB with position-aware insertion
  func MoveCard(ctx context.Context, cardID, newLaneID string, newPosition int) (*PlannerCard, error) {
+ 	return moveCard(ctx, cardID, newLaneID, newPosition, nil)
+ }
+
+// swimlaneTarget is the swimlane a card is moved into along with its lane
+type swimlaneTarget struct {
+	GroupBy string
+	Key     string
+}
+
+// MoveCardAcrossSwimlanes moves a card to a lane and position and sets the grouping attribute or field of
+// the target swimlane. The swimlane is validated before anything is written and stored with the move.
+func MoveCardAcrossSwimlanes(ctx context.Context, cardID, newLaneID string, newPosition int, groupBy, key string) (*PlannerCard, error) {
+	return moveCard(ctx, cardID, newLaneID, newPosition, &swimlaneTarget{GroupBy: groupBy, Key: key})
+}
+
+ func moveCard(ctx context.Context, cardID, newLaneID string, newPosition int, swimlane *swimlaneTarget) (*PlannerCard, error) {
  	log.Printf("Moving card: id=%s, newLaneID=%s, newPosition=%d", cardID, newLaneID, newPosition)
  
  	// Fetch full card before moving
@@ -181,18 +364,11 @@ func ReorderCards(ctx context.Context, laneID string, cardIDs []string) error {
  		return nil, err
  	}
  
- 	// Store original lane ID in the card history
- 
- 	// Backup into history
- 	if fullCard != nil && cardHistoryCollection != nil {
- 		historyRecord := bson.M{
- 			"card_id":   fullCard.ID,
- 			"lane_id":   fullCard.LaneID,
- 			"fields":    fullCard.Fields,
- 			"position":  fullCard.Position,
- 			"timestamp": time.Now(),
- 		}
- 		_, _ = cardHistoryCollection.InsertOne(ctx, historyRecord)
+ 	// Keep a copy of the card as it was for the activity log
+ 	before := *fullCard
+ 	before.Fields = map[string]interface{}{}
+ 	for k, v := range fullCard.Fields {
+ 		before.Fields[k] = v
  	}
  
  	// Get the target lane to determine proper positioning
@@ -213,59 +389,94 @@ func ReorderCards(ctx context.Context, laneID string, cardIDs []string) error {
  	if targetLane == nil {
  		return nil, fmt.Errorf("target lane not found: %s", newLaneID)
  	}
- 
- 	// Shift positions of existing cards in the target lane to make room
- 	for i := range targetLane.Cards {
- 		if targetLane.Cards[i].Position >= newPosition {
- 			_, err := plannerCollection.UpdateOne(
- 				ctx,
- 				bson.M{"lanes.id": newLaneID, "lanes.cards.id": targetLane.Cards[i].ID},
- 				bson.M{"$inc": bson.M{"lanes.$[].cards.$[elem].position": 1}},
- 				options.Update().SetArrayFilters(options.ArrayFilters{
- 					Filters: []interface{}{bson.M{"elem.id": targetLane.Cards[i].ID}},
- 				}),
- 			)
+ 	if swimlane != nil {
+ 		col, err := resolveGroupBy(&planner, swimlane.GroupBy)
+ 		if err != nil {
+ 			return nil, err
+ 		}
+ 		if swimlaneKey(fullCard, swimlane.GroupBy) != swimlane.Key {
+ 			patch, err := swimlanePatch(fullCard, swimlane.GroupBy, col, swimlane.Key)
  			if err != nil {
  				return nil, err
  			}
+ 			if err := applyCardPatch(&planner, fullCard, patch); err != nil {
+ 				return nil, err
+ 			}
  		}
  	}
- 
- 	// Remove from old lane
- 	_, err = plannerCollection.UpdateOne(
- 		ctx,
- 		bson.M{"lanes.cards.id": cardID},
- 		bson.M{"$pull": bson.M{"lanes.$[].cards": bson.M{"id": cardID}}},
- 	)
- 	if err != nil {
+ 	if err := checkWIPLimit(&planner, newLaneID, []PlannerCard{*fullCard}); err != nil {
  		return nil, err
  	}
+ 	warning := wipLimitWarning(&planner, newLaneID, []PlannerCard{*fullCard})
+ 	if planner.Settings.RejectBlockedDone && laneCategory(targetLane) == LaneCategoryDone {
+ 		blocked, err := unresolvedBlockers(ctx, &planner)
+ 		if err != nil {
+ 			return nil, err
+ 		}
+ 		if err := checkBlockedDone(&planner, targetLane, cardID, blocked); err != nil {
+ 			return nil, err
+ 		}
+ 	}
  
  	// Update lane + position
  	fullCard.LaneID = newLaneID
  	fullCard.Position = newPosition
  	fullCard.UpdatedAt = time.Now()
+ 	fullCard.Revision++
  	if fullCard.Fields == nil {
  		fullCard.Fields = map[string]interface{}{}
  	}
  	fullCard.Fields["lane_id"] = newLaneID
  	fullCard.Fields["moved_at"] = time.Now().Format(time.RFC3339)
  
- 	// Insert into new lane
- 	_, err = plannerCollection.UpdateOne(
+ 	// Move the card in one write, so that nothing changes if the target lane filled up or was removed meanwhile
+ 	result, err := plannerCollection.UpdateOne(
  		ctx,
- 		bson.M{"lanes.id": newLaneID},
- 		bson.M{"$push": bson.M{"lanes.$.cards": fullCard}},
+ 		withWIPLimitGuard(bson.M{"id": planner.ID, "lanes.id": newLaneID, "lanes.cards.id": cardID},
+ 			wipLimitGuard(&planner, newLaneID, []PlannerCard{*fullCard})),
+ 		cardSwapUpdate(fullCard, nil),
  	)
-	if err != nil {
-		return nil, err
-	}
+ 	if err != nil {
+ 		return nil, err
+ 	}
+ 	if result.MatchedCount == 0 {
+ 		return nil, wipLimitConflict(ctx, planner.ID, newLaneID, []PlannerCard{*fullCard})
+ 	}
+ 
+ 	// Shift positions of the other cards in the target lane to make room
+ 	for i := range targetLane.Cards {
+ 		if targetLane.Cards[i].ID != cardID && targetLane.Cards[i].Position >= newPosition {
+ 			_, err := plannerCollection.UpdateOne(
+ 				ctx,
+ 				bson.M{"lanes.id": newLaneID, "lanes.cards.id": targetLane.Cards[i].ID},
+ 				bson.M{"$inc": bson.M{"lanes.$[].cards.$[elem].position": 1}},
+ 				options.Update().SetArrayFilters(options.ArrayFilters{
+ 					Filters: []interface{}{bson.M{"elem.id": targetLane.Cards[i].ID}},
+ 				}),
+ 			)
+ 			if err != nil {
+ 				return nil, err
+ 			}
+ 		}
+ 	}
 
 	// Re-fetch authoritative card
 	updatedCard, err := GetCard(ctx, cardID)
 	if err != nil {
 		return nil, err
 	}
+	recordActivity(ctx, planner.ID, EventCardMoved, "card", cardID,
+		&ActivitySnapshot{Card: &before}, &ActivitySnapshot{Card: updatedCard})
+	if before.LaneID != newLaneID {
+		recordTransition(ctx, planner.ID, cardID, findLane(&planner, before.LaneID), targetLane)
+	}
+	publishEvent(ctx, planner.ID, EventCardMoved, updatedCard)
+	if before.LaneID != newLaneID {
+		updatedCard = fireCardRules(ctx, planner.ID, updatedCard,
+			ruleEvent{Trigger: RuleTriggerCardLeftLane, CardID: cardID, LaneID: before.LaneID},
+			ruleEvent{Trigger: RuleTriggerCardEnteredLane, CardID: cardID, LaneID: newLaneID})
+	}
+	updatedCard.WIPWarning = warning
 	return updatedCard, nil
 }
 
@@ -287,18 +498,22 @@ func HandleAddCard(w http.ResponseWriter, r *http.Request) {
 	
 	// Parse request body
 	var request struct {
-		Title    string `json:"title"`
-		Content  string `json:"content"`
-		Position int    `json:"position"`
+		Title    string                 `json:"title"`
+		Content  string                 `json:"content"`
+		Position int                    `json:"position"`
+		Fields   map[string]interface{} `json:"fields,omitempty"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 	
-	card, err := AddCard(r.Context(), laneID, request.Title, request.Content, request.Position)
+	card, err := AddCard(r.Context(), laneID, request.Title, request.Content, request.Position, request.Fields)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		if writeWIPLimitError(w, err) {
+			return
+		}
+		http.Error(w, err.Error(), cardErrorStatus(err))
 		return
 	}
 	
@@ -369,7 +584,7 @@ func HandleUpdateCard(w http.ResponseWriter, r *http.Request) {
 	
 	card, err := UpdateCard(r.Context(), cardID, request.Title, request.Content)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), cardErrorStatus(err))
 		return
 	}
 	
@@ -379,7 +594,61 @@ func HandleUpdateCard(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// HandleDeleteCard handles DELETE /planner/{id}/lane/{laneId}/card/{cardId}
+// HandlePatchCard handles PATCH /planner/{id}/lane/{laneId}/card/{cardId}
+func HandlePatchCard(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPatch {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Extract card ID from path
+	path := r.URL.Path
+	parts := strings.Split(path, "/")
+	if len(parts) != 7 || parts[1] != "planner" || parts[3] != "lane" || parts[5] != "card" {
+		http.Error(w, "Invalid path", http.StatusBadRequest)
+		return
+	}
+	cardID := parts[6]
+
+	// Parse request body; a null value removes the field or attribute
+	var request cardPatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	patch, err := request.toPatch()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	card, err := PatchCard(r.Context(), cardID, patch)
+	if err != nil {
+		http.Error(w, err.Error(), cardErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(card); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// cardErrorStatus maps card lookup and validation errors to HTTP status codes
+func cardErrorStatus(err error) int {
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return http.StatusNotFound
+	}
+	if errors.Is(err, ErrNotPlannerMember) || errors.Is(err, ErrInvalidGroupBy) {
+		return http.StatusBadRequest
+	}
+	if errors.Is(err, ErrCardBlocked) || errors.Is(err, ErrCardConflict) {
+		return http.StatusConflict
+	}
+	return columnErrorStatus(err)
+}
+
+// HandleDeleteCard handles DELETE /planner/{id}/lane/{laneId}/card/{cardId}[?permanent=true]
 func HandleDeleteCard(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodDelete {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -395,6 +664,15 @@ func HandleDeleteCard(w http.ResponseWriter, r *http.Request) {
 	}
 	cardID := parts[6]
 	
+	// Cards are archived unless a permanent delete is requested
+	if r.URL.Query().Get("permanent") != "true" {
+		if _, err := ArchiveCard(r.Context(), cardID); err != nil {
+			http.Error(w, err.Error(), archiveErrorStatus(err))
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
 	if err := DeleteCard(r.Context(), cardID); err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -457,25 +735,44 @@ func HandleMoveCard(w http.ResponseWriter, r *http.Request) {
 		NewLaneID   string `json:"new_lane_id"`
 		NewPosition int    `json:"new_position"`
 		Fields      map[string]interface{} `json:"fields,omitempty"`
+		GroupBy     string `json:"group_by,omitempty"`
+		Swimlane    *string `json:"swimlane,omitempty"` // target swimlane key when moving across swimlanes
 	}
 	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-	
-	card, err := MoveCard(r.Context(), cardID, request.NewLaneID, request.NewPosition)
-	if err == nil && request.Fields != nil {
-		if card.Fields == nil {
-			card.Fields = map[string]interface{}{}
-		}
-		for k, v := range request.Fields {
-			card.Fields[k] = v
+
+	// Validate fields before moving so an invalid request leaves the card untouched
+	if len(request.Fields) > 0 {
+		if _, _, err := validateFieldsForCard(r.Context(), cardID, request.Fields); err != nil {
+			http.Error(w, err.Error(), cardErrorStatus(err))
+			return
 		}
 	}
+
+	// The swimlane is checked and written together with the move, so an invalid swimlane leaves the card untouched
+	var card *PlannerCard
+	var err error
+	if request.Swimlane != nil {
+		card, err = MoveCardAcrossSwimlanes(r.Context(), cardID, request.NewLaneID, request.NewPosition, request.GroupBy, *request.Swimlane)
+	} else {
+		card, err = MoveCard(r.Context(), cardID, request.NewLaneID, request.NewPosition)
+	}
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		if writeWIPLimitError(w, err) {
+			return
+		}
+		http.Error(w, err.Error(), cardErrorStatus(err))
 		return
 	}
+	if len(request.Fields) > 0 {
+		card, err = PatchCardFields(r.Context(), cardID, request.Fields)
+		if err != nil {
+			http.Error(w, err.Error(), cardErrorStatus(err))
+			return
+		}
+	}
 	
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(card); err != nil {
@@ -0,0 +1,68 @@
+package planner
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestCardPatchUpdate(t *testing.T) {
+	planner := &Planner{
+		UserID:  "owner",
+		Columns: []PlannerColumn{{ID: "points", Name: "Points", Type: ColumnTypeNumber}, {ID: "owner", Name: "Owner", Type: ColumnTypeUser}},
+	}
+	now := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	update, err := cardPatchUpdate(planner, CardPatch{Fields: map[string]interface{}{
+		FieldTitle: "Renamed",
+		"points":   nil,
+	}}, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	set := update["$set"].(bson.M)
+	if set["lanes.$[].cards.$[elem].fields.title"] != "Renamed" || set["lanes.$[].cards.$[elem].updated_at"] != now {
+		t.Errorf("unexpected $set: %v", set)
+	}
+	if _, ok := update["$unset"].(bson.M)["lanes.$[].cards.$[elem].fields.points"]; !ok {
+		t.Errorf("expected null to unset the field, got %v", update)
+	}
+
+	// Only changed keys are written, and nothing is unset when nothing is cleared
+	update, _ = cardPatchUpdate(planner, CardPatch{Fields: map[string]interface{}{"points": 2}}, now)
+	if _, ok := update["$unset"]; ok || len(update["$set"].(bson.M)) != 2 {
+		t.Errorf("expected a single field and updated_at, got %v", update)
+	}
+
+	if _, err := cardPatchUpdate(planner, CardPatch{Fields: map[string]interface{}{"points": "two"}}, now); !errors.Is(err, ErrInvalidCardField) {
+		t.Errorf("expected ErrInvalidCardField, got %v", err)
+	}
+	if _, err := cardPatchUpdate(planner, CardPatch{Fields: map[string]interface{}{"owner": "stranger"}}, now); !errors.Is(err, ErrNotPlannerMember) {
+		t.Errorf("expected ErrNotPlannerMember, got %v", err)
+	}
+}
+
+func TestHandlePatchCardRejectsBadRequests(t *testing.T) {
+	cases := []struct {
+		method, path, body string
+		want               int
+	}{
+		{http.MethodPut, "/planner/p1/lane/l1/card/c1", `{}`, http.StatusMethodNotAllowed},
+		{http.MethodPatch, "/planner/p1/card/c1", `{}`, http.StatusBadRequest},
+		{http.MethodPatch, "/planner/p1/lane/l1/card/c1", `{"fields":`, http.StatusBadRequest},
+		// An empty patch is rejected before the card is loaded
+		{http.MethodPatch, "/planner/p1/lane/l1/card/c1", `{"fields":{}}`, http.StatusBadRequest},
+	}
+	for _, c := range cases {
+		w := httptest.NewRecorder()
+		HandlePatchCard(w, httptest.NewRequest(c.method, c.path, strings.NewReader(c.body)))
+		if w.Code != c.want {
+			t.Errorf("%s %s %s: expected %d, got %d", c.method, c.path, c.body, c.want, w.Code)
+		}
+	}
+}
@@ -0,0 +1,345 @@
+package planner
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ChecklistItem is an ordered subtask inside a card
+type ChecklistItem struct {
+	ID         string     `json:"id" bson:"id"`
+	Text       string     `json:"text" bson:"text"`
+	Done       bool       `json:"done" bson:"done"`
+	AssigneeID string     `json:"assignee_id,omitempty" bson:"assignee_id,omitempty"`
+	DueDate    *time.Time `json:"due_date,omitempty" bson:"due_date,omitempty"`
+	Position   int        `json:"position" bson:"position"`
+	CreatedAt  time.Time  `json:"created_at" bson:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at" bson:"updated_at"`
+}
+
+// ChecklistItemPatch is a partial checklist item update; nil values are left unchanged
+type ChecklistItemPatch struct {
+	Text       *string
+	Done       *bool
+	AssigneeID *string
+	DueDate    *time.Time // zero time clears the due date
+}
+
+// normalizeCard sorts a card's checklist and computes its progress
+func normalizeCard(card *PlannerCard) {
+	sort.Slice(card.Checklist, func(i, j int) bool {
+		return card.Checklist[i].Position < card.Checklist[j].Position
+	})
+	card.Progress = checklistProgress(card.Checklist)
+}
+
+// checklistProgress returns the percentage of completed checklist items
+func checklistProgress(items []ChecklistItem) int {
+	if len(items) == 0 {
+		return 0
+	}
+	done := 0
+	for _, item := range items {
+		if item.Done {
+			done++
+		}
+	}
+	return done * 100 / len(items)
+}
+
+// checklistUpdate runs an update against a single card and returns the authoritative card
+func checklistUpdate(ctx context.Context, cardID string, update bson.M, filters ...interface{}) (*PlannerCard, error) {
+	arrayFilters := options.Update().SetArrayFilters(options.ArrayFilters{
+		Filters: append([]interface{}{bson.M{"card.id": cardID}}, filters...),
+	})
+	before, err := GetCard(ctx, cardID)
+	if err != nil {
+		return nil, err
+	}
+	result, err := plannerCollection.UpdateOne(ctx, bson.M{"lanes.cards.id": cardID}, update, arrayFilters)
+	if err != nil {
+		return nil, err
+	}
+	if result.MatchedCount == 0 {
+		return nil, mongo.ErrNoDocuments
+	}
+
+	card, err := GetCard(ctx, cardID)
+	if err != nil {
+		return nil, err
+	}
+	if plannerID, err := findPlannerIDByCard(ctx, cardID); err == nil {
+		recordActivity(ctx, plannerID, EventCardUpdated, "card", cardID,
+			&ActivitySnapshot{Card: before}, &ActivitySnapshot{Card: card})
+		publishEvent(ctx, plannerID, EventCardUpdated, card)
+	}
+	return card, nil
+}
+
+// findChecklistItem returns the item with the given ID from a card
+func findChecklistItem(card *PlannerCard, itemID string) (*ChecklistItem, error) {
+	for i := range card.Checklist {
+		if card.Checklist[i].ID == itemID {
+			return &card.Checklist[i], nil
+		}
+	}
+	return nil, fmt.Errorf("checklist item not found: %s: %w", itemID, mongo.ErrNoDocuments)
+}
+
+// validateChecklistAssignee checks that a checklist assignee is a member of the card's planner
+func validateChecklistAssignee(ctx context.Context, cardID, assigneeID string) error {
+	if assigneeID == "" {
+		return nil
+	}
+	planner, err := loadCardPlanner(ctx, cardID)
+	if err != nil {
+		return err
+	}
+	if !IsPlannerMember(planner, assigneeID) {
+		return fmt.Errorf("%w: user %q cannot be assigned on this planner", ErrNotPlannerMember, assigneeID)
+	}
+	return nil
+}
+
+// AddChecklistItem appends an item to a card's checklist
+func AddChecklistItem(ctx context.Context, cardID, text, assigneeID string, dueDate *time.Time) (*PlannerCard, error) {
+	log.Printf("Adding checklist item: cardID=%s, text=%s", cardID, text)
+
+	if strings.TrimSpace(text) == "" {
+		return nil, fmt.Errorf("%w: checklist item text is required", ErrInvalidCardField)
+	}
+	card, err := GetCard(ctx, cardID)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateChecklistAssignee(ctx, cardID, assigneeID); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	item := ChecklistItem{
+		ID:         GenerateID(),
+		Text:       text,
+		AssigneeID: assigneeID,
+		Position:   len(card.Checklist) + 1,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	if dueDate != nil && !dueDate.IsZero() {
+		d := dueDate.UTC()
+		item.DueDate = &d
+	}
+
+	return checklistUpdate(ctx, cardID, bson.M{
+		"$push": bson.M{"lanes.$[].cards.$[card].checklist": item},
+		"$set":  bson.M{"lanes.$[].cards.$[card].updated_at": now},
+		"$inc":  bson.M{"lanes.$[].cards.$[card].revision": 1},
+	})
+}
+
+// UpdateChecklistItem applies a partial update to a checklist item
+func UpdateChecklistItem(ctx context.Context, cardID, itemID string, patch ChecklistItemPatch) (*PlannerCard, error) {
+	log.Printf("Updating checklist item: cardID=%s, itemID=%s", cardID, itemID)
+
+	now := time.Now()
+	prefix := "lanes.$[].cards.$[card].checklist.$[item]."
+	set := bson.M{
+		prefix + "updated_at":                now,
+		"lanes.$[].cards.$[card].updated_at": now,
+	}
+	unset := bson.M{}
+	if patch.Text != nil {
+		if strings.TrimSpace(*patch.Text) == "" {
+			return nil, fmt.Errorf("%w: checklist item text is required", ErrInvalidCardField)
+		}
+		set[prefix+"text"] = *patch.Text
+	}
+	if patch.Done != nil {
+		set[prefix+"done"] = *patch.Done
+	}
+	if patch.AssigneeID != nil {
+		if err := validateChecklistAssignee(ctx, cardID, *patch.AssigneeID); err != nil {
+			return nil, err
+		}
+		if *patch.AssigneeID == "" {
+			unset[prefix+"assignee_id"] = ""
+		} else {
+			set[prefix+"assignee_id"] = *patch.AssigneeID
+		}
+	}
+	if patch.DueDate != nil {
+		if patch.DueDate.IsZero() {
+			unset[prefix+"due_date"] = ""
+		} else {
+			set[prefix+"due_date"] = patch.DueDate.UTC()
+		}
+	}
+
+	card, err := GetCard(ctx, cardID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := findChecklistItem(card, itemID); err != nil {
+		return nil, err
+	}
+
+	update := bson.M{"$set": set, "$inc": bson.M{"lanes.$[].cards.$[card].revision": 1}}
+	if len(unset) > 0 {
+		update["$unset"] = unset
+	}
+	return checklistUpdate(ctx, cardID, update, bson.M{"item.id": itemID})
+}
+
+// ToggleChecklistItem flips the done state of a checklist item
+func ToggleChecklistItem(ctx context.Context, cardID, itemID string) (*PlannerCard, error) {
+	card, err := GetCard(ctx, cardID)
+	if err != nil {
+		return nil, err
+	}
+	item, err := findChecklistItem(card, itemID)
+	if err != nil {
+		return nil, err
+	}
+	done := !item.Done
+	return UpdateChecklistItem(ctx, cardID, itemID, ChecklistItemPatch{Done: &done})
+}
+
+// ReorderChecklist updates the positions of checklist items in a card
+func ReorderChecklist(ctx context.Context, cardID string, itemIDs []string) (*PlannerCard, error) {
+	log.Printf("Reordering checklist: cardID=%s, itemCount=%d", cardID, len(itemIDs))
+
+	card, err := GetCard(ctx, cardID)
+	if err != nil {
+		return nil, err
+	}
+	if len(itemIDs) != len(card.Checklist) {
+		return nil, fmt.Errorf("%w: expected %d checklist item IDs, got %d", ErrInvalidCardField, len(card.Checklist), len(itemIDs))
+	}
+
+	set := bson.M{"lanes.$[].cards.$[card].updated_at": time.Now()}
+	filters := []interface{}{}
+	for i, itemID := range itemIDs {
+		if _, err := findChecklistItem(card, itemID); err != nil {
+			return nil, err
+		}
+		identifier := fmt.Sprintf("item%d", i)
+		set[fmt.Sprintf("lanes.$[].cards.$[card].checklist.$[%s].position", identifier)] = i + 1
+		filters = append(filters, bson.M{identifier + ".id": itemID})
+	}
+	return checklistUpdate(ctx, cardID, bson.M{"$set": set, "$inc": bson.M{"lanes.$[].cards.$[card].revision": 1}}, filters...)
+}
+
+// DeleteChecklistItem removes an item from a card's checklist
+func DeleteChecklistItem(ctx context.Context, cardID, itemID string) (*PlannerCard, error) {
+	log.Printf("Deleting checklist item: cardID=%s, itemID=%s", cardID, itemID)
+
+	return checklistUpdate(ctx, cardID, bson.M{
+		"$pull": bson.M{"lanes.$[].cards.$[card].checklist": bson.M{"id": itemID}},
+		"$set":  bson.M{"lanes.$[].cards.$[card].updated_at": time.Now()},
+		"$inc":  bson.M{"lanes.$[].cards.$[card].revision": 1},
+	})
+}
+
+// checklistItemRequest is the JSON body for adding or updating a checklist item
+type checklistItemRequest struct {
+	Text       *string         `json:"text"`
+	Done       *bool           `json:"done"`
+	AssigneeID *string         `json:"assignee_id"`
+	DueDate    json.RawMessage `json:"due_date"`
+}
+
+// HandleChecklistRequest routes requests under /planner/{id}/card/{cardId}/checklist
+func HandleChecklistRequest(w http.ResponseWriter, r *http.Request) {
+	// /planner/{id}/card/{cardId}/checklist[/{itemId}[/toggle]]
+	path := r.URL.Path
+	parts := strings.Split(path, "/")
+	if len(parts) < 6 || parts[1] != "planner" || parts[3] != "card" || parts[5] != "checklist" {
+		http.Error(w, "Invalid path", http.StatusBadRequest)
+		return
+	}
+	cardID := parts[4]
+
+	var card *PlannerCard
+	var err error
+	switch {
+	case len(parts) == 6 && r.Method == http.MethodPost:
+		var request checklistItemRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		dueDate, err := parseOptionalDate(request.DueDate)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		text, assigneeID := "", ""
+		if request.Text != nil {
+			text = *request.Text
+		}
+		if request.AssigneeID != nil {
+			assigneeID = *request.AssigneeID
+		}
+		card, err = AddChecklistItem(r.Context(), cardID, text, assigneeID, dueDate)
+		if err != nil {
+			http.Error(w, err.Error(), cardErrorStatus(err))
+			return
+		}
+	case len(parts) == 7 && parts[6] == "reorder" && r.Method == http.MethodPut:
+		var request struct {
+			ItemIDs []string `json:"item_ids"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		card, err = ReorderChecklist(r.Context(), cardID, request.ItemIDs)
+	case len(parts) == 7 && r.Method == http.MethodPut:
+		var request checklistItemRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		dueDate, err := parseOptionalDate(request.DueDate)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		card, err = UpdateChecklistItem(r.Context(), cardID, parts[6], ChecklistItemPatch{
+			Text:       request.Text,
+			Done:       request.Done,
+			AssigneeID: request.AssigneeID,
+			DueDate:    dueDate,
+		})
+		if err != nil {
+			http.Error(w, err.Error(), cardErrorStatus(err))
+			return
+		}
+	case len(parts) == 7 && r.Method == http.MethodDelete:
+		card, err = DeleteChecklistItem(r.Context(), cardID, parts[6])
+	case len(parts) == 8 && parts[7] == "toggle" && r.Method == http.MethodPost:
+		card, err = ToggleChecklistItem(r.Context(), cardID, parts[6])
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), cardErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(card); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,50 @@
+package planner
+
+import (
+	"errors"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestChecklistProgress(t *testing.T) {
+	cases := []struct {
+		done []bool
+		want int
+	}{
+		{nil, 0},
+		{[]bool{false, false}, 0},
+		{[]bool{true, false, false}, 33},
+		{[]bool{true, true, false}, 66},
+		{[]bool{true, true}, 100},
+	}
+	for _, c := range cases {
+		items := []ChecklistItem{}
+		for _, d := range c.done {
+			items = append(items, ChecklistItem{Done: d})
+		}
+		if got := checklistProgress(items); got != c.want {
+			t.Errorf("%v: expected %d%%, got %d%%", c.done, c.want, got)
+		}
+	}
+}
+
+func TestNormalizeCardChecklist(t *testing.T) {
+	card := &PlannerCard{Checklist: []ChecklistItem{
+		{ID: "b", Position: 2, Done: true},
+		{ID: "a", Position: 1},
+		{ID: "c", Position: 3, Done: true},
+		{ID: "d", Position: 4},
+	}}
+	normalizeCard(card)
+	if card.Checklist[0].ID != "a" || card.Checklist[3].ID != "d" || card.Progress != 50 {
+		t.Errorf("unexpected checklist order or progress: %+v, %d", card.Checklist, card.Progress)
+	}
+
+	if item, err := findChecklistItem(card, "c"); err != nil || item.ID != "c" {
+		t.Errorf("expected to find item c, got %v, %v", item, err)
+	}
+	if _, err := findChecklistItem(card, "missing"); !errors.Is(err, mongo.ErrNoDocuments) {
+		t.Errorf("expected ErrNoDocuments for a missing item, got %v", err)
+	}
+}
@@ -0,0 +1,648 @@
+package planner
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Column types supported by PlannerColumn.Type
+const (
+	ColumnTypeText   = "text"
+	ColumnTypeStatus = "status"
+	ColumnTypeNumber = "number"
+	ColumnTypeDate   = "date"
+	ColumnTypeUser   = "user"
+)
+
+// Built-in card field keys that are not backed by a column
+const (
+	FieldTitle   = "title"
+	FieldContent = "content"
+)
+
+// ErrInvalidCardField is returned when a card field value does not match its column
+var ErrInvalidCardField = errors.New("invalid card field")
+
+// ErrInvalidColumn is returned when a column definition is invalid
+var ErrInvalidColumn = errors.New("invalid column")
+
+// isValidColumnType reports whether t is a supported column type
+func isValidColumnType(t string) bool {
+	switch t {
+	case ColumnTypeText, ColumnTypeStatus, ColumnTypeNumber, ColumnTypeDate, ColumnTypeUser:
+		return true
+	}
+	return false
+}
+
+// validateColumnDefinition checks the name, type and options of a column
+func validateColumnDefinition(name, colType string, statusOptions []string) error {
+	if strings.TrimSpace(name) == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidColumn)
+	}
+	if !isValidColumnType(colType) {
+		return fmt.Errorf("%w: unsupported type %q", ErrInvalidColumn, colType)
+	}
+	if colType != ColumnTypeStatus && len(statusOptions) > 0 {
+		return fmt.Errorf("%w: options are only allowed on status columns", ErrInvalidColumn)
+	}
+	seen := map[string]bool{}
+	for _, o := range statusOptions {
+		if strings.TrimSpace(o) == "" {
+			return fmt.Errorf("%w: status options cannot be empty", ErrInvalidColumn)
+		}
+		if seen[o] {
+			return fmt.Errorf("%w: duplicate status option %q", ErrInvalidColumn, o)
+		}
+		seen[o] = true
+	}
+	return nil
+}
+
+// validateColumnValue checks a card value against its column and returns the normalized value
+func validateColumnValue(col PlannerColumn, value interface{}) (interface{}, error) {
+	switch col.Type {
+	case ColumnTypeText, ColumnTypeUser:
+		s, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("%w: column %q expects a string", ErrInvalidCardField, col.Name)
+		}
+		return s, nil
+	case ColumnTypeStatus:
+		s, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("%w: column %q expects a string", ErrInvalidCardField, col.Name)
+		}
+		if len(col.Options) > 0 && !containsString(col.Options, s) {
+			return nil, fmt.Errorf("%w: %q is not an allowed status for column %q", ErrInvalidCardField, s, col.Name)
+		}
+		return s, nil
+	case ColumnTypeNumber:
+		n, ok := toFloat(value)
+		if !ok {
+			return nil, fmt.Errorf("%w: column %q expects a number", ErrInvalidCardField, col.Name)
+		}
+		return n, nil
+	case ColumnTypeDate:
+		s, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("%w: column %q expects a date string", ErrInvalidCardField, col.Name)
+		}
+		d, err := normalizeDate(s)
+		if err != nil {
+			return nil, fmt.Errorf("%w: column %q expects a date (YYYY-MM-DD or RFC 3339)", ErrInvalidCardField, col.Name)
+		}
+		return d, nil
+	}
+	return nil, fmt.Errorf("%w: column %q has unsupported type %q", ErrInvalidCardField, col.Name, col.Type)
+}
+
+// convertColumnValue converts an existing card value to a column's (new) type.
+// It is lenient where validateColumnValue is strict and reports false when no conversion exists.
+func convertColumnValue(col PlannerColumn, value interface{}) (interface{}, bool) {
+	if v, err := validateColumnValue(col, value); err == nil {
+		return v, true
+	}
+	switch col.Type {
+	case ColumnTypeText:
+		return fmt.Sprint(value), true
+	case ColumnTypeNumber:
+		if s, ok := value.(string); ok {
+			if n, err := strconv.ParseFloat(strings.TrimSpace(s), 64); err == nil {
+				return n, true
+			}
+		}
+	case ColumnTypeStatus, ColumnTypeUser, ColumnTypeDate:
+		// Numbers may still be valid once rendered as text, e.g. a status named "1"
+		if n, ok := toFloat(value); ok {
+			v, err := validateColumnValue(col, strconv.FormatFloat(n, 'f', -1, 64))
+			return v, err == nil
+		}
+	}
+	return nil, false
+}
+
+// migrateColumnValue converts a card value for a column whose definition changed. Values of user columns must
+// name a planner member, as they must when written to a card.
+func migrateColumnValue(planner *Planner, col PlannerColumn, value interface{}) (interface{}, bool) {
+	converted, ok := convertColumnValue(col, value)
+	if ok && col.Type == ColumnTypeUser && !IsPlannerMember(planner, fmt.Sprint(converted)) {
+		return nil, false
+	}
+	return converted, ok
+}
+
+// ValidateCardFields validates card field values against the planner's columns.
+// Keys must be a built-in field (title, content) or a column ID; a nil value means "unset".
+func ValidateCardFields(columns []PlannerColumn, fields map[string]interface{}) (map[string]interface{}, error) {
+	byID := map[string]PlannerColumn{}
+	for _, c := range columns {
+		byID[c.ID] = c
+	}
+
+	normalized := map[string]interface{}{}
+	for key, value := range fields {
+		if key == "" || strings.ContainsAny(key, ".$") {
+			return nil, fmt.Errorf("%w: invalid field key %q", ErrInvalidCardField, key)
+		}
+		if value == nil {
+			normalized[key] = nil
+			continue
+		}
+		if key == FieldTitle || key == FieldContent {
+			s, ok := value.(string)
+			if !ok {
+				return nil, fmt.Errorf("%w: %s must be a string", ErrInvalidCardField, key)
+			}
+			normalized[key] = s
+			continue
+		}
+		col, ok := byID[key]
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown field %q", ErrInvalidCardField, key)
+		}
+		v, err := validateColumnValue(col, value)
+		if err != nil {
+			return nil, err
+		}
+		normalized[key] = v
+	}
+	return normalized, nil
+}
+
+// parseDate parses YYYY-MM-DD (as UTC midnight) or RFC 3339
+func parseDate(s string) (time.Time, error) {
+	if t, err := time.Parse("2006-01-02", s); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
+
+// normalizeDate accepts YYYY-MM-DD or RFC 3339 and returns it in canonical form:
+// dates stay dates and timestamps are converted to UTC
+func normalizeDate(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	t, err := parseDate(s)
+	if err != nil {
+		return "", err
+	}
+	if len(s) == len("2006-01-02") {
+		return t.Format("2006-01-02"), nil
+	}
+	return t.UTC().Format(time.RFC3339), nil
+}
+
+// toFloat converts JSON and BSON numeric values to float64
+func toFloat(value interface{}) (float64, bool) {
+	switch n := value.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
+
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// getPlannerColumns loads a planner and returns it with its columns sorted by position
+func getPlannerColumns(ctx context.Context, plannerID string) (*Planner, error) {
+	var planner Planner
+	if err := plannerCollection.FindOne(ctx, bson.M{"id": plannerID}).Decode(&planner); err != nil {
+		return nil, err
+	}
+	sort.Slice(planner.Columns, func(i, j int) bool {
+		return planner.Columns[i].Position < planner.Columns[j].Position
+	})
+	return &planner, nil
+}
+
+// AddColumn adds a typed column to a planner at the specified position
+func AddColumn(ctx context.Context, plannerID, name, colType string, statusOptions []string, position int) (*PlannerColumn, error) {
+	log.Printf("Adding column: plannerID=%s, name=%s, type=%s, position=%d", plannerID, name, colType, position)
+
+	if err := validateColumnDefinition(name, colType, statusOptions); err != nil {
+		return nil, err
+	}
+
+	planner, err := getPlannerColumns(ctx, plannerID)
+	if err != nil {
+		return nil, err
+	}
+	if position <= 0 || position > len(planner.Columns)+1 {
+		position = len(planner.Columns) + 1
+	}
+
+	now := time.Now()
+	column := PlannerColumn{
+		ID:        GenerateID(),
+		PlannerID: plannerID,
+		Name:      name,
+		Type:      colType,
+		Options:   statusOptions,
+		Position:  position,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	// Shift positions of existing columns to make room for the new column
+	for _, c := range planner.Columns {
+		if c.Position >= position {
+			_, err := plannerCollection.UpdateOne(
+				ctx,
+				bson.M{"id": plannerID, "columns.id": c.ID},
+				bson.M{"$inc": bson.M{"columns.$.position": 1}},
+			)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	_, err = plannerCollection.UpdateOne(
+		ctx,
+		bson.M{"id": plannerID},
+		bson.M{"$push": bson.M{"columns": column}},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	publishEvent(ctx, plannerID, EventColumnAdded, column)
+	return &column, nil
+}
+
+// UpdateColumn renames a column or changes its type/options, migrating card values that no longer fit
+func UpdateColumn(ctx context.Context, plannerID, columnID, name, colType string, statusOptions []string) (*PlannerColumn, error) {
+	log.Printf("Updating column: plannerID=%s, id=%s, name=%s, type=%s", plannerID, columnID, name, colType)
+
+	if err := validateColumnDefinition(name, colType, statusOptions); err != nil {
+		return nil, err
+	}
+
+	planner, err := getPlannerColumns(ctx, plannerID)
+	if err != nil {
+		return nil, err
+	}
+	var column *PlannerColumn
+	for i := range planner.Columns {
+		if planner.Columns[i].ID == columnID {
+			column = &planner.Columns[i]
+			break
+		}
+	}
+	if column == nil {
+		return nil, fmt.Errorf("column not found: %s: %w", columnID, mongo.ErrNoDocuments)
+	}
+
+	column.Name = name
+	column.Type = colType
+	column.Options = statusOptions
+	column.UpdatedAt = time.Now()
+
+	_, err = plannerCollection.UpdateOne(
+		ctx,
+		bson.M{"id": plannerID, "columns.id": columnID},
+		bson.M{"$set": bson.M{
+			"columns.$.name":       column.Name,
+			"columns.$.type":       column.Type,
+			"columns.$.options":    column.Options,
+			"columns.$.updated_at": column.UpdatedAt,
+		}},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// Convert existing card values to the new definition, dropping those that cannot be converted
+	for _, lane := range planner.Lanes {
+		for _, card := range lane.Cards {
+			value, ok := card.Fields[columnID]
+			if !ok {
+				continue
+			}
+			fieldPath := "lanes.$[].cards.$[elem].fields." + columnID
+			var update bson.M
+			if converted, ok := migrateColumnValue(planner, *column, value); ok {
+				update = bson.M{"$set": bson.M{fieldPath: converted}}
+			} else {
+				log.Printf("UpdateColumn: dropping value of column %s on card %s", columnID, card.ID)
+				update = bson.M{"$unset": bson.M{fieldPath: ""}}
+			}
+			_, err := plannerCollection.UpdateOne(
+				ctx,
+				bson.M{"id": plannerID, "lanes.cards.id": card.ID},
+				update,
+				options.Update().SetArrayFilters(options.ArrayFilters{
+					Filters: []interface{}{bson.M{"elem.id": card.ID}},
+				}),
+			)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	publishEvent(ctx, plannerID, EventColumnUpdated, column)
+	return column, nil
+}
+
+// DeleteColumn removes a column from a planner and clears its values from every card. Settings, rules,
+// recurring cards and saved views that refer to the column are updated so nothing is left pointing at it:
+// rules using it are disabled, and view filter terms, sort keys and grouping on it are dropped.
+func DeleteColumn(ctx context.Context, plannerID, columnID string) error {
+	log.Printf("Deleting column: plannerID=%s, id=%s", plannerID, columnID)
+
+	planner, err := getPlannerColumns(ctx, plannerID)
+	if err != nil {
+		return err
+	}
+	after := *planner
+	after.Columns = []PlannerColumn{}
+	for _, c := range planner.Columns {
+		if c.ID != columnID {
+			after.Columns = append(after.Columns, c)
+		}
+	}
+	if len(after.Columns) == len(planner.Columns) {
+		return fmt.Errorf("column not found: %s: %w", columnID, mongo.ErrNoDocuments)
+	}
+
+	// Remove the column and clear its values in one write, skipping lanes whose cards array is missing
+	unset := bson.M{"lanes.$[lane].cards.$[card].fields." + columnID: ""}
+	if planner.Settings.StoryPointsColumn == columnID {
+		unset["settings.story_points_column"] = ""
+	}
+	if planner.SwimlaneGroupBy == columnID {
+		unset["swimlane_group_by"] = ""
+	}
+	update := bson.M{
+		"$pull":  bson.M{"columns": bson.M{"id": columnID}},
+		"$unset": unset,
+	}
+	filters := []interface{}{
+		bson.M{"lane.cards": bson.M{"$type": "array"}},
+		bson.M{"card.fields." + columnID: bson.M{"$exists": true}},
+	}
+	ruleIDs := rulesUsingField(planner.Rules, columnID)
+	if len(ruleIDs) > 0 {
+		update["$set"] = bson.M{"rules.$[rule].enabled": false, "rules.$[rule].updated_at": time.Now()}
+		filters = append(filters, bson.M{"rule.id": bson.M{"$in": ruleIDs}})
+	}
+	_, err = plannerCollection.UpdateOne(
+		ctx,
+		bson.M{"id": plannerID},
+		update,
+		options.Update().SetArrayFilters(options.ArrayFilters{Filters: filters}),
+	)
+	if err != nil {
+		return err
+	}
+
+	if recurrenceCollection != nil {
+		_, err = recurrenceCollection.UpdateMany(ctx,
+			bson.M{"planner_id": plannerID, "card.fields." + columnID: bson.M{"$exists": true}},
+			bson.M{"$unset": bson.M{"card.fields." + columnID: ""}},
+		)
+		if err != nil {
+			return err
+		}
+	}
+	if err := dropColumnFromViews(ctx, planner, &after); err != nil {
+		return err
+	}
+
+	publishEvent(ctx, plannerID, EventColumnDeleted, bson.M{"id": columnID})
+	if len(ruleIDs) > 0 {
+		publishEvent(ctx, plannerID, EventRulesUpdated, bson.M{"disabled": ruleIDs})
+	}
+	return nil
+}
+
+// dropColumnFromViews rewrites the saved views of a planner that filter, sort or group on a deleted column
+func dropColumnFromViews(ctx context.Context, before, after *Planner) error {
+	if viewCollection == nil {
+		return nil
+	}
+	cur, err := viewCollection.Find(ctx, bson.M{"planner_id": before.ID})
+	if err != nil {
+		return err
+	}
+	defer cur.Close(ctx)
+	views := []PlannerView{}
+	if err := cur.All(ctx, &views); err != nil {
+		return err
+	}
+	for _, view := range views {
+		if !dropColumnFromView(before, after, &view) {
+			continue
+		}
+		_, err := viewCollection.UpdateOne(ctx, bson.M{"id": view.ID}, bson.M{"$set": bson.M{
+			"filter":     view.Filter,
+			"sort":       view.Sort,
+			"group_by":   view.GroupBy,
+			"updated_at": time.Now(),
+		}})
+		if err != nil {
+			return err
+		}
+		if view.Scope == ViewScopePlanner {
+			publishEvent(ctx, before.ID, EventViewsUpdated, view)
+		}
+	}
+	return nil
+}
+
+// ReorderColumns updates the positions of columns in a planner
+func ReorderColumns(ctx context.Context, plannerID string, columnIDs []string) error {
+	log.Printf("Reordering columns: plannerID=%s, columnCount=%d", plannerID, len(columnIDs))
+
+	for i, columnID := range columnIDs {
+		filter := bson.M{"id": plannerID, "columns.id": columnID}
+		update := bson.M{"$set": bson.M{"columns.$.position": i + 1, "columns.$.updated_at": time.Now()}}
+		if _, err := plannerCollection.UpdateOne(ctx, filter, update); err != nil {
+			return err
+		}
+	}
+
+	publishEvent(ctx, plannerID, EventColumnsReordered, bson.M{"column_ids": columnIDs})
+	return nil
+}
+
+// columnErrorStatus maps column and field validation errors to 400, missing planners and columns to 404
+// and everything else to 500
+func columnErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, ErrInvalidColumn) || errors.Is(err, ErrInvalidCardField):
+		return http.StatusBadRequest
+	case errors.Is(err, mongo.ErrNoDocuments):
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
+
+// HandleAddColumn handles POST /planner/{id}/column
+func HandleAddColumn(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Extract planner ID from path
+	path := r.URL.Path
+	parts := strings.Split(path, "/")
+	if len(parts) != 4 || parts[1] != "planner" || parts[3] != "column" {
+		http.Error(w, "Invalid path", http.StatusBadRequest)
+		return
+	}
+	plannerID := parts[2]
+
+	// Parse request body
+	var request struct {
+		Name     string   `json:"name"`
+		Type     string   `json:"type"`
+		Options  []string `json:"options"`
+		Position int      `json:"position"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	column, err := AddColumn(r.Context(), plannerID, request.Name, request.Type, request.Options, request.Position)
+	if err != nil {
+		http.Error(w, err.Error(), columnErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(column); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// HandleUpdateColumn handles PUT /planner/{id}/column/{columnId}
+func HandleUpdateColumn(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Extract planner and column IDs from path
+	path := r.URL.Path
+	parts := strings.Split(path, "/")
+	if len(parts) != 5 || parts[1] != "planner" || parts[3] != "column" {
+		http.Error(w, "Invalid path", http.StatusBadRequest)
+		return
+	}
+	plannerID := parts[2]
+	columnID := parts[4]
+
+	// Parse request body
+	var request struct {
+		Name    string   `json:"name"`
+		Type    string   `json:"type"`
+		Options []string `json:"options"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	column, err := UpdateColumn(r.Context(), plannerID, columnID, request.Name, request.Type, request.Options)
+	if err != nil {
+		http.Error(w, err.Error(), columnErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(column); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// HandleDeleteColumn handles DELETE /planner/{id}/column/{columnId}
+func HandleDeleteColumn(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Extract planner and column IDs from path
+	path := r.URL.Path
+	parts := strings.Split(path, "/")
+	if len(parts) != 5 || parts[1] != "planner" || parts[3] != "column" {
+		http.Error(w, "Invalid path", http.StatusBadRequest)
+		return
+	}
+	plannerID := parts[2]
+	columnID := parts[4]
+
+	if err := DeleteColumn(r.Context(), plannerID, columnID); err != nil {
+		http.Error(w, err.Error(), columnErrorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// HandleReorderColumns handles PUT /planner/{id}/columns/reorder
+func HandleReorderColumns(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Extract planner ID from path
+	path := r.URL.Path
+	parts := strings.Split(path, "/")
+	if len(parts) != 5 || parts[1] != "planner" || parts[3] != "columns" || parts[4] != "reorder" {
+		http.Error(w, "Invalid path", http.StatusBadRequest)
+		return
+	}
+	plannerID := parts[2]
+
+	// Parse request body
+	var request struct {
+		ColumnIDs []string `json:"column_ids"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := ReorderColumns(r.Context(), plannerID, request.ColumnIDs); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,119 @@
+package planner
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestValidateCardFields(t *testing.T) {
+	columns := []PlannerColumn{
+		{ID: "status", Name: "Status", Type: ColumnTypeStatus, Options: []string{"Open", "Closed"}},
+		{ID: "points", Name: "Points", Type: ColumnTypeNumber},
+		{ID: "due", Name: "Due", Type: ColumnTypeDate},
+		{ID: "owner", Name: "Owner", Type: ColumnTypeUser},
+	}
+
+	fields, err := ValidateCardFields(columns, map[string]interface{}{
+		FieldTitle: "Card",
+		"status":   "Open",
+		"points":   3,
+		"due":      "2024-05-01T10:00:00+02:00",
+		"owner":    nil,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fields["points"] != 3.0 || fields["due"] != "2024-05-01T08:00:00Z" || fields[FieldTitle] != "Card" {
+		t.Errorf("unexpected normalized fields: %v", fields)
+	}
+	if v, ok := fields["owner"]; !ok || v != nil {
+		t.Errorf("expected nil to be kept to unset owner, got %v", v)
+	}
+
+	for name, bad := range map[string]map[string]interface{}{
+		"unknown column": {"nope": "x"},
+		"bad status":     {"status": "Maybe"},
+		"bad number":     {"points": "three"},
+		"bad date":       {"due": "01/05/2024"},
+		"title type":     {FieldTitle: 1},
+		"dotted key":     {"a.b": "x"},
+		"dollar key":     {"$set": "x"},
+	} {
+		if _, err := ValidateCardFields(columns, bad); !errors.Is(err, ErrInvalidCardField) {
+			t.Errorf("%s: expected ErrInvalidCardField, got %v", name, err)
+		}
+	}
+}
+
+func TestValidateColumnDefinition(t *testing.T) {
+	if err := validateColumnDefinition("Status", ColumnTypeStatus, []string{"Open", "Closed"}); err != nil {
+		t.Errorf("expected valid column, got %v", err)
+	}
+	for name, def := range map[string]struct {
+		name, colType string
+		options       []string
+	}{
+		"no name":          {"", ColumnTypeText, nil},
+		"bad type":         {"X", "color", nil},
+		"options on text":  {"X", ColumnTypeText, []string{"a"}},
+		"empty option":     {"X", ColumnTypeStatus, []string{" "}},
+		"duplicate option": {"X", ColumnTypeStatus, []string{"a", "a"}},
+	} {
+		if err := validateColumnDefinition(def.name, def.colType, def.options); !errors.Is(err, ErrInvalidColumn) {
+			t.Errorf("%s: expected ErrInvalidColumn, got %v", name, err)
+		}
+	}
+}
+
+func TestConvertColumnValue(t *testing.T) {
+	cases := []struct {
+		col   PlannerColumn
+		value interface{}
+		want  interface{}
+		ok    bool
+	}{
+		{PlannerColumn{Type: ColumnTypeText}, 4.5, "4.5", true},
+		{PlannerColumn{Type: ColumnTypeNumber}, " 12 ", 12.0, true},
+		{PlannerColumn{Type: ColumnTypeNumber}, "twelve", nil, false},
+		{PlannerColumn{Type: ColumnTypeStatus, Options: []string{"1", "2"}}, 1.0, "1", true},
+		{PlannerColumn{Type: ColumnTypeStatus, Options: []string{"Open"}}, "Closed", nil, false},
+		{PlannerColumn{Type: ColumnTypeDate}, "2024-05-01", "2024-05-01", true},
+	}
+	for i, c := range cases {
+		got, ok := convertColumnValue(c.col, c.value)
+		if ok != c.ok || (ok && got != c.want) {
+			t.Errorf("case %d: expected %v (%v), got %v (%v)", i, c.want, c.ok, got, ok)
+		}
+	}
+}
+
+func TestMigrateColumnValue(t *testing.T) {
+	planner := &Planner{UserID: "owner", Members: []string{"u1"}}
+	user := PlannerColumn{Name: "Owner", Type: ColumnTypeUser}
+	if got, ok := migrateColumnValue(planner, user, "u1"); !ok || got != "u1" {
+		t.Errorf("expected a member to be kept, got %v (%v)", got, ok)
+	}
+	if _, ok := migrateColumnValue(planner, user, "Alice"); ok {
+		t.Error("expected text that isn't a member to be dropped")
+	}
+	if got, ok := migrateColumnValue(planner, PlannerColumn{Name: "Note", Type: ColumnTypeText}, "Alice"); !ok || got != "Alice" {
+		t.Errorf("expected text columns to keep any value, got %v (%v)", got, ok)
+	}
+}
+
+func TestColumnErrorStatus(t *testing.T) {
+	cases := map[error]int{
+		fmt.Errorf("%w: x", ErrInvalidColumn):                        http.StatusBadRequest,
+		fmt.Errorf("column not found: c1: %w", mongo.ErrNoDocuments): http.StatusNotFound,
+		errors.New("boom"): http.StatusInternalServerError,
+	}
+	for err, want := range cases {
+		if got := columnErrorStatus(err); got != want {
+			t.Errorf("%v: expected %d, got %d", err, want, got)
+		}
+	}
+}
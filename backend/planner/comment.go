@@ -0,0 +1,567 @@
+package planner
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"zurabase/auth"
+)
+
+// CardComment is a markdown comment on a card; replies reference their parent comment
+type CardComment struct {
+	ID        string         `json:"id" bson:"id"`
+	PlannerID string         `json:"planner_id" bson:"planner_id"`
+	CardID    string         `json:"card_id" bson:"card_id"`
+	ParentID  string         `json:"parent_id,omitempty" bson:"parent_id,omitempty"`
+	AuthorID  string         `json:"author_id" bson:"author_id"`
+	Body      string         `json:"body" bson:"body"`
+	Mentions  []string       `json:"mentions,omitempty" bson:"mentions,omitempty"`
+	Deleted   bool           `json:"deleted,omitempty" bson:"deleted,omitempty"`
+	CreatedAt time.Time      `json:"created_at" bson:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at" bson:"updated_at"`
+	EditedAt  *time.Time     `json:"edited_at,omitempty" bson:"edited_at,omitempty"`
+	Replies   []*CardComment `json:"replies,omitempty" bson:"-"`
+}
+
+// Mention is an inbox record created when a user is @mentioned in a comment
+type Mention struct {
+	ID        string     `json:"id" bson:"id"`
+	UserID    string     `json:"user_id" bson:"user_id"`
+	AuthorID  string     `json:"author_id" bson:"author_id"`
+	PlannerID string     `json:"planner_id" bson:"planner_id"`
+	CardID    string     `json:"card_id" bson:"card_id"`
+	CommentID string     `json:"comment_id" bson:"comment_id"`
+	Excerpt   string     `json:"excerpt" bson:"excerpt"`
+	CreatedAt time.Time  `json:"created_at" bson:"created_at"`
+	ReadAt    *time.Time `json:"read_at,omitempty" bson:"read_at,omitempty"`
+}
+
+var commentCollection *mongo.Collection
+var mentionCollection *mongo.Collection
+
+var (
+	mentionPattern    = regexp.MustCompile(`(^|[^\w@])@([\w.+-]+(?:@[\w-]+(?:\.[\w-]+)+)?)`)
+	fencedCodePattern = regexp.MustCompile("(?s)```.*?```")
+	inlineCodePattern = regexp.MustCompile("`[^`\n]*`")
+)
+
+// parseMentionTokens returns the distinct @tokens in a markdown body, ignoring code
+func parseMentionTokens(body string) []string {
+	body = fencedCodePattern.ReplaceAllString(body, "")
+	body = inlineCodePattern.ReplaceAllString(body, "")
+
+	tokens := []string{}
+	for _, m := range mentionPattern.FindAllStringSubmatch(body, -1) {
+		token := strings.TrimRight(m[2], ".")
+		if token != "" && !containsString(tokens, token) {
+			tokens = append(tokens, token)
+		}
+	}
+	return tokens
+}
+
+// resolveMentions maps @tokens to planner member IDs by user ID, email or email name.
+// Members are looked up in one query, and only when a token isn't a member ID.
+func resolveMentions(ctx context.Context, planner *Planner, body string) []string {
+	tokens := parseMentionTokens(body)
+	if len(tokens) == 0 {
+		return nil
+	}
+
+	memberIDs := PlannerMemberIDs(planner)
+	users := map[string]*auth.User{}
+	for _, token := range tokens {
+		if containsString(memberIDs, token) {
+			continue
+		}
+		list, err := auth.GetUsersByIDs(ctx, memberIDs)
+		if err != nil {
+			log.Printf("resolveMentions: failed to load members of planner %s: %v", planner.ID, err)
+		}
+		for _, user := range list {
+			users[user.ID] = user
+		}
+		break
+	}
+	return matchMentions(tokens, memberIDs, users)
+}
+
+// matchMentions returns the members, in member order, that a token refers to by ID, email, email name
+// or name without spaces. users holds the members' accounts where known.
+func matchMentions(tokens, memberIDs []string, users map[string]*auth.User) []string {
+	resolved := []string{}
+	for _, memberID := range memberIDs {
+		handles := []string{memberID}
+		if user, ok := users[memberID]; ok {
+			handles = append(handles, user.Email)
+			if at := strings.Index(user.Email, "@"); at > 0 {
+				handles = append(handles, user.Email[:at])
+			}
+			handles = append(handles, strings.ReplaceAll(user.Name, " ", ""))
+		}
+		for _, token := range tokens {
+			for _, handle := range handles {
+				if handle != "" && strings.EqualFold(token, handle) && !containsString(resolved, memberID) {
+					resolved = append(resolved, memberID)
+				}
+			}
+		}
+	}
+	return resolved
+}
+
+// mentionEntries returns the inbox entries for users mentioned in a comment, leaving out its author
+func mentionEntries(comment *CardComment, userIDs []string, now time.Time) []Mention {
+	excerpt := comment.Body
+	if runes := []rune(excerpt); len(runes) > 200 {
+		excerpt = string(runes[:200]) + "…"
+	}
+	mentions := []Mention{}
+	for _, userID := range userIDs {
+		if userID == comment.AuthorID {
+			continue
+		}
+		mentions = append(mentions, Mention{
+			ID:        GenerateID(),
+			UserID:    userID,
+			AuthorID:  comment.AuthorID,
+			PlannerID: comment.PlannerID,
+			CardID:    comment.CardID,
+			CommentID: comment.ID,
+			Excerpt:   excerpt,
+			CreatedAt: now,
+		})
+	}
+	return mentions
+}
+
+// newMentions returns the users mentioned now that weren't mentioned before
+func newMentions(before, now []string) []string {
+	added := []string{}
+	for _, userID := range now {
+		if !containsString(before, userID) {
+			added = append(added, userID)
+		}
+	}
+	return added
+}
+
+// createMentions records inbox entries for newly mentioned users other than the author
+func createMentions(ctx context.Context, comment *CardComment, userIDs []string) error {
+	var docs []interface{}
+	for _, mention := range mentionEntries(comment, userIDs, time.Now()) {
+		docs = append(docs, mention)
+	}
+	if len(docs) == 0 {
+		return nil
+	}
+	_, err := mentionCollection.InsertMany(ctx, docs)
+	return err
+}
+
+// loadCommentPlanner loads the card's planner and checks the author may comment on it
+func loadCommentPlanner(ctx context.Context, cardID, authorID string) (*Planner, error) {
+	planner, err := loadCardPlanner(ctx, cardID)
+	if err != nil {
+		return nil, err
+	}
+	if len(PlannerMemberIDs(planner)) > 0 && !IsPlannerMember(planner, authorID) {
+		return nil, fmt.Errorf("%w: only planner members can comment", ErrForbidden)
+	}
+	return planner, nil
+}
+
+// AddComment adds a comment (or a reply when parentID is set) to a card
+func AddComment(ctx context.Context, cardID, authorID, parentID, body string) (*CardComment, error) {
+	log.Printf("Adding comment: cardID=%s, authorID=%s, parentID=%s", cardID, authorID, parentID)
+
+	if strings.TrimSpace(body) == "" {
+		return nil, fmt.Errorf("%w: comment body is required", ErrInvalidCardField)
+	}
+	planner, err := loadCommentPlanner(ctx, cardID, authorID)
+	if err != nil {
+		return nil, err
+	}
+	if parentID != "" {
+		var parent CardComment
+		if err := commentCollection.FindOne(ctx, bson.M{"id": parentID, "card_id": cardID}).Decode(&parent); err != nil {
+			return nil, fmt.Errorf("parent comment not found: %s: %w", parentID, err)
+		}
+	}
+
+	now := time.Now()
+	comment := &CardComment{
+		ID:        GenerateID(),
+		PlannerID: planner.ID,
+		CardID:    cardID,
+		ParentID:  parentID,
+		AuthorID:  authorID,
+		Body:      body,
+		Mentions:  resolveMentions(ctx, planner, body),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if _, err := commentCollection.InsertOne(ctx, comment); err != nil {
+		return nil, err
+	}
+	if err := createMentions(ctx, comment, comment.Mentions); err != nil {
+		log.Printf("AddComment: failed to record mentions for comment %s: %v", comment.ID, err)
+	}
+
+	publishEvent(ctx, planner.ID, EventCommentAdded, comment)
+	return comment, nil
+}
+
+// getOwnComment loads a comment and checks that it was written by the caller
+func getOwnComment(ctx context.Context, cardID, commentID, authorID string) (*CardComment, error) {
+	var comment CardComment
+	err := commentCollection.FindOne(ctx, bson.M{"id": commentID, "card_id": cardID}).Decode(&comment)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkCommentAuthor(&comment, authorID); err != nil {
+		return nil, err
+	}
+	return &comment, nil
+}
+
+// checkCommentAuthor checks that a comment still exists and was written by the user
+func checkCommentAuthor(comment *CardComment, authorID string) error {
+	if comment.Deleted {
+		return fmt.Errorf("comment %s was deleted: %w", comment.ID, mongo.ErrNoDocuments)
+	}
+	if comment.AuthorID != authorID {
+		return fmt.Errorf("%w: only the author can change a comment", ErrForbidden)
+	}
+	return nil
+}
+
+// UpdateComment edits the body of a comment; only the author may edit it
+func UpdateComment(ctx context.Context, cardID, commentID, authorID, body string) (*CardComment, error) {
+	log.Printf("Updating comment: id=%s, authorID=%s", commentID, authorID)
+
+	if strings.TrimSpace(body) == "" {
+		return nil, fmt.Errorf("%w: comment body is required", ErrInvalidCardField)
+	}
+	comment, err := getOwnComment(ctx, cardID, commentID, authorID)
+	if err != nil {
+		return nil, err
+	}
+	planner, err := loadCardPlanner(ctx, cardID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only users mentioned for the first time get a new inbox entry
+	mentions := resolveMentions(ctx, planner, body)
+	added := newMentions(comment.Mentions, mentions)
+
+	now := time.Now()
+	comment.Body = body
+	comment.Mentions = mentions
+	comment.UpdatedAt = now
+	comment.EditedAt = &now
+	_, err = commentCollection.UpdateOne(ctx,
+		bson.M{"id": commentID},
+		bson.M{"$set": bson.M{"body": body, "mentions": mentions, "updated_at": now, "edited_at": now}},
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := createMentions(ctx, comment, added); err != nil {
+		log.Printf("UpdateComment: failed to record mentions for comment %s: %v", commentID, err)
+	}
+
+	publishEvent(ctx, comment.PlannerID, EventCommentUpdated, comment)
+	return comment, nil
+}
+
+// DeleteComment deletes a comment; comments with replies are kept as a tombstone
+func DeleteComment(ctx context.Context, cardID, commentID, authorID string) error {
+	log.Printf("Deleting comment: id=%s, authorID=%s", commentID, authorID)
+
+	comment, err := getOwnComment(ctx, cardID, commentID, authorID)
+	if err != nil {
+		return err
+	}
+
+	replies, err := commentCollection.CountDocuments(ctx, bson.M{"parent_id": commentID})
+	if err != nil {
+		return err
+	}
+	if replies > 0 {
+		_, err = commentCollection.UpdateOne(ctx, bson.M{"id": commentID}, commentTombstoneUpdate(time.Now()))
+	} else {
+		_, err = commentCollection.DeleteOne(ctx, bson.M{"id": commentID})
+	}
+	if err != nil {
+		return err
+	}
+	if _, err := mentionCollection.DeleteMany(ctx, bson.M{"comment_id": commentID}); err != nil {
+		log.Printf("DeleteComment: failed to remove mentions for comment %s: %v", commentID, err)
+	}
+
+	publishEvent(ctx, comment.PlannerID, EventCommentDeleted, bson.M{"id": commentID, "card_id": cardID})
+	return nil
+}
+
+// commentTombstoneUpdate blanks a deleted comment that has replies, keeping it so the thread stays intact
+func commentTombstoneUpdate(now time.Time) bson.M {
+	return bson.M{
+		"$set":   bson.M{"deleted": true, "body": "", "updated_at": now},
+		"$unset": bson.M{"mentions": ""},
+	}
+}
+
+// deleteCardComments removes the comments of deleted cards and the mentions pointing at them. The comments
+// are returned so undoing the delete can restore them; inbox entries are not restored.
+func deleteCardComments(ctx context.Context, cardIDs ...string) ([]CardComment, error) {
+	filter := bson.M{"card_id": bson.M{"$in": cardIDs}}
+	cur, err := commentCollection.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	comments := []CardComment{}
+	if err := cur.All(ctx, &comments); err != nil {
+		return nil, err
+	}
+	if len(comments) > 0 {
+		if _, err := commentCollection.DeleteMany(ctx, filter); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := mentionCollection.DeleteMany(ctx, filter); err != nil {
+		return nil, err
+	}
+	return comments, nil
+}
+
+// restoreCardComments puts back comments removed with a card. Comments that exist again are skipped.
+func restoreCardComments(ctx context.Context, comments []CardComment) error {
+	if len(comments) == 0 {
+		return nil
+	}
+	docs := make([]interface{}, len(comments))
+	for i := range comments {
+		docs[i] = comments[i]
+	}
+	_, err := commentCollection.InsertMany(ctx, docs, options.InsertMany().SetOrdered(false))
+	if err != nil && !mongo.IsDuplicateKeyError(err) {
+		return err
+	}
+	return nil
+}
+
+// GetComments returns the comments of a card as threads ordered by creation time
+func GetComments(ctx context.Context, cardID string) ([]*CardComment, error) {
+	log.Printf("Getting comments: cardID=%s", cardID)
+
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}})
+	cursor, err := commentCollection.Find(ctx, bson.M{"card_id": cardID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var comments []*CardComment
+	if err := cursor.All(ctx, &comments); err != nil {
+		return nil, err
+	}
+	return threadComments(comments), nil
+}
+
+// threadComments nests replies under their parent comment. Replies whose parent is gone become threads.
+func threadComments(comments []*CardComment) []*CardComment {
+	byID := map[string]*CardComment{}
+	for _, c := range comments {
+		byID[c.ID] = c
+	}
+	threads := []*CardComment{}
+	for _, c := range comments {
+		if parent, ok := byID[c.ParentID]; ok && c.ParentID != "" {
+			parent.Replies = append(parent.Replies, c)
+		} else {
+			threads = append(threads, c)
+		}
+	}
+	return threads
+}
+
+// GetMentions returns a user's mention inbox, newest first
+func GetMentions(ctx context.Context, userID string, unreadOnly bool, limit int) ([]Mention, error) {
+	filter := bson.M{"user_id": userID}
+	if unreadOnly {
+		filter["read_at"] = bson.M{"$exists": false}
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}).SetLimit(int64(limit))
+	cursor, err := mentionCollection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	mentions := []Mention{}
+	if err := cursor.All(ctx, &mentions); err != nil {
+		return nil, err
+	}
+	return mentions, nil
+}
+
+// MarkMentionRead marks a mention in a user's inbox as read
+func MarkMentionRead(ctx context.Context, userID, mentionID string) error {
+	result, err := mentionCollection.UpdateOne(ctx,
+		bson.M{"id": mentionID, "user_id": userID},
+		bson.M{"$set": bson.M{"read_at": time.Now()}},
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// commentErrorStatus maps comment errors to HTTP status codes
+func commentErrorStatus(err error) int {
+	if errors.Is(err, ErrForbidden) {
+		return http.StatusForbidden
+	}
+	return cardErrorStatus(err)
+}
+
+// HandleCommentsRequest routes requests under /planner/card/{id}/comments
+func HandleCommentsRequest(w http.ResponseWriter, r *http.Request) {
+	// /planner/card/{cardId}/comments[/{commentId}]
+	path := r.URL.Path
+	parts := strings.Split(path, "/")
+	if len(parts) < 5 || len(parts) > 6 || parts[1] != "planner" || parts[2] != "card" || parts[4] != "comments" {
+		http.Error(w, "Invalid path", http.StatusBadRequest)
+		return
+	}
+	cardID := parts[3]
+
+	if len(parts) == 5 && r.Method == http.MethodGet {
+		comments, err := GetComments(r.Context(), cardID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(comments); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	userID, _ := r.Context().Value("user_id").(string)
+	if userID == "" {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var request struct {
+		Body     string `json:"body"`
+		ParentID string `json:"parent_id"`
+	}
+	if r.Method == http.MethodPost || r.Method == http.MethodPut {
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	var comment *CardComment
+	var err error
+	switch {
+	case len(parts) == 5 && r.Method == http.MethodPost:
+		comment, err = AddComment(r.Context(), cardID, userID, request.ParentID, request.Body)
+	case len(parts) == 6 && r.Method == http.MethodPut:
+		comment, err = UpdateComment(r.Context(), cardID, parts[5], userID, request.Body)
+	case len(parts) == 6 && r.Method == http.MethodDelete:
+		if err := DeleteComment(r.Context(), cardID, parts[5], userID); err != nil {
+			http.Error(w, err.Error(), commentErrorStatus(err))
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), commentErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(comment); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// HandleListMentions handles GET /me/mentions?unread=true&limit={n}
+func HandleListMentions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	userID, _ := r.Context().Value("user_id").(string)
+	if userID == "" {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	limit := 50
+	if v, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && v > 0 && v <= 200 {
+		limit = v
+	}
+	mentions, err := GetMentions(r.Context(), userID, r.URL.Query().Get("unread") == "true", limit)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(mentions); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// HandleMarkMentionRead handles POST /me/mentions/{id}/read
+func HandleMarkMentionRead(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	userID, _ := r.Context().Value("user_id").(string)
+	if userID == "" {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	path := r.URL.Path
+	parts := strings.Split(path, "/")
+	if len(parts) != 5 || parts[1] != "me" || parts[2] != "mentions" || parts[4] != "read" {
+		http.Error(w, "Invalid path", http.StatusBadRequest)
+		return
+	}
+
+	if err := MarkMentionRead(r.Context(), userID, parts[3]); err != nil {
+		http.Error(w, err.Error(), cardErrorStatus(err))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
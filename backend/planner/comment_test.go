@@ -0,0 +1,138 @@
+package planner
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"zurabase/auth"
+)
+
+func TestParseMentionTokens(t *testing.T) {
+	cases := map[string][]string{
+		"@alice please review":                 {"alice"},
+		"cc @alice, @bob and @alice again.":    {"alice", "bob"},
+		"ask @carol@example.com.":              {"carol@example.com"},
+		"email me at dave@example.com":         {},
+		"`@inline` code and ```\n@fenced\n```": {},
+		"(@erin) and @frank.":                  {"erin", "frank"},
+		"@@double":                             {},
+	}
+	for body, want := range cases {
+		if got := parseMentionTokens(body); !reflect.DeepEqual(got, want) {
+			t.Errorf("%q: expected %v, got %v", body, want, got)
+		}
+	}
+}
+
+func TestMatchMentions(t *testing.T) {
+	members := []string{"u1", "u2", "u3"}
+	users := map[string]*auth.User{
+		"u1": {ID: "u1", Email: "alice@example.com", Name: "Alice Smith"},
+		"u2": {ID: "u2", Email: "bob@example.com", Name: "Bob"},
+	}
+	cases := []struct {
+		tokens []string
+		want   []string
+	}{
+		{[]string{"ALICE"}, []string{"u1"}},
+		{[]string{"bob@example.com", "u3"}, []string{"u2", "u3"}},
+		{[]string{"alicesmith", "alice"}, []string{"u1"}},
+		{[]string{"mallory"}, []string{}},
+	}
+	for _, c := range cases {
+		if got := matchMentions(c.tokens, members, users); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%v: expected %v, got %v", c.tokens, c.want, got)
+		}
+	}
+	// Without the member accounts only IDs resolve
+	if got := matchMentions([]string{"alice", "u2"}, members, nil); !reflect.DeepEqual(got, []string{"u2"}) {
+		t.Errorf("expected only u2 without accounts, got %v", got)
+	}
+}
+
+func TestThreadComments(t *testing.T) {
+	comments := []*CardComment{
+		{ID: "a"},
+		{ID: "b", ParentID: "a"},
+		{ID: "c"},
+		{ID: "d", ParentID: "a"},
+		{ID: "e", ParentID: "gone"},
+	}
+	threads := threadComments(comments)
+	ids := []string{}
+	for _, c := range threads {
+		ids = append(ids, c.ID)
+	}
+	if !reflect.DeepEqual(ids, []string{"a", "c", "e"}) {
+		t.Fatalf("expected threads a, c, e, got %v", ids)
+	}
+	if len(threads[0].Replies) != 2 || threads[0].Replies[0].ID != "b" || threads[0].Replies[1].ID != "d" {
+		t.Errorf("expected replies b and d under a, got %v", threads[0].Replies)
+	}
+	if len(threads[1].Replies) != 0 {
+		t.Errorf("expected no replies under c, got %v", threads[1].Replies)
+	}
+}
+
+func TestCheckCommentAuthor(t *testing.T) {
+	comment := &CardComment{ID: "c1", AuthorID: "alice"}
+	if err := checkCommentAuthor(comment, "alice"); err != nil {
+		t.Errorf("expected the author to be allowed, got %v", err)
+	}
+	if err := checkCommentAuthor(comment, "bob"); !errors.Is(err, ErrForbidden) {
+		t.Errorf("expected ErrForbidden for another user, got %v", err)
+	}
+	comment.Deleted = true
+	if err := checkCommentAuthor(comment, "alice"); !errors.Is(err, mongo.ErrNoDocuments) {
+		t.Errorf("expected a deleted comment to be gone, got %v", err)
+	}
+}
+
+func TestCommentTombstoneUpdate(t *testing.T) {
+	now := time.Now()
+	update := commentTombstoneUpdate(now)
+	set := update["$set"].(bson.M)
+	if set["deleted"] != true || set["body"] != "" || set["updated_at"] != now {
+		t.Errorf("expected the tombstone to blank the body, got %v", set)
+	}
+	if _, ok := update["$unset"].(bson.M)["mentions"]; !ok {
+		t.Errorf("expected the tombstone to drop mentions, got %v", update)
+	}
+}
+
+func TestMentionEntries(t *testing.T) {
+	now := time.Now()
+	long := ""
+	for i := 0; i < 210; i++ {
+		long += "é"
+	}
+	comment := &CardComment{ID: "c1", PlannerID: "p1", CardID: "card1", AuthorID: "alice", Body: long}
+	mentions := mentionEntries(comment, []string{"alice", "bob", "carol"}, now)
+	if len(mentions) != 2 || mentions[0].UserID != "bob" || mentions[1].UserID != "carol" {
+		t.Fatalf("expected entries for bob and carol only, got %v", mentions)
+	}
+	m := mentions[0]
+	if m.AuthorID != "alice" || m.PlannerID != "p1" || m.CardID != "card1" || m.CommentID != "c1" || !m.CreatedAt.Equal(now) || m.ReadAt != nil {
+		t.Errorf("unexpected inbox entry %+v", m)
+	}
+	if runes := []rune(m.Excerpt); len(runes) != 201 || runes[200] != '…' {
+		t.Errorf("expected the excerpt cut to 200 runes, got %d", len(runes))
+	}
+	if mentions[0].ID == mentions[1].ID {
+		t.Errorf("expected distinct entry IDs")
+	}
+}
+
+func TestNewMentions(t *testing.T) {
+	if got := newMentions([]string{"a", "b"}, []string{"b", "c", "d"}); !reflect.DeepEqual(got, []string{"c", "d"}) {
+		t.Errorf("expected c and d, got %v", got)
+	}
+	if got := newMentions([]string{"a"}, nil); len(got) != 0 {
+		t.Errorf("expected nothing new, got %v", got)
+	}
+}
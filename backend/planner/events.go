@@ -0,0 +1,312 @@
+package planner
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Event types published for planner mutations
+const (
+	EventPlannerUpdated    = "planner_updated"
+	EventPlannerDeleted    = "planner_deleted"
+	EventMembersUpdated    = "members_updated"
+	EventLaneAdded         = "lane_added"
+	EventLaneUpdated       = "lane_updated"
+	EventLaneDeleted       = "lane_deleted"
+	EventLanesReordered    = "lanes_reordered"
+	EventLaneSplit         = "lane_split"
+	EventLaneUnsplit       = "lane_unsplit"
+	EventLaneArchived      = "lane_archived"
+	EventLaneRestored      = "lane_restored"
+	EventLaneCardsArchived = "lane_cards_archived"
+	EventCardAdded         = "card_added"
+	EventCardUpdated       = "card_updated"
+	EventCardDeleted       = "card_deleted"
+	EventCardMoved         = "card_moved"
+	EventCardArchived      = "card_archived"
+	EventCardRestored      = "card_restored"
+	EventCardsReordered    = "cards_reordered"
+	EventCardLinked        = "card_linked"
+	EventCardUnlinked      = "card_unlinked"
+	EventColumnAdded       = "column_added"
+	EventColumnUpdated     = "column_updated"
+	EventColumnDeleted     = "column_deleted"
+	EventColumnsReordered  = "columns_reordered"
+	EventCommentAdded      = "comment_added"
+	EventCommentUpdated    = "comment_updated"
+	EventCommentDeleted    = "comment_deleted"
+	EventActivityUndone    = "activity_undone"
+	EventRulesUpdated      = "rules_updated"
+	EventSprintsUpdated    = "sprints_updated"
+	EventSprintStarted     = "sprint_started"
+	EventSprintCompleted   = "sprint_completed"
+	EventViewsUpdated      = "views_updated"
+)
+
+// PlannerEvent is a typed change notification scoped to a single planner
+type PlannerEvent struct {
+	ID        string      `json:"id" bson:"id"`
+	Type      string      `json:"type" bson:"type"`
+	PlannerID string      `json:"planner_id" bson:"planner_id"`
+	ActorID   string      `json:"actor_id,omitempty" bson:"actor_id,omitempty"`
+	Payload   interface{} `json:"payload,omitempty" bson:"payload,omitempty"`
+	Timestamp time.Time   `json:"timestamp" bson:"timestamp"`
+}
+
+// EventBroker fans planner events out to subscribers.
+// Subscribe returns a channel that is closed once ctx is done.
+type EventBroker interface {
+	Publish(ctx context.Context, event PlannerEvent) error
+	Subscribe(ctx context.Context, plannerID string) (<-chan PlannerEvent, error)
+}
+
+// eventBroker is the broker used by all planner mutations
+var eventBroker EventBroker = NewMemoryEventBroker()
+
+// SetEventBroker replaces the broker used to publish planner events
+func SetEventBroker(b EventBroker) {
+	eventBroker = b
+}
+
+// publishEvent publishes an event for a planner, logging instead of failing the mutation
+func publishEvent(ctx context.Context, plannerID, eventType string, payload interface{}) {
+	if eventBroker == nil || plannerID == "" {
+		return
+	}
+	actorID, _ := ctx.Value("user_id").(string)
+	event := PlannerEvent{
+		ID:        GenerateID(),
+		Type:      eventType,
+		PlannerID: plannerID,
+		ActorID:   actorID,
+		Payload:   payload,
+		Timestamp: time.Now(),
+	}
+	if err := eventBroker.Publish(ctx, event); err != nil {
+		log.Printf("[events] failed to publish %s for planner %s: %v", eventType, plannerID, err)
+	}
+}
+
+// memoryEventBroker delivers events to subscribers within a single process
+type memoryEventBroker struct {
+	mu          sync.RWMutex
+	subscribers map[string]map[chan PlannerEvent]struct{}
+}
+
+// NewMemoryEventBroker returns an in-process EventBroker
+func NewMemoryEventBroker() EventBroker {
+	return &memoryEventBroker{subscribers: map[string]map[chan PlannerEvent]struct{}{}}
+}
+
+// Publish delivers the event to every subscriber of its planner, dropping it for slow subscribers
+func (b *memoryEventBroker) Publish(ctx context.Context, event PlannerEvent) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for ch := range b.subscribers[event.PlannerID] {
+		select {
+		case ch <- event:
+		default:
+			log.Printf("[events] dropping %s for slow subscriber on planner %s", event.Type, event.PlannerID)
+		}
+	}
+	return nil
+}
+
+// Subscribe registers a subscriber for a planner until ctx is done
+func (b *memoryEventBroker) Subscribe(ctx context.Context, plannerID string) (<-chan PlannerEvent, error) {
+	ch := make(chan PlannerEvent, 32)
+
+	b.mu.Lock()
+	if b.subscribers[plannerID] == nil {
+		b.subscribers[plannerID] = map[chan PlannerEvent]struct{}{}
+	}
+	b.subscribers[plannerID][ch] = struct{}{}
+	b.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		b.mu.Lock()
+		delete(b.subscribers[plannerID], ch)
+		if len(b.subscribers[plannerID]) == 0 {
+			delete(b.subscribers, plannerID)
+		}
+		b.mu.Unlock()
+		close(ch)
+	}()
+	return ch, nil
+}
+
+// mongoEventBroker shares events between backend instances through a MongoDB change stream.
+// It requires MongoDB to run as a replica set.
+type mongoEventBroker struct {
+	collection *mongo.Collection
+}
+
+// mongoEventRecord is the stored form of an event; the payload is kept as JSON so it decodes losslessly
+type mongoEventRecord struct {
+	ID          string    `bson:"id"`
+	Type        string    `bson:"type"`
+	PlannerID   string    `bson:"planner_id"`
+	ActorID     string    `bson:"actor_id,omitempty"`
+	PayloadJSON string    `bson:"payload_json,omitempty"`
+	Timestamp   time.Time `bson:"timestamp"`
+}
+
+// NewMongoEventBroker returns an EventBroker backed by the planner_events collection
+func NewMongoEventBroker(ctx context.Context, db *mongo.Database) (EventBroker, error) {
+	collection := db.Collection("planner_events")
+	// Events are only needed for live delivery, so expire them after a day
+	_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "timestamp", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(int32((24 * time.Hour).Seconds())),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create planner_events index: %w", err)
+	}
+	return &mongoEventBroker{collection: collection}, nil
+}
+
+// Publish stores the event so that every instance watching the collection receives it
+func (b *mongoEventBroker) Publish(ctx context.Context, event PlannerEvent) error {
+	record := mongoEventRecord{
+		ID:        event.ID,
+		Type:      event.Type,
+		PlannerID: event.PlannerID,
+		ActorID:   event.ActorID,
+		Timestamp: event.Timestamp,
+	}
+	if event.Payload != nil {
+		payload, err := json.Marshal(event.Payload)
+		if err != nil {
+			return err
+		}
+		record.PayloadJSON = string(payload)
+	}
+	_, err := b.collection.InsertOne(ctx, record)
+	return err
+}
+
+// Subscribe watches the collection for new events of a planner until ctx is done
+func (b *mongoEventBroker) Subscribe(ctx context.Context, plannerID string) (<-chan PlannerEvent, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"operationType":           "insert",
+			"fullDocument.planner_id": plannerID,
+		}}},
+	}
+	stream, err := b.collection.Watch(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+
+	ch := make(chan PlannerEvent, 32)
+	go func() {
+		defer close(ch)
+		defer stream.Close(context.Background())
+		for stream.Next(ctx) {
+			var change struct {
+				FullDocument mongoEventRecord `bson:"fullDocument"`
+			}
+			if err := stream.Decode(&change); err != nil {
+				log.Printf("[events] failed to decode change event: %v", err)
+				continue
+			}
+			record := change.FullDocument
+			event := PlannerEvent{
+				ID:        record.ID,
+				Type:      record.Type,
+				PlannerID: record.PlannerID,
+				ActorID:   record.ActorID,
+				Timestamp: record.Timestamp,
+			}
+			if record.PayloadJSON != "" {
+				event.Payload = json.RawMessage(record.PayloadJSON)
+			}
+			select {
+			case ch <- event:
+			case <-ctx.Done():
+				return
+			}
+		}
+		if err := stream.Err(); err != nil && ctx.Err() == nil {
+			log.Printf("[events] change stream for planner %s ended: %v", plannerID, err)
+		}
+	}()
+	return ch, nil
+}
+
+// HandleStreamPlannerEvents handles GET /planner/{id}/events as a Server-Sent Events stream
+func HandleStreamPlannerEvents(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Extract planner ID from path
+	path := r.URL.Path
+	parts := strings.Split(path, "/")
+	if len(parts) != 4 || parts[1] != "planner" || parts[3] != "events" {
+		http.Error(w, "Invalid path", http.StatusBadRequest)
+		return
+	}
+	plannerID := parts[2]
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	if _, err := GetPlanner(r.Context(), plannerID); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	events, err := eventBroker.Subscribe(r.Context(), plannerID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	fmt.Fprint(w, "retry: 3000\n\n")
+	flusher.Flush()
+
+	// Keep idle connections open through proxies
+	heartbeat := time.NewTicker(25 * time.Second)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": ping\n\n")
+			flusher.Flush()
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			data, err := json.Marshal(event)
+			if err != nil {
+				log.Printf("[events] failed to encode %s: %v", event.Type, err)
+				continue
+			}
+			fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+			flusher.Flush()
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package planner
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func receiveEvent(t *testing.T, ch <-chan PlannerEvent) PlannerEvent {
+	t.Helper()
+	select {
+	case event, ok := <-ch:
+		if !ok {
+			t.Fatal("subscription closed unexpectedly")
+		}
+		return event
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for event")
+	}
+	return PlannerEvent{}
+}
+
+func TestMemoryEventBroker(t *testing.T) {
+	broker := NewMemoryEventBroker()
+	ctx, cancel := context.WithCancel(context.Background())
+	first, err := broker.Subscribe(ctx, "p1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, _ := broker.Subscribe(ctx, "p1")
+	other, _ := broker.Subscribe(ctx, "p2")
+
+	broker.Publish(context.Background(), PlannerEvent{ID: "e1", Type: EventCardAdded, PlannerID: "p1"})
+	for _, ch := range []<-chan PlannerEvent{first, second} {
+		if event := receiveEvent(t, ch); event.ID != "e1" || event.Type != EventCardAdded {
+			t.Errorf("unexpected event %+v", event)
+		}
+	}
+	select {
+	case event := <-other:
+		t.Errorf("subscriber of another planner received %+v", event)
+	default:
+	}
+
+	cancel()
+	for _, ch := range []<-chan PlannerEvent{first, second, other} {
+		select {
+		case _, ok := <-ch:
+			if ok {
+				t.Error("expected no further events after cancel")
+			}
+		case <-time.After(time.Second):
+			t.Fatal("subscription was not closed after cancel")
+		}
+	}
+	b := broker.(*memoryEventBroker)
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if len(b.subscribers) != 0 {
+		t.Errorf("expected subscribers to be removed, got %d planners", len(b.subscribers))
+	}
+}
+
+func TestMemoryEventBrokerDropsForSlowSubscribers(t *testing.T) {
+	broker := NewMemoryEventBroker()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ch, _ := broker.Subscribe(ctx, "p1")
+
+	// Publish never blocks, even when nobody is reading
+	for i := 0; i < 100; i++ {
+		broker.Publish(context.Background(), PlannerEvent{Type: EventCardUpdated, PlannerID: "p1"})
+	}
+	if len(ch) != cap(ch) {
+		t.Errorf("expected a full buffer of %d events, got %d", cap(ch), len(ch))
+	}
+}
+
+func TestPublishEventActor(t *testing.T) {
+	broker := NewMemoryEventBroker()
+	previous := eventBroker
+	SetEventBroker(broker)
+	defer SetEventBroker(previous)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ch, _ := broker.Subscribe(ctx, "p1")
+
+	publishEvent(context.WithValue(context.Background(), "user_id", "u1"), "p1", EventLaneAdded, map[string]string{"id": "l1"})
+	event := receiveEvent(t, ch)
+	if event.ActorID != "u1" || event.PlannerID != "p1" || event.ID == "" || event.Timestamp.IsZero() {
+		t.Errorf("unexpected event %+v", event)
+	}
+	// Events without a planner are not published
+	publishEvent(context.Background(), "", EventLaneAdded, nil)
+	select {
+	case event := <-ch:
+		t.Errorf("unexpected event %+v", event)
+	default:
+	}
+}
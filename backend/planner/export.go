@@ -0,0 +1,367 @@
+package planner
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// PlannerExportSchemaVersion is the version of the JSON export format written by ExportPlannerJSON.
+// Bump it when the format changes incompatibly and teach ImportPlannerJSON to read older versions.
+const PlannerExportSchemaVersion = 1
+
+// ErrInvalidImport is returned when an import document can't be read
+var ErrInvalidImport = errors.New("invalid import")
+
+// PlannerExport is the lossless, versioned JSON representation of a planner
+type PlannerExport struct {
+	SchemaVersion int       `json:"schema_version"`
+	ExportedAt    time.Time `json:"exported_at"`
+	Planner       Planner   `json:"planner"`
+}
+
+// importResponse is the body returned by imports: the planner with its import report
+type importResponse struct {
+	*Planner
+	ImportReport *ImportReport `json:"import_report"`
+}
+
+// ExportPlannerJSON exports a planner with all lanes, columns and cards
+func ExportPlannerJSON(ctx context.Context, id string) (*PlannerExport, error) {
+	log.Printf("Exporting planner as JSON with id=%s", id)
+	planner, err := loadPlanner(ctx, id, true)
+	if err != nil {
+		return nil, err
+	}
+	return &PlannerExport{
+		SchemaVersion: PlannerExportSchemaVersion,
+		ExportedAt:    time.Now().UTC(),
+		Planner:       *planner,
+	}, nil
+}
+
+// ImportPlannerJSON creates a planner owned by userID from a JSON export. All IDs are regenerated
+// and references between columns, lanes and cards are remapped. If mergeInto is set, the exported
+// lanes and columns are added to that planner instead; columns are matched by name and type.
+func ImportPlannerJSON(ctx context.Context, export *PlannerExport, userID, mergeInto string) (*Planner, *ImportReport, error) {
+	log.Printf("Importing planner from JSON: schemaVersion=%d, userID=%s, mergeInto=%s", export.SchemaVersion, userID, mergeInto)
+
+	switch {
+	case export.SchemaVersion == 0:
+		return nil, nil, fmt.Errorf("%w: schema_version is required", ErrInvalidImport)
+	case export.SchemaVersion > PlannerExportSchemaVersion:
+		return nil, nil, fmt.Errorf("%w: unsupported schema_version %d (latest is %d)", ErrInvalidImport, export.SchemaVersion, PlannerExportSchemaVersion)
+	}
+
+	report := &ImportReport{Warnings: []ImportWarning{}}
+	source := export.Planner
+	now := time.Now()
+
+	var target *Planner
+	if mergeInto != "" {
+		var err error
+		if target, err = GetPlanner(ctx, mergeInto); err != nil {
+			return nil, nil, err
+		}
+		if target.UserID != "" && !IsPlannerMember(target, userID) {
+			return nil, nil, fmt.Errorf("%w: only planner members can merge into this planner", ErrForbidden)
+		}
+	} else {
+		target = &Planner{
+			ID:          GenerateID(),
+			UserID:      userID,
+			Title:       source.Title,
+			Description: source.Description,
+			TemplateID:  source.TemplateID,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+			Lanes:       []PlannerLane{},
+			Columns:     []PlannerColumn{},
+		}
+		// Member IDs in the file are not trusted: only the importer gets access to the new planner
+		for _, m := range PlannerMemberIDs(&source) {
+			if m != userID {
+				report.warn(0, "members", "member %q was not added; share the planner with them again", m)
+			}
+		}
+		if strings.TrimSpace(target.Title) == "" {
+			target.Title = "Imported Planner"
+		}
+	}
+
+	newLanes, newColumns := remapImport(&source, target, report)
+
+	if mergeInto == "" {
+		target.Columns = newColumns
+		target.Lanes = newLanes
+		if _, err := plannerCollection.InsertOne(ctx, target); err != nil {
+			return nil, nil, fmt.Errorf("failed to insert planner: %w", err)
+		}
+		recordImportedTransitions(ctx, target.ID, newLanes)
+		return target, report, nil
+	}
+
+	_, err := plannerCollection.UpdateOne(ctx,
+		bson.M{"id": target.ID},
+		bson.M{
+			"$push": bson.M{
+				"lanes":   bson.M{"$each": newLanes},
+				"columns": bson.M{"$each": newColumns},
+			},
+			"$set": bson.M{"updated_at": now},
+		},
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	recordImportedTransitions(ctx, target.ID, newLanes)
+	merged, err := GetPlanner(ctx, target.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	publishEvent(ctx, target.ID, EventPlannerUpdated, merged)
+	return merged, report, nil
+}
+
+// remapImport maps the columns and lanes of an exported planner onto target with new IDs and returns
+// the lanes and columns to add. Card assignees and user values must be members of target.
+func remapImport(source, target *Planner, report *ImportReport) ([]PlannerLane, []PlannerColumn) {
+	// Columns: reuse a target column with the same name and type, otherwise add a new one
+	columnIDs := map[string]string{}
+	newColumns := []PlannerColumn{}
+	for _, col := range source.Columns {
+		if err := validateColumnDefinition(col.Name, col.Type, col.Options); err != nil {
+			report.warn(0, col.Name, "column skipped: %v", err)
+			continue
+		}
+		for _, existing := range target.Columns {
+			if strings.EqualFold(existing.Name, col.Name) {
+				if existing.Type == col.Type {
+					columnIDs[col.ID] = existing.ID
+				}
+				break
+			}
+		}
+		if _, ok := columnIDs[col.ID]; ok {
+			continue
+		}
+		name := col.Name
+		for _, existing := range target.Columns {
+			if strings.EqualFold(existing.Name, name) {
+				name = col.Name + " (imported)"
+				report.warn(0, col.Name, "column exists with type %s, imported as %q", existing.Type, name)
+				break
+			}
+		}
+		newCol := col
+		newCol.ID = GenerateID()
+		newCol.PlannerID = target.ID
+		newCol.Name = name
+		newCol.Position = len(target.Columns) + len(newColumns) + 1
+		columnIDs[col.ID] = newCol.ID
+		newColumns = append(newColumns, newCol)
+	}
+	allColumns := append(append([]PlannerColumn{}, target.Columns...), newColumns...)
+	columnsByID := map[string]PlannerColumn{}
+	for _, col := range allColumns {
+		columnsByID[col.ID] = col
+	}
+
+	// Lanes keep their relative order after the target's existing lanes
+	laneIDs := map[string]string{}
+	for _, lane := range source.Lanes {
+		laneIDs[lane.ID] = GenerateID()
+	}
+	offset := 0
+	for _, lane := range target.Lanes {
+		if lane.Position > offset {
+			offset = lane.Position
+		}
+	}
+
+	// Assignees are checked against the planner the cards end up in
+	members := &Planner{UserID: target.UserID, Members: target.Members}
+	newLanes := []PlannerLane{}
+	for _, lane := range source.Lanes {
+		newLane := lane
+		newLane.ID = laneIDs[lane.ID]
+		newLane.PlannerID = target.ID
+		newLane.Position = offset + lane.Position
+		if mapped, ok := laneIDs[lane.TemplateLaneID]; ok {
+			newLane.TemplateLaneID = mapped
+		}
+		newLane.Cards = []PlannerCard{}
+		for _, card := range lane.Cards {
+			newLane.Cards = append(newLane.Cards, remapImportedCard(card, newLane.ID, columnIDs, columnsByID, members, report))
+		}
+		report.Cards += len(newLane.Cards)
+		newLanes = append(newLanes, newLane)
+	}
+	report.Lanes = len(newLanes)
+	report.Columns = len(newColumns)
+	return newLanes, newColumns
+}
+
+// remapImportedCard gives a card and its checklist new IDs and maps its field keys to the imported columns
+func remapImportedCard(card PlannerCard, laneID string, columnIDs map[string]string, columns map[string]PlannerColumn, members *Planner, report *ImportReport) PlannerCard {
+	title, _ := card.Fields[FieldTitle].(string)
+
+	card.ID = GenerateID()
+	card.LaneID = laneID
+	card.SprintID = "" // sprints aren't part of imports
+	fields := map[string]interface{}{}
+	for key, value := range card.Fields {
+		if key == FieldTitle || key == FieldContent {
+			fields[key] = value
+			continue
+		}
+		// Bookkeeping written by MoveCard, not column values
+		if key == "lane_id" {
+			fields[key] = laneID
+			continue
+		}
+		if key == "moved_at" {
+			fields[key] = value
+			continue
+		}
+		colID, ok := columnIDs[key]
+		if !ok {
+			report.warn(0, title, "value for unknown column %q dropped", key)
+			continue
+		}
+		col := columns[colID]
+		converted, ok := convertColumnValue(col, value)
+		if !ok || (col.Type == ColumnTypeUser && !IsPlannerMember(members, fmt.Sprint(converted))) {
+			report.warn(0, title, "value %v is not valid for column %q and was dropped", value, col.Name)
+			continue
+		}
+		fields[colID] = converted
+	}
+	card.Fields = fields
+
+	assignees := []string{}
+	for _, a := range card.Assignees {
+		if IsPlannerMember(members, a) {
+			assignees = append(assignees, a)
+		} else {
+			report.warn(0, title, "assignee %q is not a planner member and was dropped", a)
+		}
+	}
+	card.Assignees = nil
+	if len(assignees) > 0 {
+		card.Assignees = assignees
+	}
+
+	for i := range card.Checklist {
+		card.Checklist[i].ID = GenerateID()
+		if a := card.Checklist[i].AssigneeID; a != "" && !IsPlannerMember(members, a) {
+			report.warn(0, title, "checklist assignee %q is not a planner member and was dropped", a)
+			card.Checklist[i].AssigneeID = ""
+		}
+	}
+	report.ChecklistItems += len(card.Checklist)
+	return card
+}
+
+// HandleExportPlanner handles GET /planner/{id}/export?format={markdown|json|csv|xlsx}
+func HandleExportPlanner(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Query().Get("format") {
+	case "", "markdown", "md":
+		HandleExportPlannerMarkdown(w, r)
+	case "json":
+		HandleExportPlannerJSON(w, r)
+	case "csv", "xlsx":
+		HandleExportPlannerSheet(w, r)
+	default:
+		http.Error(w, "Unsupported export format", http.StatusBadRequest)
+	}
+}
+
+// HandleImportPlanner handles POST /planner/import?format={markdown|json|trello|github|jira}
+func HandleImportPlanner(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Query().Get("format") {
+	case "", "markdown", "md":
+		HandleImportPlannerMarkdown(w, r)
+	case "json":
+		HandleImportPlannerJSON(w, r)
+	case ImportFormatTrello, ImportFormatGitHub, ImportFormatJira:
+		HandleImportPlannerFromFormat(w, r)
+	default:
+		http.Error(w, "Unsupported import format", http.StatusBadRequest)
+	}
+}
+
+// HandleExportPlannerJSON handles GET /planner/{id}/export?format=json
+func HandleExportPlannerJSON(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Extract ID from path
+	path := r.URL.Path
+	if len(path) <= len("/planner/") || !strings.HasSuffix(path, "/export") {
+		http.Error(w, "Invalid path", http.StatusBadRequest)
+		return
+	}
+	id := path[len("/planner/") : len(path)-len("/export")]
+
+	export, err := ExportPlannerJSON(r.Context(), id)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			status = http.StatusNotFound
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", "attachment; filename=\"planner.json\"")
+	if err := json.NewEncoder(w).Encode(export); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// HandleImportPlannerJSON handles POST /planner/import?format=json[&merge_into={plannerId}]
+func HandleImportPlannerJSON(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var export PlannerExport
+	if err := json.NewDecoder(r.Body).Decode(&export); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	userID, _ := r.Context().Value("user_id").(string)
+
+	planner, report, err := ImportPlannerJSON(r.Context(), &export, userID, r.URL.Query().Get("merge_into"))
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, ErrInvalidImport):
+			status = http.StatusBadRequest
+		case errors.Is(err, ErrForbidden):
+			status = http.StatusForbidden
+		case errors.Is(err, mongo.ErrNoDocuments):
+			status = http.StatusNotFound
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(importResponse{planner, report}); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
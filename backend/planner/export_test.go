@@ -0,0 +1,84 @@
+package planner
+
+import (
+	"testing"
+)
+
+func TestRemapImport(t *testing.T) {
+	source := &Planner{
+		UserID:  "someone",
+		Members: []string{"u1", "u2"},
+		Columns: []PlannerColumn{
+			{ID: "s-points", Name: "Points", Type: ColumnTypeNumber},
+			{ID: "s-owner", Name: "Owner", Type: ColumnTypeUser},
+			{ID: "s-status", Name: "Status", Type: ColumnTypeText},
+		},
+		Lanes: []PlannerLane{
+			{ID: "s-l1", Title: "To Do", Position: 1, Cards: []PlannerCard{{
+				ID: "s-c1", LaneID: "s-l1", SprintID: "s1", Assignees: []string{"u1", "u2"},
+				Fields: map[string]interface{}{
+					FieldTitle: "Card",
+					"lane_id":  "s-l1",
+					"moved_at": "2024-05-01T00:00:00Z",
+					"s-points": 3.0,
+					"s-owner":  "u2",
+					"s-status": "open",
+					"missing":  "x",
+				},
+				Checklist: []ChecklistItem{{ID: "s-i1", Text: "Step", AssigneeID: "u2"}},
+			}}},
+			{ID: "s-l2", Title: "Done", Position: 2, TemplateLaneID: "s-l1"},
+		},
+	}
+	target := &Planner{
+		ID:      "p1",
+		UserID:  "owner",
+		Members: []string{"u1"},
+		Columns: []PlannerColumn{
+			{ID: "points", Name: "points", Type: ColumnTypeNumber, Position: 1},
+			{ID: "status", Name: "Status", Type: ColumnTypeStatus, Options: []string{"Open"}, Position: 2},
+		},
+		Lanes: []PlannerLane{{ID: "l1", Position: 3}},
+	}
+
+	report := &ImportReport{}
+	lanes, columns := remapImport(source, target, report)
+	if report.Lanes != 2 || report.Cards != 1 || report.Columns != 2 || report.ChecklistItems != 1 {
+		t.Errorf("unexpected report %+v", report)
+	}
+
+	// Points is reused, Owner is new and Status clashes with a column of another type
+	if len(columns) != 2 || columns[0].Name != "Owner" || columns[1].Name != "Status (imported)" || columns[1].Position != 4 {
+		t.Fatalf("unexpected columns %+v", columns)
+	}
+	if lanes[0].Position != 4 || lanes[1].Position != 5 || lanes[1].TemplateLaneID != lanes[0].ID || lanes[0].ID == "s-l1" {
+		t.Errorf("unexpected lanes %+v", lanes)
+	}
+
+	card := lanes[0].Cards[0]
+	if card.ID == "s-c1" || card.LaneID != lanes[0].ID || card.SprintID != "" {
+		t.Errorf("expected new card and lane IDs and no sprint, got %+v", card)
+	}
+	if card.Fields["lane_id"] != lanes[0].ID || card.Fields["moved_at"] != "2024-05-01T00:00:00Z" {
+		t.Errorf("expected move bookkeeping to follow the new lane, got %v", card.Fields)
+	}
+	if card.Fields["points"] != 3.0 || card.Fields[columns[1].ID] != "open" {
+		t.Errorf("expected values under the target column IDs, got %v", card.Fields)
+	}
+	if _, ok := card.Fields[columns[0].ID]; ok {
+		t.Errorf("a user value for a non-member should be dropped, got %v", card.Fields)
+	}
+	if _, ok := card.Fields["missing"]; ok {
+		t.Errorf("a value for an unknown column should be dropped, got %v", card.Fields)
+	}
+	if len(card.Assignees) != 1 || card.Assignees[0] != "u1" {
+		t.Errorf("expected only members of the target as assignees, got %v", card.Assignees)
+	}
+	if card.Checklist[0].ID == "s-i1" || card.Checklist[0].AssigneeID != "" {
+		t.Errorf("unexpected checklist item %+v", card.Checklist[0])
+	}
+	// Status clash, non-member owner, unknown column, assignee and checklist assignee
+	if len(report.Warnings) != 5 {
+		t.Errorf("expected 5 warnings, got %+v", report.Warnings)
+	}
+}
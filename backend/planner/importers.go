@@ -0,0 +1,700 @@
+package planner
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Import formats accepted by POST /planner/import?format=
+const (
+	ImportFormatTrello = "trello"
+	ImportFormatGitHub = "github"
+	ImportFormatJira   = "jira"
+)
+
+// importBuilder assembles a planner from lanes, columns and cards found in a foreign export
+type importBuilder struct {
+	planner *Planner
+	report  *ImportReport
+	lanes   map[string]int // lane key -> index in planner.Lanes
+	columns map[string]int // lower-case column name -> index in planner.Columns
+}
+
+func newImportBuilder(title, description, userID string) *importBuilder {
+	now := time.Now()
+	if strings.TrimSpace(title) == "" {
+		title = "Imported Planner"
+	}
+	return &importBuilder{
+		planner: &Planner{
+			ID:          GenerateID(),
+			UserID:      userID,
+			Title:       title,
+			Description: description,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+			Lanes:       []PlannerLane{},
+			Columns:     []PlannerColumn{},
+		},
+		report:  &ImportReport{Warnings: []ImportWarning{}},
+		lanes:   map[string]int{},
+		columns: map[string]int{},
+	}
+}
+
+// lane returns the index of the lane registered under key, creating it with the given title
+func (b *importBuilder) lane(key, title string) int {
+	if i, ok := b.lanes[key]; ok {
+		return i
+	}
+	b.planner.Lanes = append(b.planner.Lanes, newMarkdownLane(b.planner, title))
+	b.lanes[key] = len(b.planner.Lanes) - 1
+	return len(b.planner.Lanes) - 1
+}
+
+// column returns the ID of the column with the given name, creating it if needed.
+// Status options are merged into an existing status column.
+func (b *importBuilder) column(name, colType string, statusOptions ...string) string {
+	key := strings.ToLower(name)
+	if i, ok := b.columns[key]; ok {
+		col := &b.planner.Columns[i]
+		for _, o := range statusOptions {
+			if col.Type == ColumnTypeStatus && !containsString(col.Options, o) {
+				col.Options = append(col.Options, o)
+			}
+		}
+		return col.ID
+	}
+	now := time.Now()
+	col := PlannerColumn{
+		ID:        GenerateID(),
+		PlannerID: b.planner.ID,
+		Name:      name,
+		Type:      colType,
+		Position:  len(b.planner.Columns) + 1,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if colType == ColumnTypeStatus {
+		col.Options = append([]string{}, statusOptions...)
+	}
+	b.planner.Columns = append(b.planner.Columns, col)
+	b.columns[key] = len(b.planner.Columns) - 1
+	return col.ID
+}
+
+// newCard returns a card with a title and content ready to be added to a lane
+func (b *importBuilder) newCard(title, content string) PlannerCard {
+	return PlannerCard{
+		ID:        GenerateID(),
+		Fields:    map[string]interface{}{FieldTitle: title, FieldContent: content},
+		CreatedAt: b.planner.CreatedAt,
+		UpdatedAt: b.planner.CreatedAt,
+	}
+}
+
+// addCard appends a card to the lane at index laneIndex
+func (b *importBuilder) addCard(laneIndex int, card PlannerCard) {
+	lane := &b.planner.Lanes[laneIndex]
+	card.LaneID = lane.ID
+	card.Position = len(lane.Cards) + 1
+	normalizeCard(&card)
+	lane.Cards = append(lane.Cards, card)
+}
+
+// finish fills in the report totals
+func (b *importBuilder) finish() (*Planner, *ImportReport) {
+	for _, lane := range b.planner.Lanes {
+		b.report.Cards += len(lane.Cards)
+		for _, card := range lane.Cards {
+			b.report.ChecklistItems += len(card.Checklist)
+		}
+	}
+	b.report.Lanes = len(b.planner.Lanes)
+	b.report.Columns = len(b.planner.Columns)
+	return b.planner, b.report
+}
+
+// inferColumnValue picks a column type for a foreign value and returns the value converted to it
+func inferColumnValue(value interface{}) (string, interface{}, bool) {
+	switch v := value.(type) {
+	case float64:
+		return ColumnTypeNumber, v, true
+	case string:
+		if v == "" {
+			return "", nil, false
+		}
+		if d, err := normalizeDate(v); err == nil {
+			return ColumnTypeDate, d, true
+		}
+		return ColumnTypeText, v, true
+	case bool:
+		return ColumnTypeText, strconv.FormatBool(v), true
+	}
+	return "", nil, false
+}
+
+// setColumnValue stores a value in a column, creating or reusing a column of the inferred type
+func (b *importBuilder) setColumnValue(card *PlannerCard, name string, value interface{}, section string) {
+	colType, converted, ok := inferColumnValue(value)
+	if !ok {
+		if value != nil && value != "" {
+			b.report.warn(0, section, "value of %q could not be mapped to a column", name)
+		}
+		return
+	}
+	if i, exists := b.columns[strings.ToLower(name)]; exists {
+		col := b.planner.Columns[i]
+		// The column was typed from an earlier value
+		if v, ok := convertColumnValue(col, value); ok {
+			card.Fields[col.ID] = v
+		} else {
+			b.report.warn(0, section, "value %v does not fit %s column %q", value, col.Type, name)
+		}
+		return
+	}
+	card.Fields[b.column(name, colType)] = converted
+}
+
+// --- Trello ---
+
+type trelloBoard struct {
+	Name  string `json:"name"`
+	Desc  string `json:"desc"`
+	Lists []struct {
+		ID     string  `json:"id"`
+		Name   string  `json:"name"`
+		Closed bool    `json:"closed"`
+		Pos    float64 `json:"pos"`
+	} `json:"lists"`
+	Cards []struct {
+		ID          string   `json:"id"`
+		Name        string   `json:"name"`
+		Desc        string   `json:"desc"`
+		IDList      string   `json:"idList"`
+		Closed      bool     `json:"closed"`
+		Pos         float64  `json:"pos"`
+		Due         *string  `json:"due"`
+		Start       *string  `json:"start"`
+		IDLabels    []string `json:"idLabels"`
+		IDMembers   []string `json:"idMembers"`
+		Attachments []struct {
+			Name string `json:"name"`
+		} `json:"attachments"`
+		CustomFieldItems []json.RawMessage `json:"customFieldItems"`
+		DateLastActivity *time.Time        `json:"dateLastActivity"`
+	} `json:"cards"`
+	Labels []struct {
+		ID    string `json:"id"`
+		Name  string `json:"name"`
+		Color string `json:"color"`
+	} `json:"labels"`
+	Members []struct {
+		ID       string `json:"id"`
+		FullName string `json:"fullName"`
+		Username string `json:"username"`
+	} `json:"members"`
+	Checklists []struct {
+		ID         string `json:"id"`
+		IDCard     string `json:"idCard"`
+		Name       string `json:"name"`
+		CheckItems []struct {
+			Name  string  `json:"name"`
+			State string  `json:"state"`
+			Pos   float64 `json:"pos"`
+		} `json:"checkItems"`
+	} `json:"checklists"`
+}
+
+// ParseTrelloBoard converts a Trello board JSON export into a planner owned by userID.
+// Lists become lanes, cards keep their description, dates and checklists, labels become card
+// labels and members are listed in an "Assignees" text column. Archived items are skipped.
+func ParseTrelloBoard(data []byte, userID string) (*Planner, *ImportReport, error) {
+	var board trelloBoard
+	if err := json.Unmarshal(data, &board); err != nil {
+		return nil, nil, fmt.Errorf("%w: not a Trello board export: %v", ErrInvalidImport, err)
+	}
+	if board.Lists == nil && board.Cards == nil {
+		return nil, nil, fmt.Errorf("%w: not a Trello board export: no lists or cards", ErrInvalidImport)
+	}
+	b := newImportBuilder(board.Name, board.Desc, userID)
+
+	sort.SliceStable(board.Lists, func(i, j int) bool { return board.Lists[i].Pos < board.Lists[j].Pos })
+	for _, list := range board.Lists {
+		if list.Closed {
+			b.report.warn(0, list.Name, "archived list skipped")
+			continue
+		}
+		b.lane(list.ID, list.Name)
+	}
+
+	labels := map[string]string{}
+	for _, l := range board.Labels {
+		name := l.Name
+		if name == "" {
+			name = l.Color
+		}
+		labels[l.ID] = name
+	}
+	members := map[string]string{}
+	for _, m := range board.Members {
+		name := m.FullName
+		if name == "" {
+			name = m.Username
+		}
+		members[m.ID] = name
+	}
+	checklists := map[string][]ChecklistItem{}
+	for _, cl := range board.Checklists {
+		sort.SliceStable(cl.CheckItems, func(i, j int) bool { return cl.CheckItems[i].Pos < cl.CheckItems[j].Pos })
+		for _, item := range cl.CheckItems {
+			text := item.Name
+			if cl.Name != "" && cl.Name != "Checklist" {
+				text = cl.Name + ": " + item.Name
+			}
+			checklists[cl.IDCard] = append(checklists[cl.IDCard], ChecklistItem{
+				ID:        GenerateID(),
+				Text:      text,
+				Done:      item.State == "complete",
+				Position:  len(checklists[cl.IDCard]) + 1,
+				CreatedAt: b.planner.CreatedAt,
+				UpdatedAt: b.planner.CreatedAt,
+			})
+		}
+	}
+
+	sort.SliceStable(board.Cards, func(i, j int) bool { return board.Cards[i].Pos < board.Cards[j].Pos })
+	for _, tc := range board.Cards {
+		if tc.Closed {
+			b.report.warn(0, tc.Name, "archived card skipped")
+			continue
+		}
+		laneIndex, ok := b.lanes[tc.IDList]
+		if !ok {
+			b.report.warn(0, tc.Name, "card belongs to an unknown or archived list and was skipped")
+			continue
+		}
+		card := b.newCard(tc.Name, tc.Desc)
+		if tc.DateLastActivity != nil {
+			card.UpdatedAt = *tc.DateLastActivity
+		}
+		for name, value := range map[string]*string{"due": tc.Due, "start": tc.Start} {
+			if value == nil || *value == "" {
+				continue
+			}
+			t, err := time.Parse(time.RFC3339, *value)
+			if err != nil {
+				b.report.warn(0, tc.Name, "invalid %s date %q ignored", name, *value)
+				continue
+			}
+			t = t.UTC()
+			if name == "due" {
+				card.DueDate = &t
+			} else {
+				card.StartDate = &t
+			}
+		}
+		for _, id := range tc.IDLabels {
+			if name, ok := labels[id]; ok && name != "" {
+				card.Labels = append(card.Labels, name)
+			}
+		}
+		card.Labels = normalizeLabels(card.Labels)
+		if len(card.Labels) == 0 {
+			card.Labels = nil
+		}
+		assignees := []string{}
+		for _, id := range tc.IDMembers {
+			if name, ok := members[id]; ok {
+				assignees = append(assignees, name)
+			} else {
+				b.report.warn(0, tc.Name, "unknown member %q skipped", id)
+			}
+		}
+		if len(assignees) > 0 {
+			b.setColumnValue(&card, "Assignees", strings.Join(assignees, ", "), tc.Name)
+		}
+		card.Checklist = checklists[tc.ID]
+		if len(tc.Attachments) > 0 {
+			b.report.warn(0, tc.Name, "%d attachment(s) not imported", len(tc.Attachments))
+		}
+		if len(tc.CustomFieldItems) > 0 {
+			b.report.warn(0, tc.Name, "custom field values not imported")
+		}
+		b.addCard(laneIndex, card)
+	}
+
+	planner, report := b.finish()
+	return planner, report, nil
+}
+
+// --- GitHub Projects ---
+
+// gitHubProjectItemKeys are item keys handled explicitly rather than as custom fields
+var gitHubProjectItemKeys = []string{"id", "title", "content", "status", "assignees", "labels", "repository"}
+
+// ParseGitHubProject converts a GitHub Projects (v2) JSON dump into a planner owned by userID.
+// The dump is the output of `gh project item-list --format json`, optionally wrapped with
+// "title" and the `gh project field-list` "fields". Statuses become lanes, labels become card
+// labels, assignees an "Assignees" text column and other project fields become columns.
+func ParseGitHubProject(data []byte, userID string) (*Planner, *ImportReport, error) {
+	var dump struct {
+		Title  string `json:"title"`
+		Fields []struct {
+			Name    string `json:"name"`
+			Type    string `json:"type"`
+			Options []struct {
+				Name string `json:"name"`
+			} `json:"options"`
+		} `json:"fields"`
+		Items []map[string]interface{} `json:"items"`
+	}
+	if err := json.Unmarshal(data, &dump); err != nil {
+		return nil, nil, fmt.Errorf("%w: not a GitHub Projects export: %v", ErrInvalidImport, err)
+	}
+	if dump.Items == nil {
+		return nil, nil, fmt.Errorf("%w: not a GitHub Projects export: no items", ErrInvalidImport)
+	}
+	b := newImportBuilder(dump.Title, "", userID)
+
+	// Field definitions give the lane order and types of single select fields
+	singleSelect := map[string]bool{}
+	for _, f := range dump.Fields {
+		if f.Type != "ProjectV2SingleSelectField" {
+			continue
+		}
+		options := []string{}
+		for _, o := range f.Options {
+			options = append(options, o.Name)
+		}
+		if strings.EqualFold(f.Name, "Status") {
+			for _, o := range options {
+				b.lane(o, o)
+			}
+			continue
+		}
+		singleSelect[strings.ToLower(f.Name)] = true
+		if err := validateColumnDefinition(f.Name, ColumnTypeStatus, options); err == nil {
+			b.column(f.Name, ColumnTypeStatus, options...)
+		}
+	}
+
+	for _, item := range dump.Items {
+		title, _ := item["title"].(string)
+		body := ""
+		if content, ok := item["content"].(map[string]interface{}); ok {
+			body, _ = content["body"].(string)
+			if title == "" {
+				title, _ = content["title"].(string)
+			}
+		}
+		if title == "" {
+			b.report.warn(0, "", "item %v has no title and was skipped", item["id"])
+			continue
+		}
+		card := b.newCard(title, body)
+
+		status, _ := item["status"].(string)
+		if status == "" {
+			status = "No Status"
+		}
+		laneIndex := b.lane(status, status)
+
+		card.Labels = normalizeLabels(toStringList(item["labels"]))
+		if len(card.Labels) == 0 {
+			card.Labels = nil
+		}
+		if assignees := toStringList(item["assignees"]); len(assignees) > 0 {
+			b.setColumnValue(&card, "Assignees", strings.Join(assignees, ", "), title)
+		}
+		if content, ok := item["content"].(map[string]interface{}); ok {
+			if url, ok := content["url"].(string); ok && url != "" {
+				b.setColumnValue(&card, "URL", url, title)
+			}
+		}
+		if repo, ok := item["repository"].(string); ok && repo != "" {
+			b.setColumnValue(&card, "Repository", repo, title)
+		}
+
+		keys := make([]string, 0, len(item))
+		for key := range item {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if containsString(gitHubProjectItemKeys, key) {
+				continue
+			}
+			name := fieldDisplayName(key)
+			value := item[key]
+			if s, ok := value.(string); ok && singleSelect[strings.ToLower(name)] {
+				card.Fields[b.column(name, ColumnTypeStatus, s)] = s
+				continue
+			}
+			if m, ok := value.(map[string]interface{}); ok {
+				// Iterations and milestones are objects; keep their title
+				if t, ok := m["title"].(string); ok {
+					b.setColumnValue(&card, name, t, title)
+					continue
+				}
+				b.report.warn(0, title, "field %q could not be mapped to a column", key)
+				continue
+			}
+			b.setColumnValue(&card, name, value, title)
+		}
+		b.addCard(laneIndex, card)
+	}
+
+	planner, report := b.finish()
+	return planner, report, nil
+}
+
+// toStringList converts a JSON array of strings to a string slice
+func toStringList(value interface{}) []string {
+	items, _ := value.([]interface{})
+	result := []string{}
+	for _, item := range items {
+		if s, ok := item.(string); ok && s != "" {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+// fieldDisplayName turns a lower-case export key like "story points" into "Story points"
+func fieldDisplayName(key string) string {
+	if key == "" {
+		return key
+	}
+	r, size := utf8.DecodeRuneInString(key)
+	return string(unicode.ToUpper(r)) + key[size:]
+}
+
+// --- Jira ---
+
+// jiraDateLayouts are the date formats found in Jira CSV exports
+var jiraDateLayouts = []string{"02/Jan/06 3:04 PM", "2006-01-02 15:04", "2006-01-02", time.RFC3339}
+
+// jiraIgnoredColumns are Jira CSV columns that carry no board data
+var jiraIgnoredColumns = []string{"issue id", "parent id", "project key", "project type", "project lead", "project description", "project url", "watchers", "last viewed", "votes"}
+
+// ParseJiraCSV converts a Jira CSV export into a planner owned by userID. Statuses become lanes,
+// Summary and Description the card title and content, Labels card labels and Priority, Due Date
+// and Created/Updated the matching card attributes. Issue key, Issue Type, Assignee and
+// "Custom field (...)" columns become planner columns; any other column is reported.
+func ParseJiraCSV(data []byte, userID string) (*Planner, *ImportReport, error) {
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))))
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: not a Jira CSV export: %v", ErrInvalidImport, err)
+	}
+	index := map[string][]int{}
+	for i, h := range header {
+		key := strings.ToLower(strings.TrimSpace(h))
+		index[key] = append(index[key], i)
+	}
+	if index["summary"] == nil || index["status"] == nil {
+		return nil, nil, fmt.Errorf("%w: not a Jira CSV export: Summary and Status columns are required", ErrInvalidImport)
+	}
+
+	b := newImportBuilder("Jira import", "", userID)
+	known := []string{"summary", "status", "description", "labels", "priority", "due date", "created", "updated", "issue key", "issue type", "assignee"}
+	customFields := []string{} // lower-case header keys of "Custom field (...)" columns
+	for _, h := range header {
+		key := strings.ToLower(strings.TrimSpace(h))
+		switch {
+		case strings.HasPrefix(key, "custom field ("):
+			if !containsString(customFields, key) {
+				customFields = append(customFields, key)
+			}
+		case containsString(known, key) || containsString(jiraIgnoredColumns, key):
+		case index[key][0] == indexOf(header, h):
+			// Repeated headers are reported once
+			b.report.warn(1, strings.TrimSpace(h), "column not imported")
+		}
+	}
+
+	cell := func(record []string, name string) string {
+		for _, i := range index[name] {
+			if i < len(record) && strings.TrimSpace(record[i]) != "" {
+				return strings.TrimSpace(record[i])
+			}
+		}
+		return ""
+	}
+	parseJiraDate := func(s string) (time.Time, bool) {
+		for _, layout := range jiraDateLayouts {
+			if t, err := time.Parse(layout, s); err == nil {
+				return t.UTC(), true
+			}
+		}
+		return time.Time{}, false
+	}
+
+	line := 1
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		line++
+		if err != nil {
+			b.report.warn(line, "", "unreadable row skipped: %v", err)
+			continue
+		}
+		title := cell(record, "summary")
+		if title == "" {
+			b.report.warn(line, "", "row without Summary skipped")
+			continue
+		}
+		card := b.newCard(title, cell(record, "description"))
+
+		status := cell(record, "status")
+		if status == "" {
+			status = "No Status"
+		}
+		laneIndex := b.lane(strings.ToLower(status), status)
+
+		labels := []string{}
+		for _, i := range index["labels"] {
+			if i < len(record) {
+				labels = append(labels, strings.Fields(record[i])...)
+			}
+		}
+		if card.Labels = normalizeLabels(labels); len(card.Labels) == 0 {
+			card.Labels = nil
+		}
+		if p := cell(record, "priority"); p != "" {
+			switch strings.ToLower(p) {
+			case "highest", "blocker", "critical":
+				card.Priority = PriorityUrgent
+			case "high", "major":
+				card.Priority = PriorityHigh
+			case "medium":
+				card.Priority = PriorityMedium
+			case "low", "lowest", "minor", "trivial":
+				card.Priority = PriorityLow
+			default:
+				b.report.warn(line, title, "priority %q could not be mapped", p)
+			}
+		}
+		if s := cell(record, "due date"); s != "" {
+			if t, ok := parseJiraDate(s); ok {
+				card.DueDate = &t
+			} else {
+				b.report.warn(line, title, "invalid due date %q ignored", s)
+			}
+		}
+		if t, ok := parseJiraDate(cell(record, "created")); ok {
+			card.CreatedAt = t
+		}
+		if t, ok := parseJiraDate(cell(record, "updated")); ok {
+			card.UpdatedAt = t
+		}
+		for _, name := range []string{"Issue key", "Issue Type", "Assignee"} {
+			if v := cell(record, strings.ToLower(name)); v != "" {
+				card.Fields[b.column(name, ColumnTypeText)] = v
+			}
+		}
+		for _, key := range customFields {
+			value := cell(record, key)
+			if value == "" {
+				continue
+			}
+			name := strings.TrimSpace(header[index[key][0]])
+			name = strings.TrimSuffix(name[len("custom field ("):], ")")
+			if n, err := strconv.ParseFloat(value, 64); err == nil {
+				b.setColumnValue(&card, name, n, title)
+			} else {
+				b.setColumnValue(&card, name, value, title)
+			}
+		}
+		b.addCard(laneIndex, card)
+	}
+
+	planner, report := b.finish()
+	return planner, report, nil
+}
+
+// indexOf returns the index of the first occurrence of s in values
+func indexOf(values []string, s string) int {
+	for i, v := range values {
+		if v == s {
+			return i
+		}
+	}
+	return -1
+}
+
+// ImportPlannerFromFormat creates a planner owned by userID from a Trello, GitHub Projects or Jira export
+func ImportPlannerFromFormat(ctx context.Context, format string, data []byte, userID string) (*Planner, *ImportReport, error) {
+	log.Printf("Importing planner: format=%s, userID=%s, size=%d", format, userID, len(data))
+
+	var planner *Planner
+	var report *ImportReport
+	var err error
+	switch format {
+	case ImportFormatTrello:
+		planner, report, err = ParseTrelloBoard(data, userID)
+	case ImportFormatGitHub:
+		planner, report, err = ParseGitHubProject(data, userID)
+	case ImportFormatJira:
+		planner, report, err = ParseJiraCSV(data, userID)
+	default:
+		return nil, nil, fmt.Errorf("%w: unsupported format %q", ErrInvalidImport, format)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if _, err := plannerCollection.InsertOne(ctx, planner); err != nil {
+		return nil, nil, fmt.Errorf("failed to insert planner: %w", err)
+	}
+	recordImportedTransitions(ctx, planner.ID, planner.Lanes)
+	return planner, report, nil
+}
+
+// HandleImportPlannerFromFormat handles POST /planner/import?format={trello|github|jira}.
+// The request body is the raw export file.
+func HandleImportPlannerFromFormat(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, 50<<20))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	userID, _ := r.Context().Value("user_id").(string)
+
+	planner, report, err := ImportPlannerFromFormat(r.Context(), r.URL.Query().Get("format"), data, userID)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, ErrInvalidImport) {
+			status = http.StatusBadRequest
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(importResponse{planner, report}); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,268 @@
+package planner
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func readFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("failed to read fixture %s: %v", name, err)
+	}
+	return data
+}
+
+func laneTitles(p *Planner) []string {
+	titles := []string{}
+	for _, lane := range p.Lanes {
+		titles = append(titles, lane.Title)
+	}
+	return titles
+}
+
+func findImportedCard(t *testing.T, p *Planner, title string) (*PlannerLane, *PlannerCard) {
+	t.Helper()
+	for i := range p.Lanes {
+		for j := range p.Lanes[i].Cards {
+			if p.Lanes[i].Cards[j].Fields[FieldTitle] == title {
+				return &p.Lanes[i], &p.Lanes[i].Cards[j]
+			}
+		}
+	}
+	t.Fatalf("card %q not imported", title)
+	return nil, nil
+}
+
+func findImportedColumn(t *testing.T, p *Planner, name string) PlannerColumn {
+	t.Helper()
+	for _, col := range p.Columns {
+		if col.Name == name {
+			return col
+		}
+	}
+	t.Fatalf("column %q not imported, have %+v", name, p.Columns)
+	return PlannerColumn{}
+}
+
+func hasWarning(report *ImportReport, section, substr string) bool {
+	for _, w := range report.Warnings {
+		if w.Section == section && strings.Contains(w.Message, substr) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestParseTrelloBoard_Fixture(t *testing.T) {
+	p, report, err := ParseTrelloBoard(readFixture(t, "trello_board.json"), "user-1")
+	if err != nil {
+		t.Fatalf("ParseTrelloBoard failed: %v", err)
+	}
+
+	if p.Title != "Website Relaunch" || p.Description != "Marketing site rebuild" || p.UserID != "user-1" {
+		t.Errorf("unexpected planner header: %q %q %q", p.Title, p.Description, p.UserID)
+	}
+	if got, want := laneTitles(p), []string{"To Do", "Doing", "Done"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("lanes = %v, want %v", got, want)
+	}
+	if report.Lanes != 3 || report.Cards != 3 || report.ChecklistItems != 3 {
+		t.Errorf("unexpected report counts: %+v", report)
+	}
+
+	lane, card := findImportedCard(t, p, "Design hero section")
+	if lane.Title != "Doing" || card.LaneID != lane.ID {
+		t.Errorf("card in lane %q (%s), want Doing (%s)", lane.Title, card.LaneID, lane.ID)
+	}
+	if card.Fields[FieldContent] != "Use the **new** brand colors." {
+		t.Errorf("content = %q", card.Fields[FieldContent])
+	}
+	if !reflect.DeepEqual(card.Labels, []string{"design", "green"}) {
+		t.Errorf("labels = %v", card.Labels)
+	}
+	if card.DueDate == nil || !card.DueDate.Equal(time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)) {
+		t.Errorf("due date = %v", card.DueDate)
+	}
+	if card.StartDate == nil || !card.StartDate.Equal(time.Date(2024, 4, 20, 9, 0, 0, 0, time.UTC)) {
+		t.Errorf("start date = %v", card.StartDate)
+	}
+	assignees := findImportedColumn(t, p, "Assignees")
+	if assignees.Type != ColumnTypeText || card.Fields[assignees.ID] != "Ana Silva, raj" {
+		t.Errorf("assignees column %+v value %v", assignees, card.Fields[assignees.ID])
+	}
+	if len(card.Checklist) != 3 || card.Checklist[0].Text != "Sketch layout" || !card.Checklist[0].Done || card.Checklist[2].Done {
+		t.Errorf("checklist = %+v", card.Checklist)
+	}
+	if card.Progress != 66 {
+		t.Errorf("progress = %d, want 66", card.Progress)
+	}
+
+	for _, w := range []struct{ section, message string }{
+		{"Old ideas", "archived list"},
+		{"Old banner", "archived card"},
+		{"Parked idea", "unknown or archived list"},
+		{"Design hero section", "member-gone"},
+		{"Design hero section", "attachment"},
+		{"Write copy", "custom field"},
+	} {
+		if !hasWarning(report, w.section, w.message) {
+			t.Errorf("missing warning %q for %q in %+v", w.message, w.section, report.Warnings)
+		}
+	}
+}
+
+func TestParseGitHubProject_Fixture(t *testing.T) {
+	p, report, err := ParseGitHubProject(readFixture(t, "github_project.json"), "user-1")
+	if err != nil {
+		t.Fatalf("ParseGitHubProject failed: %v", err)
+	}
+
+	if p.Title != "Roadmap" {
+		t.Errorf("title = %q", p.Title)
+	}
+	if got, want := laneTitles(p), []string{"Todo", "In Progress", "Done", "No Status"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("lanes = %v, want %v", got, want)
+	}
+	if report.Cards != 3 {
+		t.Errorf("cards = %d, want 3", report.Cards)
+	}
+
+	lane, card := findImportedCard(t, p, "Add dark mode")
+	if lane.Title != "In Progress" || card.Fields[FieldContent] != "Follow the OS setting." {
+		t.Errorf("card in %q with content %q", lane.Title, card.Fields[FieldContent])
+	}
+	if !reflect.DeepEqual(card.Labels, []string{"enhancement", "ui"}) {
+		t.Errorf("labels = %v", card.Labels)
+	}
+	size := findImportedColumn(t, p, "Size")
+	if size.Type != ColumnTypeStatus || !reflect.DeepEqual(size.Options, []string{"S", "M", "L"}) || card.Fields[size.ID] != "M" {
+		t.Errorf("size column %+v value %v", size, card.Fields[size.ID])
+	}
+	estimate := findImportedColumn(t, p, "Estimate")
+	if estimate.Type != ColumnTypeNumber || card.Fields[estimate.ID] != float64(5) {
+		t.Errorf("estimate column %+v value %v", estimate, card.Fields[estimate.ID])
+	}
+	target := findImportedColumn(t, p, "Target date")
+	if target.Type != ColumnTypeDate || card.Fields[target.ID] != "2024-06-30" {
+		t.Errorf("target date column %+v value %v", target, card.Fields[target.ID])
+	}
+	if iteration := findImportedColumn(t, p, "Iteration"); card.Fields[iteration.ID] != "Sprint 3" {
+		t.Errorf("iteration = %v", card.Fields[iteration.ID])
+	}
+	if assignees := findImportedColumn(t, p, "Assignees"); card.Fields[assignees.ID] != "octocat, hubot" {
+		t.Errorf("assignees = %v", card.Fields[assignees.ID])
+	}
+	if url := findImportedColumn(t, p, "URL"); card.Fields[url.ID] != "https://github.com/acme/app/issues/12" {
+		t.Errorf("url = %v", card.Fields[url.ID])
+	}
+
+	if lane, _ := findImportedCard(t, p, "Triage backlog"); lane.Title != "No Status" {
+		t.Errorf("item without status in lane %q", lane.Title)
+	}
+	if !hasWarning(report, "Release notes", "Linked pull requests") {
+		t.Errorf("missing warning for linked pull requests: %+v", report.Warnings)
+	}
+	if !hasWarning(report, "", "no title") {
+		t.Errorf("missing warning for untitled item: %+v", report.Warnings)
+	}
+}
+
+func TestParseJiraCSV_Fixture(t *testing.T) {
+	p, report, err := ParseJiraCSV(readFixture(t, "jira_issues.csv"), "user-1")
+	if err != nil {
+		t.Fatalf("ParseJiraCSV failed: %v", err)
+	}
+
+	if got, want := laneTitles(p), []string{"In Progress", "To Do", "Done"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("lanes = %v, want %v", got, want)
+	}
+	if report.Cards != 4 {
+		t.Errorf("cards = %d, want 4", report.Cards)
+	}
+
+	_, card := findImportedCard(t, p, "Login fails on Safari")
+	if card.Fields[FieldContent] != "Steps:\n1. Open login\n2. Submit" {
+		t.Errorf("content = %q", card.Fields[FieldContent])
+	}
+	if card.Priority != PriorityUrgent {
+		t.Errorf("priority = %q", card.Priority)
+	}
+	if !reflect.DeepEqual(card.Labels, []string{"frontend", "safari"}) {
+		t.Errorf("labels = %v", card.Labels)
+	}
+	if card.DueDate == nil || !card.DueDate.Equal(time.Date(2024, 3, 20, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("due date = %v", card.DueDate)
+	}
+	if !card.CreatedAt.Equal(time.Date(2024, 3, 12, 9, 15, 0, 0, time.UTC)) {
+		t.Errorf("created at = %v", card.CreatedAt)
+	}
+	for name, want := range map[string]interface{}{
+		"Issue key":    "WEB-1",
+		"Issue Type":   "Bug",
+		"Assignee":     "Ana Silva",
+		"Story Points": float64(3),
+		"Team":         "Web",
+	} {
+		if col := findImportedColumn(t, p, name); card.Fields[col.ID] != want {
+			t.Errorf("%s = %v, want %v", name, card.Fields[col.ID], want)
+		}
+	}
+
+	// Cell contents are kept verbatim; escaping happens on export
+	_, sso := findImportedCard(t, p, "Add SSO")
+	if sso.Fields[FieldContent] != `=HYPERLINK("http://x")` || sso.Priority != PriorityMedium {
+		t.Errorf("unexpected SSO card: %+v", sso)
+	}
+
+	for _, w := range []struct{ section, message string }{
+		{"Reporter", "not imported"},
+		{"Sprint", "not imported"},
+		{"", "without Summary"},
+		{"Flaky test", "priority"},
+		{"Flaky test", "due date"},
+		{"Flaky test", "Story Points"},
+	} {
+		if !hasWarning(report, w.section, w.message) {
+			t.Errorf("missing warning %q for %q in %+v", w.message, w.section, report.Warnings)
+		}
+	}
+}
+
+func TestImporters_RejectForeignFormats(t *testing.T) {
+	parsers := map[string]func([]byte, string) (*Planner, *ImportReport, error){
+		ImportFormatTrello: ParseTrelloBoard,
+		ImportFormatGitHub: ParseGitHubProject,
+		ImportFormatJira:   ParseJiraCSV,
+	}
+	inputs := map[string][]byte{
+		ImportFormatTrello: readFixture(t, "jira_issues.csv"),
+		ImportFormatGitHub: readFixture(t, "trello_board.json"),
+		ImportFormatJira:   readFixture(t, "github_project.json"),
+	}
+	for format, parse := range parsers {
+		if _, _, err := parse(inputs[format], "user-1"); !errors.Is(err, ErrInvalidImport) {
+			t.Errorf("%s: expected ErrInvalidImport, got %v", format, err)
+		}
+	}
+}
+
+func TestFieldDisplayName(t *testing.T) {
+	cases := map[string]string{
+		"":         "",
+		"estimate": "Estimate",
+		"équipe":   "Équipe",
+		"über":     "Über",
+		"日付":       "日付",
+	}
+	for key, want := range cases {
+		if got := fieldDisplayName(key); got != want {
+			t.Errorf("%q: expected %q, got %q", key, want, got)
+		}
+	}
+}
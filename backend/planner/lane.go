@@ -3,20 +3,89 @@ package planner
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
 	"sort"
 	"strings"
 	"time"
+	"unicode"
 
 	"go.mongodb.org/mongo-driver/bson"
 )
 
+// Lane categories give lanes a meaning independent of their title
+const (
+	LaneCategoryBacklog    = "backlog"
+	LaneCategoryTodo       = "todo"
+	LaneCategoryInProgress = "in-progress"
+	LaneCategoryDone       = "done"
+)
+
+func isValidLaneCategory(c string) bool {
+	switch c {
+	case "", LaneCategoryBacklog, LaneCategoryTodo, LaneCategoryInProgress, LaneCategoryDone:
+		return true
+	}
+	return false
+}
+
+// Title words used to guess the category of lanes created without one
+var laneCategoryKeywords = []struct {
+	category string
+	words    []string
+}{
+	{LaneCategoryDone, []string{"done", "complete", "completed", "finished", "closed", "shipped", "released", "resolved"}},
+	{LaneCategoryInProgress, []string{"progress", "doing", "review", "reviewing", "testing", "qa", "active", "working"}},
+	{LaneCategoryBacklog, []string{"backlog", "later", "icebox", "someday", "ideas"}},
+}
+
+// inferLaneCategory guesses a lane's category from the words of its title, defaulting to todo.
+// Whole words are matched, so "Undone" or "Quality" don't count as "done" or "qa".
+func inferLaneCategory(title string) string {
+	words := strings.FieldsFunc(strings.ToLower(title), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, k := range laneCategoryKeywords {
+		for _, w := range k.words {
+			if containsString(words, w) {
+				return k.category
+			}
+		}
+	}
+	return LaneCategoryTodo
+}
+
+// laneCategory returns the category of a lane, guessed from the title for lanes that don't have one
+func laneCategory(lane *PlannerLane) string {
+	if lane.Category != "" {
+		return lane.Category
+	}
+	return inferLaneCategory(lane.Title)
+}
+
+// findLane returns the lane of a planner with the given ID, or nil
+func findLane(planner *Planner, laneID string) *PlannerLane {
+	for i := range planner.Lanes {
+		if planner.Lanes[i].ID == laneID {
+			return &planner.Lanes[i]
+		}
+	}
+	return nil
+}
+
 // AddLane adds a new lane to a planner document in MongoDB with position-aware insertion
-func AddLane(ctx context.Context, plannerID, title, description, color string, position int) (*PlannerLane, error) {
+func AddLane(ctx context.Context, plannerID, title, description, color, category string, position int) (*PlannerLane, error) {
 	log.Printf("Adding lane: plannerID=%s, title=%s, position=%d", plannerID, title, position)
 
+	if !isValidLaneCategory(category) {
+		return nil, fmt.Errorf("%w: category must be backlog, todo, in-progress or done", ErrInvalidSettings)
+	}
+	if category == "" {
+		category = inferLaneCategory(title)
+	}
+
 	// First, get the current planner to determine proper positioning
 	var planner Planner
 	err := plannerCollection.FindOne(ctx, bson.M{"id": plannerID}).Decode(&planner)
@@ -31,6 +100,7 @@ func AddLane(ctx context.Context, plannerID, title, description, color string, p
 		Title:       title,
 		Description: description,
 		Color:       color,
+		Category:    category,
 		Position:    position,
 		CreatedAt:   time.Now(),
 		UpdatedAt:   time.Now(),
@@ -67,22 +137,46 @@ func AddLane(ctx context.Context, plannerID, title, description, color string, p
 	}
 
 	log.Printf("AddLane: Successfully added lane %s to planner %s", laneID, plannerID)
+	recordActivity(ctx, plannerID, EventLaneAdded, "lane", laneID, nil, &ActivitySnapshot{Lane: &lane})
+	publishEvent(ctx, plannerID, EventLaneAdded, lane)
 	return &lane, nil
 }
 
-// UpdateLane updates a lane's title and description in MongoDB
-func UpdateLane(ctx context.Context, laneID, title, description, color string) (*PlannerLane, error) {
+// UpdateLane updates a lane's title, description and color in MongoDB.
+// A nil wipLimit leaves the lane's WIP limit unchanged.
+func UpdateLane(ctx context.Context, laneID, title, description, color string, wipLimit *int, category *string) (*PlannerLane, error) {
 	log.Printf("Updating lane: id=%s, title=%s", laneID, title)
 
+	before, _, err := getLane(ctx, laneID)
+	if err != nil {
+		return nil, err
+	}
+	limit := before.WIPLimit
+	if wipLimit != nil {
+		if *wipLimit < 0 {
+			return nil, fmt.Errorf("%w: wip_limit must not be negative", ErrInvalidSettings)
+		}
+		limit = *wipLimit
+	}
+	laneCategory := before.Category
+	if category != nil {
+		if !isValidLaneCategory(*category) {
+			return nil, fmt.Errorf("%w: category must be backlog, todo, in-progress or done", ErrInvalidSettings)
+		}
+		laneCategory = *category
+	}
+
 	filter := bson.M{"lanes.id": laneID}
 	update := bson.M{"$set": bson.M{
 		"lanes.$.title":       title,
 		"lanes.$.description": description,
 		"lanes.$.color":       color,
+		"lanes.$.wip_limit":   limit,
+		"lanes.$.category":    laneCategory,
 		"lanes.$.updated_at":  time.Now(),
 	}}
 
-	_, err := plannerCollection.UpdateOne(ctx, filter, update)
+	_, err = plannerCollection.UpdateOne(ctx, filter, update)
 	if err != nil {
 		return nil, err
 	}
@@ -93,9 +187,20 @@ func UpdateLane(ctx context.Context, laneID, title, description, color string) (
 		Title:       title,
 		Description: description,
 		Color:       color,
+		WIPLimit:    limit,
+		Category:    laneCategory,
 		UpdatedAt:   time.Now(),
 		Cards:       []PlannerCard{},
 	}
+	if plannerID, err := findPlannerIDByLane(ctx, laneID); err == nil {
+		updatedLane.PlannerID = plannerID
+		after := *before
+		after.Title, after.Description, after.Color, after.WIPLimit = title, description, color, limit
+		after.Category = laneCategory
+		recordActivity(ctx, plannerID, EventLaneUpdated, "lane", laneID,
+			&ActivitySnapshot{Lane: before}, &ActivitySnapshot{Lane: &after})
+		publishEvent(ctx, plannerID, EventLaneUpdated, updatedLane)
+	}
 	return updatedLane, nil
 }
 
@@ -103,19 +208,50 @@ func UpdateLane(ctx context.Context, laneID, title, description, color string) (
 func DeleteLane(ctx context.Context, laneID string) error {
 	log.Printf("Deleting lane: id=%s", laneID)
 
+	before, plannerID, err := getLane(ctx, laneID)
+	if err != nil {
+		return err
+	}
+
 	// Pull lane from array
-	_, err := plannerCollection.UpdateOne(
+	_, err = plannerCollection.UpdateOne(
 		ctx,
 		bson.M{"lanes.id": laneID},
 		bson.M{"$pull": bson.M{"lanes": bson.M{"id": laneID}}},
 	)
-	return err
+	if err != nil {
+		return err
+	}
+	cardIDs := []string{}
+	for _, card := range before.Cards {
+		cardIDs = append(cardIDs, card.ID)
+	}
+	links, err := deleteCardLinks(ctx, cardIDs...)
+	if err != nil {
+		return err
+	}
+	comments, err := deleteCardComments(ctx, cardIDs...)
+	if err != nil {
+		return err
+	}
+	recordActivity(ctx, plannerID, EventLaneDeleted, "lane", laneID,
+		&ActivitySnapshot{Lane: before, Links: links, Comments: comments}, nil)
+	for _, card := range before.Cards {
+		recordTransition(ctx, plannerID, card.ID, before, nil)
+	}
+	publishEvent(ctx, plannerID, EventLaneDeleted, bson.M{"id": laneID})
+	return nil
 }
 
 // ReorderLanes updates the positions of lanes in a planner
 func ReorderLanes(ctx context.Context, plannerID string, laneIDs []string) error {
 	log.Printf("Reordering lanes: plannerID=%s, laneCount=%d", plannerID, len(laneIDs))
 
+	var before Planner
+	if err := plannerCollection.FindOne(ctx, bson.M{"id": plannerID}).Decode(&before); err != nil {
+		return err
+	}
+
 	for i, laneID := range laneIDs {
 		filter := bson.M{"id": plannerID, "lanes.id": laneID}
 		update := bson.M{"$set": bson.M{"lanes.$.position": i + 1, "lanes.$.updated_at": time.Now()}}
@@ -125,6 +261,12 @@ func ReorderLanes(ctx context.Context, plannerID string, laneIDs []string) error
 		}
 	}
 
+	var after Planner
+	if err := plannerCollection.FindOne(ctx, bson.M{"id": plannerID}).Decode(&after); err == nil {
+		recordActivity(ctx, plannerID, EventLanesReordered, "planner", plannerID,
+			&ActivitySnapshot{Lanes: lanePositions(&before)}, &ActivitySnapshot{Lanes: lanePositions(&after)})
+	}
+	publishEvent(ctx, plannerID, EventLanesReordered, bson.M{"lane_ids": laneIDs})
 	return nil
 }
 
@@ -174,6 +316,7 @@ func SplitLane(ctx context.Context, laneID, newTitle, newDescription, newColor s
 		Title:          newTitle,
 		Description:    newDescription,
 		Color:          newColor,
+		Category:       originalLane.Category,
 		Position:       originalLane.Position + 1,
 		CreatedAt:      time.Now(),
 		UpdatedAt:      time.Now(),
@@ -234,7 +377,12 @@ func SplitLane(ctx context.Context, laneID, newTitle, newDescription, newColor s
 		bson.M{"id": planner.ID},
 		bson.M{"$push": bson.M{"lanes": newLane}},
 	)
+	if err != nil {
+		return nil, err
+	}
 
+	recordLaneLayoutActivity(ctx, &planner, EventLaneSplit, laneID)
+	publishEvent(ctx, planner.ID, EventLaneSplit, bson.M{"lane_id": laneID, "new_lane": newLane})
 	return &newLane, nil
 }
 
@@ -261,6 +409,9 @@ func UnsplitLane(ctx context.Context, laneID, targetLaneID string) error {
 	if source == nil || target == nil {
 		return fmt.Errorf("source or target lane not found")
 	}
+	if err := checkWIPLimit(&planner, targetLaneID, source.Cards); err != nil {
+		return err
+	}
 
 	// Merge cards and reindex positions
 	merged := append([]PlannerCard{}, target.Cards...)
@@ -270,6 +421,7 @@ func UnsplitLane(ctx context.Context, laneID, targetLaneID string) error {
 		card.Position = len(target.Cards) + i
 		card.LaneID = targetLaneID
 		card.UpdatedAt = time.Now()
+		card.Revision++
 		merged = append(merged, card)
 	}
 
@@ -279,13 +431,16 @@ func UnsplitLane(ctx context.Context, laneID, targetLaneID string) error {
 	})
 
 	// Update target lane with merged cards
-	_, err = plannerCollection.UpdateOne(ctx,
-		bson.M{"lanes.id": targetLaneID},
+	result, err := plannerCollection.UpdateOne(ctx,
+		withWIPLimitGuard(bson.M{"lanes.id": targetLaneID}, wipLimitGuard(&planner, targetLaneID, source.Cards)),
 		bson.M{"$set": bson.M{"lanes.$.cards": merged, "lanes.$.updated_at": time.Now()}},
 	)
 	if err != nil {
 		return err
 	}
+	if result.MatchedCount == 0 {
+		return wipLimitConflict(ctx, planner.ID, targetLaneID, source.Cards)
+	}
 
 	// Remove source lane
 	_, err = plannerCollection.UpdateOne(ctx,
@@ -296,6 +451,11 @@ func UnsplitLane(ctx context.Context, laneID, targetLaneID string) error {
 		return err
 	}
 
+	recordLaneLayoutActivity(ctx, &planner, EventLaneUnsplit, laneID)
+	for _, card := range source.Cards {
+		recordTransition(ctx, planner.ID, card.ID, source, target)
+	}
+	publishEvent(ctx, planner.ID, EventLaneUnsplit, bson.M{"lane_id": laneID, "target_lane_id": targetLaneID})
 	return nil
 }
 
@@ -322,6 +482,9 @@ func HandleUnsplitLane(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := UnsplitLane(r.Context(), laneID, targetLaneID); err != nil {
+		if writeWIPLimitError(w, err) {
+			return
+		}
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -349,6 +512,7 @@ func HandleAddLane(w http.ResponseWriter, r *http.Request) {
 		Title       string `json:"title"`
 		Description string `json:"description"`
 		Color       string `json:"color"`
+		Category    string `json:"category"`
 		Position    int    `json:"position"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
@@ -356,9 +520,13 @@ func HandleAddLane(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	lane, err := AddLane(r.Context(), plannerID, request.Title, request.Description, request.Color, request.Position)
+	lane, err := AddLane(r.Context(), plannerID, request.Title, request.Description, request.Color, request.Category, request.Position)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		status := http.StatusInternalServerError
+		if errors.Is(err, ErrInvalidSettings) {
+			status = http.StatusBadRequest
+		}
+		http.Error(w, err.Error(), status)
 		return
 	}
 
@@ -388,18 +556,24 @@ func HandleUpdateLane(w http.ResponseWriter, r *http.Request) {
 
 	// Parse request body
 	var request struct {
-		Title       string `json:"title"`
-		Description string `json:"description"`
-		Color       string `json:"color"`
+		Title       string  `json:"title"`
+		Description string  `json:"description"`
+		Color       string  `json:"color"`
+		WIPLimit    *int    `json:"wip_limit,omitempty"`
+		Category    *string `json:"category,omitempty"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	lane, err := UpdateLane(r.Context(), laneID, request.Title, request.Description, request.Color)
+	lane, err := UpdateLane(r.Context(), laneID, request.Title, request.Description, request.Color, request.WIPLimit, request.Category)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		status := http.StatusInternalServerError
+		if errors.Is(err, ErrInvalidSettings) {
+			status = http.StatusBadRequest
+		}
+		http.Error(w, err.Error(), status)
 		return
 	}
 
@@ -409,7 +583,7 @@ func HandleUpdateLane(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// HandleDeleteLane handles DELETE /planner/{id}/lane/{laneId}
+// HandleDeleteLane handles DELETE /planner/{id}/lane/{laneId}[?permanent=true]
 func HandleDeleteLane(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodDelete {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -425,6 +599,15 @@ func HandleDeleteLane(w http.ResponseWriter, r *http.Request) {
 	}
 	laneID := parts[4]
 
+	// Lanes are archived with their cards unless a permanent delete is requested
+	if r.URL.Query().Get("permanent") != "true" {
+		if _, err := ArchiveLane(r.Context(), laneID); err != nil {
+			http.Error(w, err.Error(), archiveErrorStatus(err))
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
 	if err := DeleteLane(r.Context(), laneID); err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -0,0 +1,514 @@
+package planner
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Card link types. blocked-by is stored as a blocks link in the other direction;
+// duplicated-by is how a duplicates link looks from its target.
+const (
+	LinkBlocks       = "blocks"
+	LinkBlockedBy    = "blocked-by"
+	LinkRelatesTo    = "relates-to"
+	LinkDuplicates   = "duplicates"
+	LinkDuplicatedBy = "duplicated-by"
+)
+
+var (
+	// ErrInvalidLink is returned when a card link is malformed or already exists
+	ErrInvalidLink = errors.New("invalid card link")
+	// ErrLinkCycle is returned when a blocking link would make cards block themselves
+	ErrLinkCycle = errors.New("blocking link would create a cycle")
+	// ErrCardBlocked is returned when a blocked card is moved into a done lane and the planner rejects it
+	ErrCardBlocked = errors.New("card is blocked")
+)
+
+var cardLinkCollection *mongo.Collection
+
+// CardLink is a typed link from one card to another, possibly in another planner
+type CardLink struct {
+	ID              string    `json:"id" bson:"id"`
+	Type            string    `json:"type" bson:"type"` // blocks, relates-to or duplicates
+	SourceCardID    string    `json:"source_card_id" bson:"source_card_id"`
+	SourcePlannerID string    `json:"source_planner_id" bson:"source_planner_id"`
+	TargetCardID    string    `json:"target_card_id" bson:"target_card_id"`
+	TargetPlannerID string    `json:"target_planner_id" bson:"target_planner_id"`
+	CreatedBy       string    `json:"created_by,omitempty" bson:"created_by,omitempty"`
+	CreatedAt       time.Time `json:"created_at" bson:"created_at"`
+}
+
+// CardLinkView is a link as seen from one of its cards
+type CardLinkView struct {
+	ID        string       `json:"id"`
+	Type      string       `json:"type"`           // from the card's point of view, e.g. blocked-by
+	CardID    string       `json:"card_id"`        // the other card
+	Card      *CardSummary `json:"card,omitempty"` // nil when the other card was deleted or is not accessible
+	CreatedBy string       `json:"created_by,omitempty"`
+	CreatedAt time.Time    `json:"created_at"`
+}
+
+// blockerState is what decides whether a blocking card still blocks
+type blockerState struct {
+	CardID       string     `bson:"card_id"`
+	ArchivedAt   *time.Time `bson:"archived_at"`
+	LaneTitle    string     `bson:"lane_title"`
+	LaneCategory string     `bson:"lane_category"`
+}
+
+// resolved reports whether a blocker no longer blocks: it is done or archived
+func (b blockerState) resolved() bool {
+	return b.ArchivedAt != nil || laneCategory(&PlannerLane{Title: b.LaneTitle, Category: b.LaneCategory}) == LaneCategoryDone
+}
+
+// blockingPathExists reports whether from reaches to through blocking links.
+// successors returns the cards blocked by any of the given cards.
+func blockingPathExists(from, to string, successors func(cardIDs []string) ([]string, error)) (bool, error) {
+	visited := map[string]bool{from: true}
+	frontier := []string{from}
+	for len(frontier) > 0 {
+		next, err := successors(frontier)
+		if err != nil {
+			return false, err
+		}
+		frontier = nil
+		for _, id := range next {
+			if id == to {
+				return true, nil
+			}
+			if !visited[id] {
+				visited[id] = true
+				frontier = append(frontier, id)
+			}
+		}
+	}
+	return false, nil
+}
+
+// blockedSuccessors returns the cards blocked by any of the given cards
+func blockedSuccessors(ctx context.Context, cardIDs []string) ([]string, error) {
+	cur, err := cardLinkCollection.Find(ctx, bson.M{"type": LinkBlocks, "source_card_id": bson.M{"$in": cardIDs}})
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+	var links []CardLink
+	if err := cur.All(ctx, &links); err != nil {
+		return nil, err
+	}
+	ids := []string{}
+	for _, l := range links {
+		ids = append(ids, l.TargetCardID)
+	}
+	return ids, nil
+}
+
+// cardPlannerForUser returns the ID of the planner holding a card, checking that the user can access it
+func cardPlannerForUser(ctx context.Context, cardID, userID string) (string, error) {
+	filter := bson.M{"lanes.cards.id": cardID}
+	if userID != "" {
+		filter = bson.M{"$and": []bson.M{filter, plannerAccessFilter(userID)}}
+	}
+	var result struct {
+		ID string `bson:"id"`
+	}
+	opts := options.FindOne().SetProjection(bson.M{"id": 1})
+	if err := plannerCollection.FindOne(ctx, filter, opts).Decode(&result); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) && userID != "" {
+			if _, findErr := findPlannerIDByCard(ctx, cardID); findErr == nil {
+				return "", fmt.Errorf("%w: no access to the planner of card %s", ErrForbidden, cardID)
+			}
+		}
+		return "", err
+	}
+	return result.ID, nil
+}
+
+// CreateCardLink links a card to another card. Linking a card in another planner requires access to it.
+func CreateCardLink(ctx context.Context, userID, cardID, linkType, targetCardID string) (*CardLink, error) {
+	log.Printf("Linking cards: cardID=%s, type=%s, targetCardID=%s", cardID, linkType, targetCardID)
+
+	if cardID == targetCardID {
+		return nil, fmt.Errorf("%w: a card can't be linked to itself", ErrInvalidLink)
+	}
+	source, target := cardID, targetCardID
+	switch linkType {
+	case LinkBlocks, LinkRelatesTo, LinkDuplicates:
+	case LinkBlockedBy:
+		linkType, source, target = LinkBlocks, targetCardID, cardID
+	default:
+		return nil, fmt.Errorf("%w: type must be blocks, blocked-by, relates-to or duplicates", ErrInvalidLink)
+	}
+
+	sourcePlannerID, err := cardPlannerForUser(ctx, source, userID)
+	if err != nil {
+		return nil, err
+	}
+	targetPlannerID, err := cardPlannerForUser(ctx, target, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Relates-to links have no direction, so either direction counts as existing
+	existing := bson.M{"type": linkType, "source_card_id": source, "target_card_id": target}
+	if linkType == LinkRelatesTo {
+		existing = bson.M{"type": linkType, "$or": []bson.M{
+			{"source_card_id": source, "target_card_id": target},
+			{"source_card_id": target, "target_card_id": source},
+		}}
+	}
+	if n, err := cardLinkCollection.CountDocuments(ctx, existing); err != nil {
+		return nil, err
+	} else if n > 0 {
+		return nil, fmt.Errorf("%w: the cards are already linked", ErrInvalidLink)
+	}
+
+	if linkType == LinkBlocks {
+		cycle, err := blockingPathExists(target, source, func(ids []string) ([]string, error) {
+			return blockedSuccessors(ctx, ids)
+		})
+		if err != nil {
+			return nil, err
+		}
+		if cycle {
+			return nil, fmt.Errorf("%w: %s already depends on %s", ErrLinkCycle, source, target)
+		}
+	}
+
+	link := &CardLink{
+		ID:              GenerateID(),
+		Type:            linkType,
+		SourceCardID:    source,
+		SourcePlannerID: sourcePlannerID,
+		TargetCardID:    target,
+		TargetPlannerID: targetPlannerID,
+		CreatedBy:       userID,
+		CreatedAt:       time.Now(),
+	}
+	if _, err := cardLinkCollection.InsertOne(ctx, link); err != nil {
+		return nil, err
+	}
+	// A concurrent request may have linked the cards the other way between the check and the insert.
+	// With the link stored, it is part of a cycle exactly when its target leads back to its source.
+	if linkType == LinkBlocks {
+		cycle, err := blockingPathExists(target, source, func(ids []string) ([]string, error) {
+			return blockedSuccessors(ctx, ids)
+		})
+		if err != nil || cycle {
+			if _, delErr := cardLinkCollection.DeleteOne(ctx, bson.M{"id": link.ID}); delErr != nil {
+				log.Printf("[ERROR] Failed to remove link %s: %v", link.ID, delErr)
+			}
+			if err != nil {
+				return nil, err
+			}
+			return nil, fmt.Errorf("%w: %s already depends on %s", ErrLinkCycle, source, target)
+		}
+	}
+	publishEvent(ctx, sourcePlannerID, EventCardLinked, link)
+	if targetPlannerID != sourcePlannerID {
+		publishEvent(ctx, targetPlannerID, EventCardLinked, link)
+	}
+	return link, nil
+}
+
+// DeleteCardLink removes a link of a card. It requires access to the card's planner.
+func DeleteCardLink(ctx context.Context, userID, cardID, linkID string) error {
+	log.Printf("Unlinking cards: cardID=%s, linkID=%s", cardID, linkID)
+
+	if _, err := cardPlannerForUser(ctx, cardID, userID); err != nil {
+		return err
+	}
+	filter := bson.M{"id": linkID, "$or": []bson.M{{"source_card_id": cardID}, {"target_card_id": cardID}}}
+	var link CardLink
+	if err := cardLinkCollection.FindOneAndDelete(ctx, filter).Decode(&link); err != nil {
+		return err
+	}
+	publishEvent(ctx, link.SourcePlannerID, EventCardUnlinked, bson.M{"id": linkID})
+	if link.TargetPlannerID != link.SourcePlannerID {
+		publishEvent(ctx, link.TargetPlannerID, EventCardUnlinked, bson.M{"id": linkID})
+	}
+	return nil
+}
+
+// cardLinksFilter matches every link of the given cards
+func cardLinksFilter(cardIDs []string) bson.M {
+	return bson.M{"$or": []bson.M{
+		{"source_card_id": bson.M{"$in": cardIDs}},
+		{"target_card_id": bson.M{"$in": cardIDs}},
+	}}
+}
+
+// deleteCardLinks removes every link of deleted cards and returns them, so undoing the delete can restore them
+func deleteCardLinks(ctx context.Context, cardIDs ...string) ([]CardLink, error) {
+	cur, err := cardLinkCollection.Find(ctx, cardLinksFilter(cardIDs))
+	if err != nil {
+		return nil, err
+	}
+	links := []CardLink{}
+	if err := cur.All(ctx, &links); err != nil {
+		return nil, err
+	}
+	if len(links) == 0 {
+		return links, nil
+	}
+	_, err = cardLinkCollection.DeleteMany(ctx, cardLinksFilter(cardIDs))
+	return links, err
+}
+
+// restoreCardLinks puts back links removed with a card. Links that exist again are skipped.
+func restoreCardLinks(ctx context.Context, links []CardLink) error {
+	if len(links) == 0 {
+		return nil
+	}
+	docs := make([]interface{}, len(links))
+	for i := range links {
+		docs[i] = links[i]
+	}
+	_, err := cardLinkCollection.InsertMany(ctx, docs, options.InsertMany().SetOrdered(false))
+	if err != nil && !mongo.IsDuplicateKeyError(err) {
+		return err
+	}
+	return nil
+}
+
+// GetCardLinks returns the links of a card, with the other card if the user can access it.
+// It requires access to the card's planner.
+func GetCardLinks(ctx context.Context, userID, cardID string) ([]CardLinkView, error) {
+	log.Printf("Getting card links: cardID=%s", cardID)
+
+	if _, err := cardPlannerForUser(ctx, cardID, userID); err != nil {
+		return nil, err
+	}
+	cur, err := cardLinkCollection.Find(ctx,
+		bson.M{"$or": []bson.M{{"source_card_id": cardID}, {"target_card_id": cardID}}},
+		options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+	var links []CardLink
+	if err := cur.All(ctx, &links); err != nil {
+		return nil, err
+	}
+
+	views := []CardLinkView{}
+	otherIDs := []string{}
+	for _, l := range links {
+		view := CardLinkView{ID: l.ID, Type: l.Type, CardID: l.TargetCardID, CreatedBy: l.CreatedBy, CreatedAt: l.CreatedAt}
+		if l.TargetCardID == cardID {
+			view.CardID = l.SourceCardID
+			switch l.Type {
+			case LinkBlocks:
+				view.Type = LinkBlockedBy
+			case LinkDuplicates:
+				view.Type = LinkDuplicatedBy
+			}
+		}
+		views = append(views, view)
+		otherIDs = append(otherIDs, view.CardID)
+	}
+	if len(views) == 0 {
+		return views, nil
+	}
+
+	plannerFilter := bson.M{}
+	if userID != "" {
+		plannerFilter = plannerAccessFilter(userID)
+	}
+	summaries, err := findCardSummaries(ctx, plannerFilter, bson.M{"id": bson.M{"$in": otherIDs}})
+	if err != nil {
+		return nil, err
+	}
+	byID := map[string]*CardSummary{}
+	for i := range summaries {
+		byID[summaries[i].Card.ID] = &summaries[i]
+	}
+	for i := range views {
+		views[i].Card = byID[views[i].CardID]
+	}
+	return views, nil
+}
+
+// blockerStates returns the state of the given cards wherever they are
+func blockerStates(ctx context.Context, cardIDs []string) (map[string]blockerState, error) {
+	pipeline := []bson.M{
+		{"$match": bson.M{"lanes.cards.id": bson.M{"$in": cardIDs}}},
+		{"$unwind": "$lanes"},
+		{"$unwind": "$lanes.cards"},
+		{"$match": bson.M{"lanes.cards.id": bson.M{"$in": cardIDs}}},
+		{"$project": bson.M{
+			"_id":           0,
+			"card_id":       "$lanes.cards.id",
+			"archived_at":   "$lanes.cards.archived_at",
+			"lane_title":    "$lanes.title",
+			"lane_category": "$lanes.category",
+		}},
+	}
+	cursor, err := plannerCollection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	var states []blockerState
+	if err := cursor.All(ctx, &states); err != nil {
+		return nil, err
+	}
+	result := map[string]blockerState{}
+	for _, s := range states {
+		result[s.CardID] = s
+	}
+	return result, nil
+}
+
+// unresolvedBlockers returns, for each card of a planner that is blocked, the IDs of the cards still blocking it.
+// Blockers that are done, archived or deleted no longer block.
+func unresolvedBlockers(ctx context.Context, planner *Planner) (map[string][]string, error) {
+	cardIDs := []string{}
+	for _, lane := range planner.Lanes {
+		for _, card := range lane.Cards {
+			cardIDs = append(cardIDs, card.ID)
+		}
+	}
+	if len(cardIDs) == 0 {
+		return map[string][]string{}, nil
+	}
+
+	cur, err := cardLinkCollection.Find(ctx, bson.M{"type": LinkBlocks, "target_card_id": bson.M{"$in": cardIDs}})
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+	var links []CardLink
+	if err := cur.All(ctx, &links); err != nil {
+		return nil, err
+	}
+	if len(links) == 0 {
+		return map[string][]string{}, nil
+	}
+
+	blockerIDs := []string{}
+	for _, l := range links {
+		blockerIDs = append(blockerIDs, l.SourceCardID)
+	}
+	states, err := blockerStates(ctx, blockerIDs)
+	if err != nil {
+		return nil, err
+	}
+	return blockedBy(links, states), nil
+}
+
+// blockedBy groups the unresolved blockers of blocking links by blocked card
+func blockedBy(links []CardLink, states map[string]blockerState) map[string][]string {
+	blocked := map[string][]string{}
+	for _, l := range links {
+		state, ok := states[l.SourceCardID]
+		if l.Type != LinkBlocks || !ok || state.resolved() {
+			continue
+		}
+		blocked[l.TargetCardID] = append(blocked[l.TargetCardID], l.SourceCardID)
+	}
+	return blocked
+}
+
+// flagBlockedCards sets the blocked status of the cards of a planner
+func flagBlockedCards(planner *Planner, blocked map[string][]string) {
+	for i := range planner.Lanes {
+		for j := range planner.Lanes[i].Cards {
+			card := &planner.Lanes[i].Cards[j]
+			card.BlockedBy = blocked[card.ID]
+			card.Blocked = len(card.BlockedBy) > 0
+		}
+	}
+}
+
+// checkBlockedDone returns ErrCardBlocked if the planner rejects moving blocked cards into done lanes
+// and the card is blocked
+func checkBlockedDone(planner *Planner, lane *PlannerLane, cardID string, blocked map[string][]string) error {
+	if !planner.Settings.RejectBlockedDone || laneCategory(lane) != LaneCategoryDone || len(blocked[cardID]) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w by %s", ErrCardBlocked, strings.Join(blocked[cardID], ", "))
+}
+
+// linkErrorStatus maps card link errors to HTTP status codes
+func linkErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, ErrInvalidLink):
+		return http.StatusBadRequest
+	case errors.Is(err, ErrLinkCycle):
+		return http.StatusConflict
+	case errors.Is(err, ErrForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, mongo.ErrNoDocuments):
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
+
+// HandleCardLinks handles GET and POST /planner/{id}/card/{cardId}/links and DELETE /planner/{id}/card/{cardId}/links/{linkId}
+func HandleCardLinks(w http.ResponseWriter, r *http.Request) {
+	// Extract card and link IDs from path
+	path := r.URL.Path
+	parts := strings.Split(path, "/")
+	if len(parts) < 6 || len(parts) > 7 || parts[1] != "planner" || parts[3] != "card" || parts[5] != "links" {
+		http.Error(w, "Invalid path", http.StatusBadRequest)
+		return
+	}
+	cardID := parts[4]
+	linkID := ""
+	if len(parts) == 7 {
+		linkID = parts[6]
+	}
+	userID, _ := r.Context().Value("user_id").(string)
+
+	var response interface{}
+	switch {
+	case r.Method == http.MethodGet && linkID == "":
+		links, err := GetCardLinks(r.Context(), userID, cardID)
+		if err != nil {
+			http.Error(w, err.Error(), linkErrorStatus(err))
+			return
+		}
+		response = links
+	case r.Method == http.MethodPost && linkID == "":
+		var request struct {
+			Type         string `json:"type"`
+			TargetCardID string `json:"target_card_id"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		link, err := CreateCardLink(r.Context(), userID, cardID, request.Type, request.TargetCardID)
+		if err != nil {
+			http.Error(w, err.Error(), linkErrorStatus(err))
+			return
+		}
+		response = link
+	case r.Method == http.MethodDelete && linkID != "":
+		if err := DeleteCardLink(r.Context(), userID, cardID, linkID); err != nil {
+			http.Error(w, err.Error(), linkErrorStatus(err))
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
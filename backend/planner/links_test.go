@@ -0,0 +1,132 @@
+package planner
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestBlockingPathExists(t *testing.T) {
+	// a blocks b, b blocks c and d
+	graph := map[string][]string{"a": {"b"}, "b": {"c", "d"}, "d": {"b"}}
+	successors := func(ids []string) ([]string, error) {
+		next := []string{}
+		for _, id := range ids {
+			next = append(next, graph[id]...)
+		}
+		return next, nil
+	}
+
+	if ok, err := blockingPathExists("a", "c", successors); err != nil || !ok {
+		t.Errorf("expected a to reach c, got %v, %v", ok, err)
+	}
+	if ok, err := blockingPathExists("c", "a", successors); err != nil || ok {
+		t.Errorf("expected c not to reach a, got %v, %v", ok, err)
+	}
+	if ok, _ := blockingPathExists("b", "x", successors); ok {
+		t.Error("expected no path to an unknown card")
+	}
+
+	failing := func([]string) ([]string, error) { return nil, errors.New("boom") }
+	if _, err := blockingPathExists("a", "b", failing); err == nil {
+		t.Error("expected lookup error to be returned")
+	}
+}
+
+func TestBlockedBy(t *testing.T) {
+	now := time.Now()
+	links := []CardLink{
+		{Type: LinkBlocks, SourceCardID: "open", TargetCardID: "c1"},
+		{Type: LinkBlocks, SourceCardID: "done", TargetCardID: "c1"},
+		{Type: LinkBlocks, SourceCardID: "archived", TargetCardID: "c2"},
+		{Type: LinkBlocks, SourceCardID: "deleted", TargetCardID: "c2"},
+		{Type: LinkRelatesTo, SourceCardID: "open", TargetCardID: "c3"},
+	}
+	states := map[string]blockerState{
+		"open":     {CardID: "open", LaneCategory: LaneCategoryInProgress},
+		"done":     {CardID: "done", LaneCategory: LaneCategoryDone},
+		"archived": {CardID: "archived", ArchivedAt: &now},
+	}
+
+	blocked := blockedBy(links, states)
+	if len(blocked) != 1 || len(blocked["c1"]) != 1 || blocked["c1"][0] != "open" {
+		t.Errorf("expected only c1 to be blocked by open, got %v", blocked)
+	}
+
+	p := &Planner{Lanes: []PlannerLane{{ID: "l", Cards: []PlannerCard{{ID: "c1"}, {ID: "c2"}}}}}
+	flagBlockedCards(p, blocked)
+	if !p.Lanes[0].Cards[0].Blocked || p.Lanes[0].Cards[1].Blocked {
+		t.Errorf("unexpected blocked flags: %+v", p.Lanes[0].Cards)
+	}
+}
+
+func TestCheckBlockedDone(t *testing.T) {
+	p := &Planner{Lanes: []PlannerLane{{ID: "done", Category: LaneCategoryDone}, {ID: "doing", Category: LaneCategoryInProgress}}}
+	blocked := map[string][]string{"c1": {"c2"}}
+
+	if err := checkBlockedDone(p, &p.Lanes[0], "c1", blocked); err != nil {
+		t.Errorf("setting is off, expected no error, got %v", err)
+	}
+	p.Settings.RejectBlockedDone = true
+	if err := checkBlockedDone(p, &p.Lanes[0], "c1", blocked); !errors.Is(err, ErrCardBlocked) {
+		t.Errorf("expected ErrCardBlocked, got %v", err)
+	}
+	if err := checkBlockedDone(p, &p.Lanes[1], "c1", blocked); err != nil {
+		t.Errorf("lane isn't done, expected no error, got %v", err)
+	}
+	if err := checkBlockedDone(p, &p.Lanes[0], "c2", blocked); err != nil {
+		t.Errorf("card isn't blocked, expected no error, got %v", err)
+	}
+}
+
+func TestBlockingPathExists_ConcurrentLinks(t *testing.T) {
+	// Two requests linked "a blocks b" and "b blocks a" at the same time; each checks its stored link
+	graph := map[string][]string{"a": {"b"}, "b": {"a"}}
+	successors := func(ids []string) ([]string, error) {
+		next := []string{}
+		for _, id := range ids {
+			next = append(next, graph[id]...)
+		}
+		return next, nil
+	}
+	for _, link := range [][2]string{{"a", "b"}, {"b", "a"}} {
+		if ok, err := blockingPathExists(link[1], link[0], successors); err != nil || !ok {
+			t.Errorf("%s blocks %s: expected the stored link to be found in a cycle, got %v, %v", link[0], link[1], ok, err)
+		}
+	}
+}
+
+func TestCardLinksRequireAccess(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	mt.Run("no access", func(mt *mtest.T) {
+		defer func(p, l *mongo.Collection) { plannerCollection, cardLinkCollection = p, l }(plannerCollection, cardLinkCollection)
+		plannerCollection = mt.DB.Collection("planners")
+		cardLinkCollection = mt.DB.Collection("card_links")
+
+		// The card's planner exists but isn't shared with the user
+		noAccess := func() {
+			mt.AddMockResponses(
+				mtest.CreateCursorResponse(0, "test.planners", mtest.FirstBatch),
+				mtest.CreateCursorResponse(0, "test.planners", mtest.FirstBatch, bson.D{{Key: "id", Value: "p1"}}),
+			)
+		}
+		noAccess()
+		if _, err := GetCardLinks(context.Background(), "mallory", "c1"); !errors.Is(err, ErrForbidden) {
+			t.Errorf("expected listing links to be forbidden, got %v", err)
+		}
+		noAccess()
+		if err := DeleteCardLink(context.Background(), "mallory", "c1", "link1"); !errors.Is(err, ErrForbidden) {
+			t.Errorf("expected deleting a link to be forbidden, got %v", err)
+		}
+		for _, event := range mt.GetAllStartedEvents() {
+			if event.Command.Lookup(event.CommandName).StringValue() == "card_links" {
+				t.Errorf("expected no link to be read or deleted, got %s", event.CommandName)
+			}
+		}
+	})
+}
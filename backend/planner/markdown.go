@@ -0,0 +1,715 @@
+package planner
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	markdownHeadingRe = regexp.MustCompile(`^(#{1,6})\s+(.*?)(?:\s+#+)?\s*$`)
+	markdownTaskRe    = regexp.MustCompile(`^\s*[-*+]\s+\[([ xX])\]\s+(.*)$`)
+
+	// Text lines that would be read as structure: headings, task list items and code fences.
+	// Any backslashes already in front are part of the match, so escaping stays reversible.
+	markdownEscapeRe   = regexp.MustCompile("^(\\s*)(\\\\*(?:#{1,3}\\s|[-*+]\\s+\\[[ xX]\\]\\s|```|~~~))")
+	markdownUnescapeRe = regexp.MustCompile("^(\\s*)\\\\(\\\\*(?:#{1,3}\\s|[-*+]\\s+\\[[ xX]\\]\\s|```|~~~))")
+	// A rule at the start of card content would be read as front matter
+	markdownRuleEscapeRe   = regexp.MustCompile(`^(\s*)(\\*---\s*)$`)
+	markdownRuleUnescapeRe = regexp.MustCompile(`^(\s*)\\(\\*---\s*)$`)
+)
+
+// ImportWarning describes part of an imported document that could not be mapped
+type ImportWarning struct {
+	Line    int    `json:"line,omitempty"`
+	Section string `json:"section,omitempty"`
+	Message string `json:"message"`
+}
+
+// ImportReport summarizes what an import created and what it had to skip
+type ImportReport struct {
+	Lanes          int             `json:"lanes"`
+	Cards          int             `json:"cards"`
+	Columns        int             `json:"columns"`
+	ChecklistItems int             `json:"checklist_items"`
+	Warnings       []ImportWarning `json:"warnings"`
+}
+
+func (r *ImportReport) warn(line int, section, format string, args ...interface{}) {
+	r.Warnings = append(r.Warnings, ImportWarning{Line: line, Section: section, Message: fmt.Sprintf(format, args...)})
+}
+
+// markdownLine is a source line with its 1-based line number
+type markdownLine struct {
+	n    int
+	text string
+}
+
+// ParsePlannerMarkdown parses a document produced by ExportPlannerMarkdown back into a planner.
+// `#` is the planner title, `##` a lane and `###` a card; task list items become checklist items.
+// Front matter at the top of the document declares columns, and a front matter block right after
+// a card heading holds the card's column values and attributes. The planner is not stored.
+func ParsePlannerMarkdown(markdown, userID string) (*Planner, *ImportReport) {
+	report := &ImportReport{Warnings: []ImportWarning{}}
+	now := time.Now()
+	planner := &Planner{
+		ID:        GenerateID(),
+		UserID:    userID,
+		CreatedAt: now,
+		UpdatedAt: now,
+		Lanes:     []PlannerLane{},
+		Columns:   []PlannerColumn{},
+	}
+
+	raw := strings.Split(strings.ReplaceAll(markdown, "\r\n", "\n"), "\n")
+	lines := make([]markdownLine, len(raw))
+	for i, text := range raw {
+		lines[i] = markdownLine{n: i + 1, text: text}
+	}
+
+	// Document front matter declares the planner columns
+	if len(lines) > 0 && strings.TrimSpace(lines[0].text) == "---" {
+		block, rest, ok := splitFrontMatter(lines)
+		if ok {
+			entries := parseFrontMatter(block, "front matter", report)
+			planner.Columns = parseMarkdownColumns(entries, block, report)
+			for i := range planner.Columns {
+				planner.Columns[i].PlannerID = planner.ID
+			}
+			for key := range entries {
+				if key != "columns" {
+					report.warn(block[0].n, "front matter", "unknown front matter key %q ignored", key)
+				}
+			}
+			lines = rest
+		} else {
+			report.warn(lines[0].n, "front matter", "front matter is not closed with ---, treated as text")
+		}
+	}
+
+	var lane *PlannerLane
+	var card *PlannerCard
+	var body []markdownLine
+	titleSeen := false
+	inFence := false
+
+	// flush assigns the text collected since the last heading to the planner, lane or card
+	flush := func() {
+		switch {
+		case card != nil:
+			finishMarkdownCard(planner, card, body, report)
+			lane.Cards = append(lane.Cards, *card)
+			card = nil
+		case lane != nil:
+			lane.Description = joinMarkdown(body)
+		case titleSeen:
+			planner.Description = joinMarkdown(body)
+		default:
+			if text := joinMarkdown(body); text != "" {
+				report.warn(body[0].n, "", "text before the planner title was ignored")
+			}
+		}
+		body = nil
+	}
+	ensureLane := func(line int) {
+		if lane != nil {
+			return
+		}
+		report.warn(line, "", "card found before any lane, added to lane \"Imported\"")
+		planner.Lanes = append(planner.Lanes, newMarkdownLane(planner, "Imported"))
+		lane = &planner.Lanes[len(planner.Lanes)-1]
+	}
+
+	for _, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line.text), "```") || strings.HasPrefix(strings.TrimSpace(line.text), "~~~") {
+			inFence = !inFence
+		}
+		m := markdownHeadingRe.FindStringSubmatch(line.text)
+		if inFence || m == nil || len(m[1]) > 3 {
+			body = append(body, line)
+			continue
+		}
+
+		level, heading := len(m[1]), m[2]
+		switch level {
+		case 1:
+			if titleSeen {
+				report.warn(line.n, heading, "additional # heading kept as text")
+				body = append(body, line)
+				continue
+			}
+			flush()
+			titleSeen = true
+			planner.Title = heading
+		case 2:
+			flush()
+			planner.Lanes = append(planner.Lanes, newMarkdownLane(planner, heading))
+			lane = &planner.Lanes[len(planner.Lanes)-1]
+		case 3:
+			flush()
+			ensureLane(line.n)
+			card = &PlannerCard{
+				ID:        GenerateID(),
+				LaneID:    lane.ID,
+				Fields:    map[string]interface{}{FieldTitle: heading, FieldContent: ""},
+				Position:  len(lane.Cards) + 1,
+				CreatedAt: now,
+				UpdatedAt: now,
+			}
+		}
+	}
+	if inFence {
+		report.warn(len(raw), "", "code block is not closed")
+	}
+	flush()
+
+	if !titleSeen || strings.TrimSpace(planner.Title) == "" {
+		report.warn(0, "", "document has no # title, using \"Imported Planner\"")
+		planner.Title = "Imported Planner"
+	}
+	for _, l := range planner.Lanes {
+		report.Cards += len(l.Cards)
+		for _, c := range l.Cards {
+			report.ChecklistItems += len(c.Checklist)
+		}
+	}
+	report.Lanes = len(planner.Lanes)
+	report.Columns = len(planner.Columns)
+	return planner, report
+}
+
+// newMarkdownLane creates an empty lane at the end of a planner
+func newMarkdownLane(planner *Planner, title string) PlannerLane {
+	return PlannerLane{
+		ID:        GenerateID(),
+		PlannerID: planner.ID,
+		Title:     title,
+		Category:  inferLaneCategory(title),
+		Position:  len(planner.Lanes) + 1,
+		CreatedAt: planner.CreatedAt,
+		UpdatedAt: planner.CreatedAt,
+		Cards:     []PlannerCard{},
+	}
+}
+
+// finishMarkdownCard reads a card's front matter, task list and content from its body lines
+func finishMarkdownCard(planner *Planner, card *PlannerCard, body []markdownLine, report *ImportReport) {
+	section, _ := card.Fields[FieldTitle].(string)
+
+	// Skip blank lines between the heading and a front matter block
+	start := 0
+	for start < len(body) && strings.TrimSpace(body[start].text) == "" {
+		start++
+	}
+	if start < len(body) && strings.TrimSpace(body[start].text) == "---" {
+		if block, rest, ok := splitFrontMatter(body[start:]); ok {
+			applyCardFrontMatter(planner, card, parseFrontMatter(block, section, report), block[0].n, section, report)
+			body = rest
+		}
+	}
+
+	content := []markdownLine{}
+	inFence := false
+	for _, line := range body {
+		trimmed := strings.TrimSpace(line.text)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+		}
+		if m := markdownTaskRe.FindStringSubmatch(line.text); m != nil && !inFence {
+			card.Checklist = append(card.Checklist, ChecklistItem{
+				ID:        GenerateID(),
+				Text:      m[2],
+				Done:      m[1] != " ",
+				Position:  len(card.Checklist) + 1,
+				CreatedAt: card.CreatedAt,
+				UpdatedAt: card.CreatedAt,
+			})
+			continue
+		}
+		content = append(content, line)
+	}
+	card.Fields[FieldContent] = joinMarkdownText(content, true)
+	normalizeCard(card)
+}
+
+// applyCardFrontMatter maps front matter entries to card attributes and column values by column name
+func applyCardFrontMatter(planner *Planner, card *PlannerCard, entries map[string]interface{}, line int, section string, report *ImportReport) {
+	byName := map[string]PlannerColumn{}
+	for _, col := range planner.Columns {
+		byName[strings.ToLower(col.Name)] = col
+	}
+
+	keys := make([]string, 0, len(entries))
+	for key := range entries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		value := entries[key]
+		switch key {
+		case "assignees":
+			for _, a := range frontMatterList(value) {
+				if IsPlannerMember(planner, a) {
+					card.Assignees = append(card.Assignees, a)
+				} else {
+					report.warn(line, section, "assignee %q is not a planner member and was dropped", a)
+				}
+			}
+			continue
+		case "labels":
+			card.Labels = normalizeLabels(frontMatterList(value))
+			continue
+		case "priority":
+			if p, _ := value.(string); isValidPriority(p) {
+				card.Priority = p
+			} else {
+				report.warn(line, section, "invalid priority %q ignored", value)
+			}
+			continue
+		case "due_date", "start_date":
+			s, _ := value.(string)
+			t, err := parseDate(s)
+			if err != nil {
+				report.warn(line, section, "invalid %s %q ignored", key, s)
+				continue
+			}
+			t = t.UTC()
+			if key == "due_date" {
+				card.DueDate = &t
+			} else {
+				card.StartDate = &t
+			}
+			continue
+		}
+
+		col, ok := byName[strings.ToLower(key)]
+		if !ok {
+			report.warn(line, section, "unknown field %q ignored", key)
+			continue
+		}
+		s, ok := value.(string)
+		if !ok {
+			report.warn(line, section, "field %q must be a single value", key)
+			continue
+		}
+		converted, ok := convertColumnValue(col, s)
+		if !ok {
+			report.warn(line, section, "value %q is not valid for %s column %q", s, col.Type, col.Name)
+			continue
+		}
+		if col.Type == ColumnTypeUser && !IsPlannerMember(planner, s) {
+			report.warn(line, section, "user %q in column %q is not a planner member", s, col.Name)
+			continue
+		}
+		card.Fields[col.ID] = converted
+	}
+	if card.StartDate != nil && card.DueDate != nil && card.StartDate.After(*card.DueDate) {
+		report.warn(line, section, "start_date is after due_date, start_date ignored")
+		card.StartDate = nil
+	}
+}
+
+// parseMarkdownColumns builds planner columns from the `columns` front matter entry
+func parseMarkdownColumns(entries map[string]interface{}, block []markdownLine, report *ImportReport) []PlannerColumn {
+	columns := []PlannerColumn{}
+	line := 0
+	if len(block) > 0 {
+		line = block[0].n
+	}
+	items, _ := entries["columns"].([]map[string]interface{})
+	if v, ok := entries["columns"]; ok && items == nil {
+		report.warn(line, "front matter", "columns must be a list, got %v", v)
+	}
+	now := time.Now()
+	for _, item := range items {
+		name, _ := item["name"].(string)
+		colType, _ := item["type"].(string)
+		if colType == "" {
+			colType = ColumnTypeText
+		}
+		statusOptions := frontMatterList(item["options"])
+		if err := validateColumnDefinition(name, colType, statusOptions); err != nil {
+			report.warn(line, "front matter", "column %q skipped: %v", name, err)
+			continue
+		}
+		duplicate := false
+		for _, c := range columns {
+			duplicate = duplicate || strings.EqualFold(c.Name, name)
+		}
+		if duplicate {
+			report.warn(line, "front matter", "duplicate column %q skipped", name)
+			continue
+		}
+		col := PlannerColumn{
+			ID:        GenerateID(),
+			Name:      name,
+			Type:      colType,
+			Position:  len(columns) + 1,
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+		if colType == ColumnTypeStatus {
+			col.Options = statusOptions
+		}
+		columns = append(columns, col)
+	}
+	return columns
+}
+
+// splitFrontMatter splits a block delimited by --- lines from the lines that follow it
+func splitFrontMatter(lines []markdownLine) ([]markdownLine, []markdownLine, bool) {
+	for i := 1; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i].text) == "---" {
+			return lines[1:i], lines[i+1:], true
+		}
+	}
+	return nil, lines, false
+}
+
+// parseFrontMatter parses the YAML subset written by the exporter: `key: value` scalars,
+// inline `[a, b]` lists and lists of `- key: value` maps. Other lines are reported and skipped.
+func parseFrontMatter(lines []markdownLine, section string, report *ImportReport) map[string]interface{} {
+	entries := map[string]interface{}{}
+	var listKey string
+	var current map[string]interface{}
+
+	for _, line := range lines {
+		if strings.TrimSpace(line.text) == "" || strings.HasPrefix(strings.TrimSpace(line.text), "#") {
+			continue
+		}
+		indented := strings.HasPrefix(line.text, " ") || strings.HasPrefix(line.text, "\t")
+		text := strings.TrimSpace(line.text)
+
+		if indented && listKey != "" {
+			if strings.HasPrefix(text, "- ") {
+				current = map[string]interface{}{}
+				entries[listKey] = append(entries[listKey].([]map[string]interface{}), current)
+				text = strings.TrimSpace(text[2:])
+			}
+			key, value, ok := splitFrontMatterEntry(text)
+			if !ok || current == nil {
+				report.warn(line.n, section, "could not parse front matter line %q", text)
+				continue
+			}
+			current[key] = parseFrontMatterValue(value)
+			continue
+		}
+
+		listKey, current = "", nil
+		key, value, ok := splitFrontMatterEntry(text)
+		if !ok || indented {
+			report.warn(line.n, section, "could not parse front matter line %q", text)
+			continue
+		}
+		if value == "" {
+			listKey = key
+			entries[key] = []map[string]interface{}{}
+			continue
+		}
+		entries[key] = parseFrontMatterValue(value)
+	}
+	return entries
+}
+
+// splitFrontMatterEntry splits `key: value`, where the key may be a quoted string
+func splitFrontMatterEntry(text string) (string, string, bool) {
+	var key, rest string
+	if strings.HasPrefix(text, `"`) {
+		quoted, err := strconv.QuotedPrefix(text)
+		if err != nil {
+			return "", "", false
+		}
+		key, _ = strconv.Unquote(quoted)
+		rest = text[len(quoted):]
+		if !strings.HasPrefix(rest, ":") {
+			return "", "", false
+		}
+		rest = rest[1:]
+	} else {
+		i := strings.Index(text, ":")
+		if i <= 0 {
+			return "", "", false
+		}
+		key, rest = strings.TrimSpace(text[:i]), text[i+1:]
+	}
+	if rest != "" && !strings.HasPrefix(rest, " ") && !strings.HasPrefix(rest, "\t") {
+		return "", "", false
+	}
+	return key, strings.TrimSpace(rest), key != ""
+}
+
+// parseFrontMatterValue parses a scalar (optionally quoted) or an inline list
+func parseFrontMatterValue(s string) interface{} {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "[") && strings.HasSuffix(s, "]") {
+		items := []string{}
+		for _, item := range splitFrontMatterList(s[1 : len(s)-1]) {
+			if v := unquoteFrontMatter(item); v != "" {
+				items = append(items, v)
+			}
+		}
+		return items
+	}
+	return unquoteFrontMatter(s)
+}
+
+// splitFrontMatterList splits an inline list on commas outside of quotes
+func splitFrontMatterList(s string) []string {
+	items := []string{}
+	var b strings.Builder
+	inQuote, escaped := false, false
+	for _, r := range s {
+		switch {
+		case escaped:
+			escaped = false
+		case r == '\\' && inQuote:
+			escaped = true
+		case r == '"':
+			inQuote = !inQuote
+		case r == ',' && !inQuote:
+			items = append(items, b.String())
+			b.Reset()
+			continue
+		}
+		b.WriteRune(r)
+	}
+	if strings.TrimSpace(b.String()) != "" {
+		items = append(items, b.String())
+	}
+	return items
+}
+
+func unquoteFrontMatter(s string) string {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, `"`) {
+		if v, err := strconv.Unquote(s); err == nil {
+			return v
+		}
+	}
+	if len(s) >= 2 && strings.HasPrefix(s, "'") && strings.HasSuffix(s, "'") {
+		return strings.ReplaceAll(s[1:len(s)-1], "''", "'")
+	}
+	return s
+}
+
+// frontMatterList returns a front matter value as a list, accepting a single scalar too
+func frontMatterList(value interface{}) []string {
+	switch v := value.(type) {
+	case []string:
+		return v
+	case string:
+		if v == "" {
+			return nil
+		}
+		return []string{v}
+	}
+	return nil
+}
+
+// joinMarkdown joins lines, undoing escapeMarkdown, and trims surrounding blank lines
+func joinMarkdown(lines []markdownLine) string {
+	return joinMarkdownText(lines, false)
+}
+
+// joinMarkdownText is joinMarkdown for text escaped with or without the leading rule escape
+func joinMarkdownText(lines []markdownLine, rule bool) string {
+	texts := make([]string, len(lines))
+	for i, l := range lines {
+		texts[i] = l.text
+	}
+	return strings.Trim(unescapeMarkdown(strings.Join(texts, "\n"), rule), "\n \t")
+}
+
+func isMarkdownFence(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	return strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~")
+}
+
+// escapeMarkdown prefixes the lines of user text that the importer would read as headings, task list
+// items or code fences with a backslash, which Markdown renders as the literal character.
+// Code blocks are kept as they are unless one is left open, in which case its fence is escaped too so
+// it can't swallow the rest of the document. With rule, a --- first line is escaped so it isn't read
+// as front matter.
+func escapeMarkdown(text string, rule bool) string {
+	lines := strings.Split(text, "\n")
+	fences := 0
+	for _, line := range lines {
+		if isMarkdownFence(line) {
+			fences++
+		}
+	}
+	balanced := fences%2 == 0
+
+	inFence, first := false, true
+	for i, line := range lines {
+		if first && strings.TrimSpace(line) != "" {
+			first = false
+			if rule && markdownRuleEscapeRe.MatchString(line) {
+				lines[i] = markdownRuleEscapeRe.ReplaceAllString(line, `$1\$2`)
+				continue
+			}
+		}
+		if balanced && isMarkdownFence(line) {
+			inFence = !inFence
+			continue
+		}
+		if !inFence {
+			lines[i] = markdownEscapeRe.ReplaceAllString(line, `$1\$2`)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// unescapeMarkdown reverses escapeMarkdown
+func unescapeMarkdown(text string, rule bool) string {
+	lines := strings.Split(text, "\n")
+	inFence, first := false, true
+	for i, line := range lines {
+		if first && strings.TrimSpace(line) != "" {
+			first = false
+			if rule && markdownRuleUnescapeRe.MatchString(line) {
+				lines[i] = markdownRuleUnescapeRe.ReplaceAllString(line, "$1$2")
+				continue
+			}
+		}
+		if isMarkdownFence(line) {
+			inFence = !inFence
+			continue
+		}
+		if !inFence {
+			lines[i] = markdownUnescapeRe.ReplaceAllString(line, "$1$2")
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// renderPlannerMarkdown renders a planner as the Markdown document that ParsePlannerMarkdown reads back
+func renderPlannerMarkdown(planner *Planner) string {
+	var b strings.Builder
+
+	// Columns are declared in front matter
+	b.WriteString(markdownPlannerFrontMatter(planner))
+	b.WriteString("# " + planner.Title + "\n\n")
+	if planner.Description != "" {
+		b.WriteString(escapeMarkdown(planner.Description, false) + "\n\n")
+	}
+
+	// Add each lane and its cards
+	for _, lane := range planner.Lanes {
+		b.WriteString("## " + lane.Title + "\n\n")
+		if lane.Description != "" {
+			b.WriteString(escapeMarkdown(lane.Description, false) + "\n\n")
+		}
+
+		for _, card := range lane.Cards {
+			title, _ := card.Fields[FieldTitle].(string)
+			b.WriteString("### " + title + "\n\n")
+			b.WriteString(markdownCardFrontMatter(planner, &card))
+			if content, ok := card.Fields[FieldContent].(string); ok && content != "" {
+				b.WriteString(escapeMarkdown(content, true) + "\n\n")
+			}
+			// Checklists are written as GitHub task lists
+			if len(card.Checklist) > 0 {
+				for _, item := range card.Checklist {
+					box := "[ ]"
+					if item.Done {
+						box = "[x]"
+					}
+					b.WriteString("- " + box + " " + item.Text + "\n")
+				}
+				b.WriteString("\n")
+			}
+		}
+	}
+	return b.String()
+}
+
+// formatFrontMatterValue renders a value so that parseFrontMatterValue reads it back unchanged
+func formatFrontMatterValue(value interface{}) string {
+	switch v := value.(type) {
+	case []string:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = formatFrontMatterValue(item)
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case string:
+		if v == "" || strings.TrimSpace(v) != v || strings.ContainsAny(v, ":#[],\"'\n") {
+			return strconv.Quote(v)
+		}
+		return v
+	case time.Time:
+		return v.UTC().Format(time.RFC3339)
+	}
+	if n, ok := toFloat(value); ok {
+		return strconv.FormatFloat(n, 'f', -1, 64)
+	}
+	return formatFrontMatterValue(fmt.Sprint(value))
+}
+
+// markdownPlannerFrontMatter renders the column declarations of a planner
+func markdownPlannerFrontMatter(planner *Planner) string {
+	if len(planner.Columns) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("---\ncolumns:\n")
+	for _, col := range planner.Columns {
+		b.WriteString("  - name: " + formatFrontMatterValue(col.Name) + "\n")
+		b.WriteString("    type: " + col.Type + "\n")
+		if len(col.Options) > 0 {
+			b.WriteString("    options: " + formatFrontMatterValue(col.Options) + "\n")
+		}
+	}
+	b.WriteString("---\n\n")
+	return b.String()
+}
+
+// markdownCardFrontMatter renders a card's column values and attributes, keyed by column name
+func markdownCardFrontMatter(planner *Planner, card *PlannerCard) string {
+	lines := []string{}
+	for _, col := range planner.Columns {
+		if value, ok := card.Fields[col.ID]; ok && value != nil {
+			lines = append(lines, formatFrontMatterValue(col.Name)+": "+formatFrontMatterValue(value))
+		}
+	}
+	if len(card.Assignees) > 0 {
+		lines = append(lines, "assignees: "+formatFrontMatterValue(card.Assignees))
+	}
+	if len(card.Labels) > 0 {
+		lines = append(lines, "labels: "+formatFrontMatterValue(card.Labels))
+	}
+	if card.Priority != "" {
+		lines = append(lines, "priority: "+card.Priority)
+	}
+	if card.DueDate != nil {
+		lines = append(lines, "due_date: "+formatFrontMatterValue(*card.DueDate))
+	}
+	if card.StartDate != nil {
+		lines = append(lines, "start_date: "+formatFrontMatterValue(*card.StartDate))
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+	return "---\n" + strings.Join(lines, "\n") + "\n---\n\n"
+}
+
+// ImportPlannerFromMarkdown creates a planner owned by userID from a Markdown document
+func ImportPlannerFromMarkdown(ctx context.Context, markdown, templateID, userID string) (*Planner, *ImportReport, error) {
+	log.Printf("Importing planner from Markdown with templateID=%s, userID=%s", templateID, userID)
+
+	planner, report := ParsePlannerMarkdown(markdown, userID)
+	planner.TemplateID = templateID
+
+	if _, err := plannerCollection.InsertOne(ctx, planner); err != nil {
+		return nil, nil, fmt.Errorf("failed to insert planner: %w", err)
+	}
+	recordImportedTransitions(ctx, planner.ID, planner.Lanes)
+	return planner, report, nil
+}
@@ -0,0 +1,133 @@
+package planner
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func markdownFixture() *Planner {
+	due := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	return &Planner{
+		Title:       "Launch",
+		Description: "Plan for the launch.\n\n## Not a lane\n\n- [ ] not a checklist item",
+		Columns: []PlannerColumn{
+			{ID: "status", Name: "Status", Type: ColumnTypeStatus, Options: []string{"Open", "Done: really"}},
+			{ID: "points", Name: "Story Points", Type: ColumnTypeNumber},
+		},
+		Lanes: []PlannerLane{
+			{ID: "l1", Title: "To Do", Description: "# Also not a title", Cards: []PlannerCard{
+				{ID: "c1", Labels: []string{"bug", "needs, review"}, Priority: PriorityHigh, DueDate: &due,
+					Fields: map[string]interface{}{
+						FieldTitle:   "Write C#",
+						FieldContent: "---\nStarts with a rule.\n\n### Not a card\n\\## Backslash kept\n```\n## code stays\n- [ ] as is\n```",
+						"status":     "Done: really",
+						"points":     3.0,
+					},
+					Checklist: []ChecklistItem{{Text: "Draft"}, {Text: "Review", Done: true}},
+				},
+				{ID: "c2", Fields: map[string]interface{}{FieldTitle: "Open fence", FieldContent: "```go\nfunc main() {}\n## still content"}},
+			}},
+			{ID: "l2", Title: "Done", Cards: []PlannerCard{
+				{ID: "c3", Fields: map[string]interface{}{FieldTitle: "Ruled", FieldContent: "---"}},
+			}},
+		},
+	}
+}
+
+func TestMarkdownRoundTrip(t *testing.T) {
+	original := markdownFixture()
+	parsed, report := ParsePlannerMarkdown(renderPlannerMarkdown(original), "u1")
+	if len(report.Warnings) != 0 {
+		t.Errorf("unexpected warnings: %+v", report.Warnings)
+	}
+	if report.Lanes != 2 || report.Cards != 3 || report.Columns != 2 || report.ChecklistItems != 2 {
+		t.Errorf("unexpected report %+v", report)
+	}
+
+	if parsed.Title != original.Title || parsed.Description != original.Description {
+		t.Errorf("planner title or description changed: %q, %q", parsed.Title, parsed.Description)
+	}
+	if len(parsed.Columns) != 2 || parsed.Columns[0].Name != "Status" || !reflect.DeepEqual(parsed.Columns[0].Options, original.Columns[0].Options) {
+		t.Fatalf("unexpected columns %+v", parsed.Columns)
+	}
+	if len(parsed.Lanes) != 2 {
+		t.Fatalf("expected 2 lanes, got %d", len(parsed.Lanes))
+	}
+	if parsed.Lanes[0].Description != original.Lanes[0].Description || len(parsed.Lanes[0].Cards) != 2 || len(parsed.Lanes[1].Cards) != 1 {
+		t.Fatalf("unexpected lanes %+v", parsed.Lanes)
+	}
+
+	for li, lane := range original.Lanes {
+		for ci, want := range lane.Cards {
+			got := parsed.Lanes[li].Cards[ci]
+			for _, key := range []string{FieldTitle, FieldContent} {
+				if got.Fields[key] != want.Fields[key] {
+					t.Errorf("card %s %s: expected %q, got %q", want.ID, key, want.Fields[key], got.Fields[key])
+				}
+			}
+		}
+	}
+	card := parsed.Lanes[0].Cards[0]
+	if card.Fields[parsed.Columns[0].ID] != "Done: really" || card.Fields[parsed.Columns[1].ID] != 3.0 {
+		t.Errorf("unexpected column values %v", card.Fields)
+	}
+	if !reflect.DeepEqual(card.Labels, []string{"bug", "needs, review"}) || card.Priority != PriorityHigh || card.DueDate == nil || !card.DueDate.Equal(*original.Lanes[0].Cards[0].DueDate) {
+		t.Errorf("unexpected card attributes %+v", card)
+	}
+	if len(card.Checklist) != 2 || card.Checklist[1].Text != "Review" || !card.Checklist[1].Done || card.Progress != 50 {
+		t.Errorf("unexpected checklist %+v", card.Checklist)
+	}
+}
+
+func TestEscapeMarkdown(t *testing.T) {
+	cases := []struct {
+		text, want string
+		rule       bool
+	}{
+		{"## heading", `\## heading`, false},
+		{"#### deep heading", "#### deep heading", false},
+		{"#hashtag", "#hashtag", false},
+		{"  - [x] done", `  \- [x] done`, false},
+		{"- plain item", "- plain item", false},
+		{"```\n## code\n```", "```\n## code\n```", false},
+		{"```\n## code", "\\```\n\\## code", false},
+		{"\\```", "\\\\```", false},
+		{"\n---\ntext\n---", "\n\\---\ntext\n---", true},
+		{"---", "---", false},
+	}
+	for _, c := range cases {
+		got := escapeMarkdown(c.text, c.rule)
+		if got != c.want {
+			t.Errorf("%q: expected %q, got %q", c.text, c.want, got)
+		}
+		if back := unescapeMarkdown(got, c.rule); back != c.text {
+			t.Errorf("%q: unescaped to %q", c.text, back)
+		}
+	}
+}
+
+func TestParsePlannerMarkdownReport(t *testing.T) {
+	doc := strings.Join([]string{
+		"intro before the title",
+		"# Board",
+		"### Orphan card",
+		"---",
+		"priority: someday",
+		"Owner: bob",
+		"---",
+		"## Lane",
+		"# Second title",
+	}, "\n")
+	planner, report := ParsePlannerMarkdown(doc, "u1")
+	if planner.Title != "Board" || len(planner.Lanes) != 2 || planner.Lanes[0].Title != "Imported" {
+		t.Fatalf("unexpected planner %+v", planner)
+	}
+	if planner.Lanes[1].Description != "# Second title" {
+		t.Errorf("expected a second title to be kept as text, got %q", planner.Lanes[1].Description)
+	}
+	if len(report.Warnings) != 5 {
+		t.Errorf("expected 5 warnings, got %+v", report.Warnings)
+	}
+}
@@ -0,0 +1,134 @@
+package planner
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// ErrNotPlannerMember is returned when a user is referenced on a planner they don't belong to
+var ErrNotPlannerMember = errors.New("not a planner member")
+
+// ErrForbidden is returned when the caller may not perform an action on a planner
+var ErrForbidden = errors.New("forbidden")
+
+// plannerAccessFilter matches planners the user owns or is a member of
+func plannerAccessFilter(userID string) bson.M {
+	return bson.M{"$or": []bson.M{
+		{"user_id": userID},
+		{"members": userID},
+	}}
+}
+
+// PlannerMemberIDs returns the owner and members of a planner without duplicates
+func PlannerMemberIDs(p *Planner) []string {
+	ids := []string{}
+	seen := map[string]bool{}
+	for _, id := range append([]string{p.UserID}, p.Members...) {
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// IsPlannerMember reports whether a user owns or is a member of a planner
+func IsPlannerMember(p *Planner, userID string) bool {
+	if userID == "" {
+		return false
+	}
+	return containsString(PlannerMemberIDs(p), userID)
+}
+
+// SetPlannerMembers replaces the member list of a planner; only the owner may change it
+func SetPlannerMembers(ctx context.Context, plannerID string, members []string) ([]string, error) {
+	log.Printf("Setting planner members: plannerID=%s, memberCount=%d", plannerID, len(members))
+
+	planner, err := GetPlanner(ctx, plannerID)
+	if err != nil {
+		return nil, err
+	}
+	actorID, _ := ctx.Value("user_id").(string)
+	if planner.UserID != "" && actorID != planner.UserID {
+		return nil, fmt.Errorf("%w: only the planner owner can change members", ErrForbidden)
+	}
+
+	cleaned := []string{}
+	for _, m := range members {
+		m = strings.TrimSpace(m)
+		if m == "" || m == planner.UserID || containsString(cleaned, m) {
+			continue
+		}
+		cleaned = append(cleaned, m)
+	}
+
+	_, err = plannerCollection.UpdateOne(ctx,
+		bson.M{"id": plannerID},
+		bson.M{"$set": bson.M{"members": cleaned, "updated_at": time.Now()}},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	planner.Members = cleaned
+	publishEvent(ctx, plannerID, EventMembersUpdated, bson.M{"members": cleaned})
+	return PlannerMemberIDs(planner), nil
+}
+
+// HandlePlannerMembers handles GET and PUT /planner/{id}/members
+func HandlePlannerMembers(w http.ResponseWriter, r *http.Request) {
+	// Extract planner ID from path
+	path := r.URL.Path
+	parts := strings.Split(path, "/")
+	if len(parts) != 4 || parts[1] != "planner" || parts[3] != "members" {
+		http.Error(w, "Invalid path", http.StatusBadRequest)
+		return
+	}
+	plannerID := parts[2]
+
+	var members []string
+	switch r.Method {
+	case http.MethodGet:
+		planner, err := GetPlanner(r.Context(), plannerID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		members = PlannerMemberIDs(planner)
+	case http.MethodPut:
+		var request struct {
+			Members []string `json:"members"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		var err error
+		members, err = SetPlannerMembers(r.Context(), plannerID, request.Members)
+		if err != nil {
+			status := http.StatusInternalServerError
+			if errors.Is(err, ErrForbidden) {
+				status = http.StatusForbidden
+			}
+			http.Error(w, err.Error(), status)
+			return
+		}
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(members); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
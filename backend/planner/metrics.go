@@ -0,0 +1,468 @@
+package planner
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	defaultMetricsDays = 30
+	maxMetricsDays     = 366
+)
+
+// ErrInvalidMetricsRange is returned when a metrics date range is malformed or too long
+var ErrInvalidMetricsRange = errors.New("invalid metrics range")
+
+var cardTransitionCollection *mongo.Collection
+
+// laneCategories lists lane categories in flow order
+var laneCategories = []string{LaneCategoryBacklog, LaneCategoryTodo, LaneCategoryInProgress, LaneCategoryDone}
+
+// CardTransition records a card entering, leaving or moving between lanes.
+// An empty FromLaneID means the card was created; an empty ToLaneID means it was deleted.
+// Categories are those of the lanes at the time of the transition.
+type CardTransition struct {
+	ID           string    `json:"id" bson:"id"`
+	PlannerID    string    `json:"planner_id" bson:"planner_id"`
+	CardID       string    `json:"card_id" bson:"card_id"`
+	FromLaneID   string    `json:"from_lane_id,omitempty" bson:"from_lane_id,omitempty"`
+	FromCategory string    `json:"from_category,omitempty" bson:"from_category,omitempty"`
+	ToLaneID     string    `json:"to_lane_id,omitempty" bson:"to_lane_id,omitempty"`
+	ToCategory   string    `json:"to_category,omitempty" bson:"to_category,omitempty"`
+	ActorID      string    `json:"actor_id,omitempty" bson:"actor_id,omitempty"`
+	Timestamp    time.Time `json:"timestamp" bson:"timestamp"`
+}
+
+// DurationStats summarizes cycle or lead times in hours
+type DurationStats struct {
+	Count int     `json:"count"`
+	Mean  float64 `json:"mean_hours"`
+	P50   float64 `json:"p50_hours"`
+	P75   float64 `json:"p75_hours"`
+	P85   float64 `json:"p85_hours"`
+	P95   float64 `json:"p95_hours"`
+}
+
+// DailyCount is the number of cards finished on a day
+type DailyCount struct {
+	Date  string `json:"date"`
+	Count int    `json:"count"`
+}
+
+// ThroughputStats summarizes the number of cards finished per day
+type ThroughputStats struct {
+	Total int          `json:"total"`
+	P50   float64      `json:"p50"`
+	P75   float64      `json:"p75"`
+	P85   float64      `json:"p85"`
+	P95   float64      `json:"p95"`
+	Daily []DailyCount `json:"daily"`
+}
+
+// CumulativeFlowPoint is the number of cards in each lane category at the end of a day
+type CumulativeFlowPoint struct {
+	Date   string         `json:"date"`
+	Counts map[string]int `json:"counts"`
+}
+
+// PlannerMetrics are the flow metrics of a planner over a date range
+type PlannerMetrics struct {
+	PlannerID      string                `json:"planner_id"`
+	From           string                `json:"from"`
+	To             string                `json:"to"`
+	CycleTime      DurationStats         `json:"cycle_time"`
+	LeadTime       DurationStats         `json:"lead_time"`
+	Throughput     ThroughputStats       `json:"throughput"`
+	CumulativeFlow []CumulativeFlowPoint `json:"cumulative_flow"`
+}
+
+// flowCard is what the metrics need to know about a card that still exists
+type flowCard struct {
+	CreatedAt  time.Time
+	ArchivedAt *time.Time
+	Category   string // category of the lane the card is in now
+}
+
+// recordTransition stores a card's move from one lane to another. A nil from lane means the card was created,
+// a nil to lane that it was deleted. Failures are logged, like activity.
+func recordTransition(ctx context.Context, plannerID, cardID string, from, to *PlannerLane) {
+	if cardTransitionCollection == nil || plannerID == "" {
+		return
+	}
+	actorID, _ := ctx.Value("user_id").(string)
+	transition := CardTransition{
+		ID:        GenerateID(),
+		PlannerID: plannerID,
+		CardID:    cardID,
+		ActorID:   actorID,
+		Timestamp: time.Now(),
+	}
+	if from != nil {
+		transition.FromLaneID = from.ID
+		transition.FromCategory = laneCategory(from)
+	}
+	if to != nil {
+		transition.ToLaneID = to.ID
+		transition.ToCategory = laneCategory(to)
+	}
+	if _, err := cardTransitionCollection.InsertOne(ctx, transition); err != nil {
+		log.Printf("[metrics] failed to record transition of card %s: %v", cardID, err)
+	}
+}
+
+// recordImportedTransitions stores the lanes imported cards start in. They are dated at each card's
+// creation, so cards imported into done lanes aren't counted as finished on the day of the import.
+func recordImportedTransitions(ctx context.Context, plannerID string, lanes []PlannerLane) {
+	if cardTransitionCollection == nil || plannerID == "" {
+		return
+	}
+	actorID, _ := ctx.Value("user_id").(string)
+	now := time.Now()
+	docs := []interface{}{}
+	for i := range lanes {
+		for _, card := range lanes[i].Cards {
+			at := card.CreatedAt
+			if at.IsZero() || at.After(now) {
+				at = now
+			}
+			docs = append(docs, CardTransition{
+				ID:         GenerateID(),
+				PlannerID:  plannerID,
+				CardID:     card.ID,
+				ToLaneID:   lanes[i].ID,
+				ToCategory: laneCategory(&lanes[i]),
+				ActorID:    actorID,
+				Timestamp:  at,
+			})
+		}
+	}
+	if len(docs) == 0 {
+		return
+	}
+	if _, err := cardTransitionCollection.InsertMany(ctx, docs); err != nil {
+		log.Printf("[metrics] failed to record transitions of imported cards in planner %s: %v", plannerID, err)
+	}
+}
+
+// GetCardTransitions returns the lane transitions of a card, oldest first
+func GetCardTransitions(ctx context.Context, cardID string) ([]CardTransition, error) {
+	log.Printf("Getting card transitions: cardID=%s", cardID)
+
+	cur, err := cardTransitionCollection.Find(ctx, bson.M{"card_id": cardID},
+		options.Find().SetSort(bson.D{{Key: "timestamp", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+	transitions := []CardTransition{}
+	if err := cur.All(ctx, &transitions); err != nil {
+		return nil, err
+	}
+	return transitions, nil
+}
+
+// percentile returns the nearest-rank percentile of sorted values
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	i := int(math.Ceil(p/100*float64(len(sorted)))) - 1
+	if i < 0 {
+		i = 0
+	}
+	return sorted[i]
+}
+
+func roundHours(h float64) float64 {
+	return math.Round(h*100) / 100
+}
+
+// durationStats summarizes durations in hours
+func durationStats(hours []float64) DurationStats {
+	if len(hours) == 0 {
+		return DurationStats{}
+	}
+	sort.Float64s(hours)
+	sum := 0.0
+	for _, h := range hours {
+		sum += h
+	}
+	return DurationStats{
+		Count: len(hours),
+		Mean:  roundHours(sum / float64(len(hours))),
+		P50:   roundHours(percentile(hours, 50)),
+		P75:   roundHours(percentile(hours, 75)),
+		P85:   roundHours(percentile(hours, 85)),
+		P95:   roundHours(percentile(hours, 95)),
+	}
+}
+
+// categoryBefore returns the category a card was in just before t, or "" if it wasn't on the board.
+// Archived cards leave the flow unless they are done.
+func categoryBefore(card *flowCard, transitions []CardTransition, created, t time.Time) string {
+	if !created.Before(t) || (card == nil && len(transitions) == 0) {
+		return ""
+	}
+	category := ""
+	switch {
+	case len(transitions) == 0:
+		category = card.Category
+	case transitions[0].FromLaneID == "":
+		// Created through a tracked path, so the first transition is where the card started
+		category = transitions[0].ToCategory
+	default:
+		category = transitions[0].FromCategory
+	}
+	for _, tr := range transitions {
+		if !tr.Timestamp.Before(t) {
+			break
+		}
+		category = tr.ToCategory
+	}
+	if card != nil && card.ArchivedAt != nil && card.ArchivedAt.Before(t) && category != LaneCategoryDone {
+		return ""
+	}
+	return category
+}
+
+// computeMetrics calculates flow metrics for the days from through to (inclusive, both at midnight UTC).
+// cards holds the cards that still exist; transitions must be sorted by timestamp.
+//
+// A card is finished when it enters a done lane from a lane that isn't done; only its last finish in the range counts.
+// Lead time runs from creation to finish, cycle time from first entering an in-progress lane to finish.
+func computeMetrics(cards map[string]flowCard, transitions []CardTransition, from, to time.Time) *PlannerMetrics {
+	end := to.AddDate(0, 0, 1)
+	byCard := map[string][]CardTransition{}
+	for _, tr := range transitions {
+		byCard[tr.CardID] = append(byCard[tr.CardID], tr)
+	}
+	cardIDs := map[string]bool{}
+	for id := range cards {
+		cardIDs[id] = true
+	}
+	for id := range byCard {
+		cardIDs[id] = true
+	}
+
+	days := int(end.Sub(from).Hours() / 24)
+	daily := make([]int, days)
+	cfd := make([]map[string]int, days)
+	for i := range cfd {
+		cfd[i] = map[string]int{}
+		for _, c := range laneCategories {
+			cfd[i][c] = 0
+		}
+	}
+
+	var leadTimes, cycleTimes []float64
+	for id := range cardIDs {
+		history := byCard[id]
+		var card *flowCard
+		if c, ok := cards[id]; ok {
+			card = &c
+		}
+		var created time.Time
+		switch {
+		case card != nil:
+			created = card.CreatedAt
+		case len(history) > 0:
+			created = history[0].Timestamp
+		}
+		if card != nil && len(history) > 0 && history[0].Timestamp.Before(created) {
+			created = history[0].Timestamp
+		}
+
+		var started, finished time.Time
+		for _, tr := range history {
+			if started.IsZero() && tr.ToCategory == LaneCategoryInProgress {
+				started = tr.Timestamp
+			}
+			if tr.ToCategory == LaneCategoryDone && tr.FromCategory != LaneCategoryDone &&
+				!tr.Timestamp.Before(from) && tr.Timestamp.Before(end) {
+				finished = tr.Timestamp
+			}
+		}
+		if !finished.IsZero() {
+			daily[int(finished.Sub(from).Hours()/24)]++
+			leadTimes = append(leadTimes, finished.Sub(created).Hours())
+			if !started.IsZero() && !started.After(finished) {
+				cycleTimes = append(cycleTimes, finished.Sub(started).Hours())
+			}
+		}
+
+		for i := range cfd {
+			if category := categoryBefore(card, history, created, from.AddDate(0, 0, i+1)); category != "" {
+				cfd[i][category]++
+			}
+		}
+	}
+
+	metrics := &PlannerMetrics{
+		From:           from.Format("2006-01-02"),
+		To:             to.Format("2006-01-02"),
+		CycleTime:      durationStats(cycleTimes),
+		LeadTime:       durationStats(leadTimes),
+		Throughput:     ThroughputStats{Daily: []DailyCount{}},
+		CumulativeFlow: []CumulativeFlowPoint{},
+	}
+	counts := []float64{}
+	for i, n := range daily {
+		date := from.AddDate(0, 0, i).Format("2006-01-02")
+		metrics.Throughput.Total += n
+		metrics.Throughput.Daily = append(metrics.Throughput.Daily, DailyCount{Date: date, Count: n})
+		metrics.CumulativeFlow = append(metrics.CumulativeFlow, CumulativeFlowPoint{Date: date, Counts: cfd[i]})
+		counts = append(counts, float64(n))
+	}
+	sort.Float64s(counts)
+	metrics.Throughput.P50 = percentile(counts, 50)
+	metrics.Throughput.P75 = percentile(counts, 75)
+	metrics.Throughput.P85 = percentile(counts, 85)
+	metrics.Throughput.P95 = percentile(counts, 95)
+	return metrics
+}
+
+// metricsRange parses the from and to dates of a metrics request, defaulting to the last 30 days
+func metricsRange(fromParam, toParam string, now time.Time) (time.Time, time.Time, error) {
+	day := func(t time.Time) time.Time {
+		t = t.UTC()
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	}
+	to := day(now)
+	if toParam != "" {
+		t, err := parseDate(toParam)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("%w: to must be a date", ErrInvalidMetricsRange)
+		}
+		to = day(t)
+	}
+	from := to.AddDate(0, 0, 1-defaultMetricsDays)
+	if fromParam != "" {
+		t, err := parseDate(fromParam)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("%w: from must be a date", ErrInvalidMetricsRange)
+		}
+		from = day(t)
+	}
+	if from.After(to) {
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: from is after to", ErrInvalidMetricsRange)
+	}
+	if to.Sub(from).Hours()/24 >= maxMetricsDays {
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: at most %d days", ErrInvalidMetricsRange, maxMetricsDays)
+	}
+	return from, to, nil
+}
+
+// GetPlannerMetrics returns the flow metrics of a planner for the days from through to
+func GetPlannerMetrics(ctx context.Context, plannerID string, from, to time.Time) (*PlannerMetrics, error) {
+	log.Printf("Getting planner metrics: plannerID=%s, from=%s, to=%s", plannerID, from.Format("2006-01-02"), to.Format("2006-01-02"))
+
+	planner, err := loadPlanner(ctx, plannerID, true)
+	if err != nil {
+		return nil, err
+	}
+	cards := map[string]flowCard{}
+	for i := range planner.Lanes {
+		for _, card := range planner.Lanes[i].Cards {
+			cards[card.ID] = flowCard{CreatedAt: card.CreatedAt, ArchivedAt: card.ArchivedAt, Category: laneCategory(&planner.Lanes[i])}
+		}
+	}
+
+	cur, err := cardTransitionCollection.Find(ctx,
+		bson.M{"planner_id": plannerID, "timestamp": bson.M{"$lt": to.AddDate(0, 0, 1)}},
+		options.Find().SetSort(bson.D{{Key: "timestamp", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+	var transitions []CardTransition
+	if err := cur.All(ctx, &transitions); err != nil {
+		return nil, err
+	}
+
+	metrics := computeMetrics(cards, transitions, from, to)
+	metrics.PlannerID = plannerID
+	return metrics, nil
+}
+
+// metricsErrorStatus maps metrics errors to HTTP status codes
+func metricsErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, ErrInvalidMetricsRange):
+		return http.StatusBadRequest
+	case errors.Is(err, mongo.ErrNoDocuments):
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
+
+// HandlePlannerMetrics handles GET /planner/{id}/metrics?from=&to=
+func HandlePlannerMetrics(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Extract planner ID from path
+	path := r.URL.Path
+	parts := strings.Split(path, "/")
+	if len(parts) != 4 || parts[1] != "planner" || parts[3] != "metrics" {
+		http.Error(w, "Invalid path", http.StatusBadRequest)
+		return
+	}
+	plannerID := parts[2]
+
+	from, to, err := metricsRange(r.URL.Query().Get("from"), r.URL.Query().Get("to"), time.Now())
+	if err != nil {
+		http.Error(w, err.Error(), metricsErrorStatus(err))
+		return
+	}
+	metrics, err := GetPlannerMetrics(r.Context(), plannerID, from, to)
+	if err != nil {
+		http.Error(w, err.Error(), metricsErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(metrics); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// HandleCardTransitions handles GET /planner/{id}/card/{cardId}/transitions
+func HandleCardTransitions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Extract card ID from path
+	path := r.URL.Path
+	parts := strings.Split(path, "/")
+	if len(parts) != 6 || parts[1] != "planner" || parts[3] != "card" || parts[5] != "transitions" {
+		http.Error(w, "Invalid path", http.StatusBadRequest)
+		return
+	}
+
+	transitions, err := GetCardTransitions(r.Context(), parts[4])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(transitions); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
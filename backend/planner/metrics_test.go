@@ -0,0 +1,112 @@
+package planner
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestInferLaneCategory(t *testing.T) {
+	cases := map[string]string{
+		"Done":        LaneCategoryDone,
+		"In Progress": LaneCategoryInProgress,
+		"Code review": LaneCategoryInProgress,
+		"Backlog":     LaneCategoryBacklog,
+		"To Do":       LaneCategoryTodo,
+		"":            LaneCategoryTodo,
+		"in-progress": LaneCategoryInProgress,
+		"QA/Testing":  LaneCategoryInProgress,
+		"Undone":      LaneCategoryTodo,
+		"Quality":     LaneCategoryTodo,
+		"Preview":     LaneCategoryTodo,
+		"Reactive":    LaneCategoryTodo,
+	}
+	for title, want := range cases {
+		if got := inferLaneCategory(title); got != want {
+			t.Errorf("%q: expected %s, got %s", title, want, got)
+		}
+	}
+	if got := laneCategory(&PlannerLane{Title: "Done", Category: LaneCategoryTodo}); got != LaneCategoryTodo {
+		t.Errorf("explicit category should win, got %s", got)
+	}
+}
+
+func TestComputeMetrics(t *testing.T) {
+	day := func(d, h int) time.Time { return time.Date(2024, 3, d, h, 0, 0, 0, time.UTC) }
+	move := func(card, from, to string, at time.Time) CardTransition {
+		tr := CardTransition{CardID: card, FromCategory: from, ToCategory: to, Timestamp: at}
+		if from != "" {
+			tr.FromLaneID = from + "-lane"
+		}
+		if to != "" {
+			tr.ToLaneID = to + "-lane"
+		}
+		return tr
+	}
+
+	cards := map[string]flowCard{
+		"a": {CreatedAt: day(1, 9), Category: LaneCategoryDone},
+		"b": {CreatedAt: day(1, 9), Category: LaneCategoryDone},
+		"c": {CreatedAt: day(2, 9), Category: LaneCategoryTodo},
+		// Created before transitions were recorded
+		"old": {CreatedAt: day(1, 0), Category: LaneCategoryBacklog},
+	}
+	transitions := []CardTransition{
+		move("a", "", LaneCategoryTodo, day(1, 9)),
+		move("b", "", LaneCategoryTodo, day(1, 9)),
+		move("gone", "", LaneCategoryTodo, day(1, 12)),
+		move("a", LaneCategoryTodo, LaneCategoryInProgress, day(2, 9)),
+		move("c", "", LaneCategoryTodo, day(2, 9)),
+		move("a", LaneCategoryInProgress, LaneCategoryDone, day(3, 9)),
+		move("b", LaneCategoryTodo, LaneCategoryDone, day(3, 21)),
+		move("gone", LaneCategoryTodo, "", day(3, 12)),
+	}
+
+	m := computeMetrics(cards, transitions, day(1, 0), day(3, 0))
+	if m.From != "2024-03-01" || m.To != "2024-03-03" {
+		t.Errorf("unexpected range %s..%s", m.From, m.To)
+	}
+	if m.LeadTime.Count != 2 || m.LeadTime.P50 != 48 || m.LeadTime.P95 != 60 {
+		t.Errorf("unexpected lead time: %+v", m.LeadTime)
+	}
+	// b skipped in-progress, so only a has a cycle time
+	if m.CycleTime.Count != 1 || m.CycleTime.Mean != 24 {
+		t.Errorf("unexpected cycle time: %+v", m.CycleTime)
+	}
+	if m.Throughput.Total != 2 || len(m.Throughput.Daily) != 3 || m.Throughput.Daily[2].Count != 2 || m.Throughput.P95 != 2 {
+		t.Errorf("unexpected throughput: %+v", m.Throughput)
+	}
+
+	if len(m.CumulativeFlow) != 3 {
+		t.Fatalf("expected 3 days of cumulative flow, got %d", len(m.CumulativeFlow))
+	}
+	want := []map[string]int{
+		{LaneCategoryBacklog: 1, LaneCategoryTodo: 3, LaneCategoryInProgress: 0, LaneCategoryDone: 0},
+		{LaneCategoryBacklog: 1, LaneCategoryTodo: 3, LaneCategoryInProgress: 1, LaneCategoryDone: 0},
+		{LaneCategoryBacklog: 1, LaneCategoryTodo: 1, LaneCategoryInProgress: 0, LaneCategoryDone: 2},
+	}
+	for i, w := range want {
+		for category, n := range w {
+			if got := m.CumulativeFlow[i].Counts[category]; got != n {
+				t.Errorf("day %d %s: expected %d, got %d", i+1, category, n, got)
+			}
+		}
+	}
+}
+
+func TestMetricsRange(t *testing.T) {
+	now := time.Date(2024, 3, 31, 15, 0, 0, 0, time.UTC)
+	from, to, err := metricsRange("", "", now)
+	if err != nil || to.Format("2006-01-02") != "2024-03-31" || from.Format("2006-01-02") != "2024-03-02" {
+		t.Errorf("unexpected default range %v..%v, %v", from, to, err)
+	}
+	if _, _, err := metricsRange("2024-03-10", "2024-03-01", now); !errors.Is(err, ErrInvalidMetricsRange) {
+		t.Errorf("expected ErrInvalidMetricsRange for reversed range, got %v", err)
+	}
+	if _, _, err := metricsRange("2022-01-01", "2024-01-01", now); !errors.Is(err, ErrInvalidMetricsRange) {
+		t.Errorf("expected ErrInvalidMetricsRange for long range, got %v", err)
+	}
+	if _, _, err := metricsRange("soon", "", now); !errors.Is(err, ErrInvalidMetricsRange) {
+		t.Errorf("expected ErrInvalidMetricsRange for bad date, got %v", err)
+	}
+}
@@ -3,22 +3,26 @@ package planner
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"sort"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 // Planner represents a Markdown-based planning board
 type Planner struct {
 	ID          string          `json:"id" bson:"id"`
 	UserID      string          `json:"user_id" bson:"user_id"` // added user ownership
+	Members     []string        `json:"members,omitempty" bson:"members,omitempty"`
 	Title       string          `json:"title" bson:"title"`
 	Description string          `json:"description" bson:"description"`
 	TemplateID  string          `json:"template_id" bson:"template_id"`
@@ -26,6 +30,18 @@ type Planner struct {
 	UpdatedAt   time.Time       `json:"updated_at" bson:"updated_at"`
 	Lanes       []PlannerLane   `json:"lanes" bson:"lanes"`
 	Columns     []PlannerColumn `json:"columns" bson:"columns"`
+	Settings    PlannerSettings `json:"settings" bson:"settings"`
+	Rules       []PlannerRule   `json:"rules,omitempty" bson:"rules,omitempty"`
+	Sprints     []Sprint        `json:"sprints,omitempty" bson:"sprints,omitempty"`
+
+	SwimlaneGroupBy string     `json:"swimlane_group_by,omitempty" bson:"swimlane_group_by,omitempty"` // default grouping of GetPlanner
+	GroupBy         string     `json:"group_by,omitempty" bson:"-"`
+	Swimlanes       []Swimlane `json:"swimlanes,omitempty" bson:"-"` // cards bucketed by swimlane x lane when grouped
+
+	// Set when cards were filtered or sorted, see GetPlannerView
+	ViewID string `json:"view_id,omitempty" bson:"-"`
+	Filter string `json:"filter,omitempty" bson:"-"`
+	Sort   string `json:"sort,omitempty" bson:"-"`
 }
 
 // PlannerLane represents a lane in a planner
@@ -36,9 +52,14 @@ type PlannerLane struct {
 	Title          string        `json:"title" bson:"title"`
 	Description    string        `json:"description" bson:"description"`
 	Color          string        `json:"color" bson:"color"`
+	Category       string        `json:"category,omitempty" bson:"category,omitempty"`   // backlog, todo, in-progress or done
+	WIPLimit       int           `json:"wip_limit,omitempty" bson:"wip_limit,omitempty"` // maximum number of cards, 0 for none
+	WIPCount       int           `json:"wip_count,omitempty" bson:"-"`                   // cards counted against the limit
+	OverLimit      bool          `json:"over_limit,omitempty" bson:"-"`
 	Position       int           `json:"position" bson:"position"`
 	CreatedAt      time.Time     `json:"created_at" bson:"created_at"`
 	UpdatedAt      time.Time     `json:"updated_at" bson:"updated_at"`
+	ArchivedAt     *time.Time    `json:"archived_at,omitempty" bson:"archived_at,omitempty"`
 	Cards          []PlannerCard `json:"cards" bson:"cards"`
 }
 
@@ -47,16 +68,35 @@ type PlannerCard struct {
 	ID        string                 `json:"id" bson:"id"`
 	LaneID    string                 `json:"lane_id" bson:"lane_id"`
 	Fields    map[string]interface{} `json:"fields" bson:"fields"` // columnID -> value
+	Assignees []string               `json:"assignees,omitempty" bson:"assignees,omitempty"`
+	DueDate   *time.Time             `json:"due_date,omitempty" bson:"due_date,omitempty"`
+	StartDate *time.Time             `json:"start_date,omitempty" bson:"start_date,omitempty"`
+	Priority  string                 `json:"priority,omitempty" bson:"priority,omitempty"`
+	Labels    []string               `json:"labels,omitempty" bson:"labels,omitempty"`
+	Checklist []ChecklistItem        `json:"checklist,omitempty" bson:"checklist,omitempty"`
+	Progress  int                    `json:"progress" bson:"-"` // percentage of checklist items done
 	Position  int                    `json:"position" bson:"position"`
 	CreatedAt time.Time              `json:"created_at" bson:"created_at"`
 	UpdatedAt time.Time              `json:"updated_at" bson:"updated_at"`
+	Revision  int                    `json:"revision" bson:"revision"` // incremented on every update, used as the iCalendar SEQUENCE
+
+	ArchivedAt   *time.Time `json:"archived_at,omitempty" bson:"archived_at,omitempty"`
+	RecurrenceID string     `json:"recurrence_id,omitempty" bson:"recurrence_id,omitempty"` // recurrence that created the card
+	SprintID     string     `json:"sprint_id,omitempty" bson:"sprint_id,omitempty"`
+
+	Blocked   bool     `json:"blocked,omitempty" bson:"-"`    // blocked by a card that isn't done yet
+	BlockedBy []string `json:"blocked_by,omitempty" bson:"-"` // IDs of the cards still blocking this one
+
+	WIPWarning *WIPLimitError `json:"wip_warning,omitempty" bson:"-"` // limit the change exceeded, in warn mode
 }
 
+// PlannerColumn represents a typed custom field shared by all cards of a planner
 type PlannerColumn struct {
 	ID        string    `json:"id" bson:"id"`
 	PlannerID string    `json:"planner_id" bson:"planner_id"`
 	Name      string    `json:"name" bson:"name"`
-	Type      string    `json:"type" bson:"type"` // text, status, number, date, user
+	Type      string    `json:"type" bson:"type"`                           // text, status, number, date, user
+	Options   []string  `json:"options,omitempty" bson:"options,omitempty"` // allowed values for status columns
 	Position  int       `json:"position" bson:"position"`
 	CreatedAt time.Time `json:"created_at" bson:"created_at"`
 	UpdatedAt time.Time `json:"updated_at" bson:"updated_at"`
@@ -68,6 +108,122 @@ var plannerCollection *mongo.Collection
 // Initialize sets up the MongoDB collection for the planner package
 func Initialize(client *mongo.Client, dbName string) {
 	plannerCollection = client.Database(dbName).Collection("planners")
+	commentCollection = client.Database(dbName).Collection("planner_comments")
+	cardHistoryCollection = client.Database(dbName).Collection("card_history")
+	mentionCollection = client.Database(dbName).Collection("mentions")
+	calendarTokenCollection = client.Database(dbName).Collection("calendar_tokens")
+	ruleFiringCollection = client.Database(dbName).Collection("rule_firings")
+	recurrenceCollection = client.Database(dbName).Collection("card_recurrences")
+	recurrenceOccurrenceCollection = client.Database(dbName).Collection("card_recurrence_occurrences")
+	cardLinkCollection = client.Database(dbName).Collection("card_links")
+	cardTransitionCollection = client.Database(dbName).Collection("card_transitions")
+	viewCollection = client.Database(dbName).Collection("planner_views")
+}
+
+// CreateIndexes creates the indexes used by planner queries
+func CreateIndexes(ctx context.Context) error {
+	_, err := plannerCollection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "id", Value: 1}}},
+		{Keys: bson.D{{Key: "user_id", Value: 1}}},
+		{Keys: bson.D{{Key: "members", Value: 1}}},
+		{Keys: bson.D{{Key: "lanes.cards.id", Value: 1}}},
+		{Keys: bson.D{{Key: "lanes.cards.assignees", Value: 1}}},
+		{Keys: bson.D{{Key: "lanes.cards.due_date", Value: 1}}},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = commentCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "card_id", Value: 1}, {Key: "created_at", Value: 1}},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = mentionCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = cardHistoryCollection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "planner_id", Value: 1}, {Key: "timestamp", Value: -1}}},
+		{Keys: bson.D{{Key: "planner_id", Value: 1}, {Key: "actor_id", Value: 1}, {Key: "timestamp", Value: -1}}},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = calendarTokenCollection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "token", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "user_id", Value: 1}}, Options: options.Index().SetUnique(true)},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = ruleFiringCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "rule_id", Value: 1}, {Key: "card_id", Value: 1}, {Key: "due_date", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = recurrenceCollection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "id", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "planner_id", Value: 1}}},
+		{Keys: bson.D{{Key: "enabled", Value: 1}, {Key: "next_occurrence", Value: 1}}},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = recurrenceOccurrenceCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "recurrence_id", Value: 1}, {Key: "occurrence", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = cardLinkCollection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "id", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "source_card_id", Value: 1}, {Key: "type", Value: 1}}},
+		{Keys: bson.D{{Key: "target_card_id", Value: 1}, {Key: "type", Value: 1}}},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = cardTransitionCollection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "planner_id", Value: 1}, {Key: "timestamp", Value: 1}}},
+		{Keys: bson.D{{Key: "card_id", Value: 1}, {Key: "timestamp", Value: 1}}},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = viewCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "planner_id", Value: 1}, {Key: "user_id", Value: 1}},
+	})
+	if err != nil {
+		return err
+	}
+
+	// Built-in templates are installed by every instance; the unique index keeps one copy of each
+	templateCollection := plannerCollection.Database().Collection("planner_templates")
+	if err := removeDuplicateBuiltinTemplates(ctx, templateCollection); err != nil {
+		return err
+	}
+	_, err = templateCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "scope", Value: 1}, {Key: "slug", Value: 1}},
+		Options: options.Index().SetUnique(true).
+			SetPartialFilterExpression(bson.M{"scope": TemplateScopeBuiltIn}),
+	})
+	return err
 }
 
 // CreatePlanner creates a new planner document in MongoDB
@@ -84,6 +240,15 @@ func CreatePlanner(ctx context.Context, title, description, templateID string, u
 		CreatedAt:   now,
 		UpdatedAt:   now,
 		Lanes:       []PlannerLane{},
+		Columns:     []PlannerColumn{},
+	}
+
+	if templateID != "" {
+		template, err := GetTemplate(ctx, templateID)
+		if err != nil {
+			return nil, fmt.Errorf("unknown template_id: %w", err)
+		}
+		instantiateTemplate(planner, template)
 	}
 
 	_, err := plannerCollection.InsertOne(ctx, planner)
@@ -96,17 +261,58 @@ func CreatePlanner(ctx context.Context, title, description, templateID string, u
 
 
 
- // GetPlanner retrieves a planner by ID with all its lanes, cards, and columns
+ // GetPlanner retrieves a planner by ID with all its lanes, cards, and columns.
+ // Archived cards and lanes are left out.
  func GetPlanner(ctx context.Context, id string) (*Planner, error) {
+ 	return loadPlanner(ctx, id, false)
+ }
+
+ // loadPlanner retrieves a planner by ID, optionally keeping archived cards and lanes
+ func loadPlanner(ctx context.Context, id string, includeArchived bool) (*Planner, error) {
+ 	return queryPlanner(ctx, id, includeArchived, nil)
+ }
+
+ // queryPlanner retrieves a planner by ID. With a query, its cards are filtered and sorted by MongoDB.
+ func queryPlanner(ctx context.Context, id string, includeArchived bool, query *cardQuery) (*Planner, error) {
  	log.Printf("Getting planner with id=%s", id)
  
- 	result := plannerCollection.FindOne(ctx, bson.M{"id": id})
- 	if result.Err() != nil {
- 		return nil, result.Err()
- 	}
  	var planner Planner
- 	if err := result.Decode(&planner); err != nil {
- 		return nil, err
+ 	var laneCounts map[string]int
+ 	if query == nil {
+ 		result := plannerCollection.FindOne(ctx, bson.M{"id": id})
+ 		if result.Err() != nil {
+ 			return nil, result.Err()
+ 		}
+ 		if err := result.Decode(&planner); err != nil {
+ 			return nil, err
+ 		}
+ 	} else {
+ 		cur, err := plannerCollection.Aggregate(ctx, query.pipeline(id))
+ 		if err != nil {
+ 			return nil, err
+ 		}
+ 		defer cur.Close(ctx)
+ 		if !cur.Next(ctx) {
+ 			if err := cur.Err(); err != nil {
+ 				return nil, err
+ 			}
+ 			return nil, mongo.ErrNoDocuments
+ 		}
+ 		var result struct {
+ 			Planner        `bson:",inline"`
+ 			LaneCardCounts []struct {
+ 				ID    string `bson:"id"`
+ 				Count int    `bson:"count"`
+ 			} `bson:"lane_card_counts"`
+ 		}
+ 		if err := cur.Decode(&result); err != nil {
+ 			return nil, err
+ 		}
+ 		planner = result.Planner
+ 		laneCounts = map[string]int{}
+ 		for _, c := range result.LaneCardCounts {
+ 			laneCounts[c.ID] = c.Count
+ 		}
  	}
  
  	// Ensure Lanes, Cards, and Columns slices are not nil
@@ -117,23 +323,49 @@ func CreatePlanner(ctx context.Context, title, description, templateID string, u
  			if planner.Lanes[i].Cards == nil {
  				planner.Lanes[i].Cards = []PlannerCard{}
  			}
+ 			planner.Lanes[i].Category = laneCategory(&planner.Lanes[i])
  		}
  	}
  	if planner.Columns == nil {
  		planner.Columns = []PlannerColumn{}
  	}
  
- 	// Sort lanes and cards by position
+ 	blocked, err := unresolvedBlockers(ctx, &planner)
+ 	if err != nil {
+ 		return nil, err
+ 	}
+ 	flagBlockedCards(&planner, blocked)
+ 
+ 	if !includeArchived {
+ 		hideArchived(&planner)
+ 	}
+ 
+ 	// Sort columns, lanes and cards by position
+ 	sort.Slice(planner.Columns, func(i, j int) bool {
+ 		return planner.Columns[i].Position < planner.Columns[j].Position
+ 	})
  	sort.Slice(planner.Lanes, func(i, j int) bool {
  		return planner.Lanes[i].Position < planner.Lanes[j].Position
  	})
  	for i := range planner.Lanes {
- 		sort.Slice(planner.Lanes[i].Cards, func(a, b int) bool {
- 			return planner.Lanes[i].Cards[a].Position < planner.Lanes[i].Cards[b].Position
- 		})
+ 		// A sorted query already returns cards in order
+ 		if query == nil || len(query.Sort) == 0 {
+ 			sort.Slice(planner.Lanes[i].Cards, func(a, b int) bool {
+ 				return planner.Lanes[i].Cards[a].Position < planner.Lanes[i].Cards[b].Position
+ 			})
+ 		}
+ 		for j := range planner.Lanes[i].Cards {
+ 			normalizeCard(&planner.Lanes[i].Cards[j])
+ 		}
  	}
  
- 	log.Printf("GetPlanner: Returning planner with %d lanes and %d columns", len(planner.Lanes), len(planner.Columns))
+  	if laneCounts == nil {
+  		flagWIPLimits(&planner)
+  	} else {
+  		flagWIPLimitCounts(&planner, laneCounts)
+  	}
+ 
+	log.Printf("GetPlanner: Returning planner with %d lanes and %d columns", len(planner.Lanes), len(planner.Columns))
  	return &planner, nil
  }
 
@@ -149,14 +381,41 @@ func UpdatePlanner(ctx context.Context, id, title, description string) (*Planner
 		return nil, err
 	}
 
-	return GetPlanner(ctx, id)
+	planner, err := GetPlanner(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	publishEvent(ctx, id, EventPlannerUpdated, planner)
+	return planner, nil
 }
 
 	// DeletePlanner deletes a planner and all its lanes and cards
 func DeletePlanner(ctx context.Context, id string) error {
 	log.Printf("Deleting planner with id=%s", id)
 	_, err := plannerCollection.DeleteOne(ctx, bson.M{"id": id})
-	return err
+	if err != nil {
+		return err
+	}
+	if _, err := recurrenceCollection.DeleteMany(ctx, bson.M{"planner_id": id}); err != nil {
+		return err
+	}
+	if _, err := cardTransitionCollection.DeleteMany(ctx, bson.M{"planner_id": id}); err != nil {
+		return err
+	}
+	if _, err := viewCollection.DeleteMany(ctx, bson.M{"planner_id": id}); err != nil {
+		return err
+	}
+	if _, err := commentCollection.DeleteMany(ctx, bson.M{"planner_id": id}); err != nil {
+		return err
+	}
+	if _, err := mentionCollection.DeleteMany(ctx, bson.M{"planner_id": id}); err != nil {
+		return err
+	}
+	if _, err := cardLinkCollection.DeleteMany(ctx, bson.M{"$or": []bson.M{{"source_planner_id": id}, {"target_planner_id": id}}}); err != nil {
+		return err
+	}
+	publishEvent(ctx, id, EventPlannerDeleted, bson.M{"id": id})
+	return nil
 }
 
 // ExportPlannerMarkdown exports a planner as a Markdown document
@@ -167,52 +426,12 @@ func ExportPlannerMarkdown(ctx context.Context, id string) (string, error) {
 		return "", err
 	}
 	
-	// Build the Markdown document
-	markdown := "# " + planner.Title + "\n\n"
-	
-	if planner.Description != "" {
-		markdown += planner.Description + "\n\n"
-	}
-	
-	// Add each lane and its cards
-	for _, lane := range planner.Lanes {
-		markdown += "## " + lane.Title + "\n\n"
-		
-		if lane.Description != "" {
-			markdown += lane.Description + "\n\n"
-		}
-		
-		// Add cards
-		for _, card := range lane.Cards {
-			if title, ok := card.Fields["title"].(string); ok {
-				markdown += "### " + title + "\n\n"
-			}
-			if content, ok := card.Fields["content"].(string); ok {
-				markdown += content + "\n\n"
-			}
-		}
-	}
-	
-	return markdown, nil
-}
-
-// ImportPlannerFromMarkdown creates a planner from a Markdown document
-// This is a simplified implementation and would need more robust parsing in production
-func ImportPlannerFromMarkdown(ctx context.Context, markdown, templateID string) (*Planner, error) {
-	// This would need a proper Markdown parser in a real implementation
-	// For now, we'll just create a basic planner with the Markdown content as description
-	
-	log.Printf("Importing planner from Markdown with templateID=%s", templateID)
-	// Extract title from first heading (simplified)
-	title := "Imported Planner"
-	description := markdown
-	
-	return CreatePlanner(ctx, title, description, templateID, "")
+	return renderPlannerMarkdown(planner), nil
 }
 
-// GetPlannersByUser retrieves all planners owned by a specific user
+// GetPlannersByUser retrieves all planners owned by or shared with a specific user
 func GetPlannersByUser(ctx context.Context, userID string) ([]*Planner, error) {
-	cursor, err := plannerCollection.Find(ctx, bson.M{"user_id": userID})
+	cursor, err := plannerCollection.Find(ctx, plannerAccessFilter(userID))
 	if err != nil {
 		return nil, err
 	}
@@ -285,7 +504,11 @@ func HandleCreatePlanner(w http.ResponseWriter, r *http.Request) {
 	planner, err := CreatePlanner(r.Context(), request.Title, request.Description, request.TemplateID, userID)
 	if err != nil {
 		log.Printf("[ERROR] Failed to create planner (title=%s, templateID=%s): %v", request.Title, request.TemplateID, err)
-		http.Error(w, fmt.Sprintf("Failed to create planner: %v", err), http.StatusInternalServerError)
+		status := http.StatusInternalServerError
+		if errors.Is(err, ErrTemplateNotFound) {
+			status = http.StatusBadRequest
+		}
+		http.Error(w, fmt.Sprintf("Failed to create planner: %v", err), status)
 		return
 	}
 
@@ -310,11 +533,37 @@ func HandleGetPlanner(w http.ResponseWriter, r *http.Request) {
 	}
 	id := path[len("/planner/"):]
 	
-	planner, err := GetPlanner(r.Context(), id)
+	query := r.URL.Query()
+	if query.Get("view") == "" && query.Get("filter") == "" && query.Get("sort") == "" {
+		planner, err := GetPlanner(r.Context(), id)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		if err := applyRequestedGrouping(planner, r, nil); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		writePlanner(w, planner)
+		return
+	}
+
+	userID, _ := r.Context().Value("user_id").(string)
+	planner, view, err := GetPlannerView(r.Context(), id, userID, query.Get("view"), query.Get("filter"), query.Get("sort"))
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusNotFound)
+		http.Error(w, err.Error(), viewErrorStatus(err))
 		return
 	}
+	// An explicit group_by wins over the view's grouping
+	if err := applyRequestedGrouping(planner, r, view); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	writePlanner(w, planner)
+}
+
+// writePlanner writes a planner as JSON
+func writePlanner(w http.ResponseWriter, planner *Planner) {
 	
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(planner); err != nil {
@@ -382,11 +631,49 @@ func HandleDeletePlanner(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// lastIDNano is the timestamp of the last generated ID, kept so bulk inserts never reuse one
+var lastIDNano int64
+
 // generateID creates a unique ID for database records
 func GenerateID() string {
 	// Simple implementation using timestamp and random number
 	// In production, use a proper UUID library
-	return fmt.Sprintf("%d-%d", time.Now().UnixNano(), time.Now().Unix())
+	now := time.Now()
+	nano := now.UnixNano()
+	for {
+		last := atomic.LoadInt64(&lastIDNano)
+		if nano <= last {
+			nano = last + 1
+		}
+		if atomic.CompareAndSwapInt64(&lastIDNano, last, nano) {
+			break
+		}
+	}
+	return fmt.Sprintf("%d-%d", nano, now.Unix())
+}
+
+// findPlannerIDByLane returns the ID of the planner that owns a lane
+func findPlannerIDByLane(ctx context.Context, laneID string) (string, error) {
+	var result struct {
+		ID string `bson:"id"`
+	}
+	opts := options.FindOne().SetProjection(bson.M{"id": 1})
+	if err := plannerCollection.FindOne(ctx, bson.M{"lanes.id": laneID}, opts).Decode(&result); err != nil {
+		return "", err
+	}
+	return result.ID, nil
+}
+
+// findPlannerIDByCard returns the ID of the planner that owns a card
+func findPlannerIDByCard(ctx context.Context, cardID string) (string, error) {
+	var result struct {
+		ID string `bson:"id"`
+	}
+	opts := options.FindOne().SetProjection(bson.M{"id": 1})
+	if err := plannerCollection.FindOne(ctx, bson.M{"lanes.cards.id": cardID}, opts).Decode(&result); err != nil {
+		return "", err
+	}
+	return result.ID, nil
 }
 
 
@@ -432,15 +719,16 @@ func HandleImportPlannerMarkdown(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
+	userID, _ := r.Context().Value("user_id").(string)
 	
-	planner, err := ImportPlannerFromMarkdown(r.Context(), request.Markdown, request.TemplateID)
+	planner, report, err := ImportPlannerFromMarkdown(r.Context(), request.Markdown, request.TemplateID, userID)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 	
 	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(planner); err != nil {
+	if err := json.NewEncoder(w).Encode(importResponse{planner, report}); err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 	}
 }
@@ -0,0 +1,663 @@
+package planner
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Supported recurrence frequencies
+const (
+	RecurrenceDaily   = "DAILY"
+	RecurrenceWeekly  = "WEEKLY"
+	RecurrenceMonthly = "MONTHLY"
+)
+
+const (
+	maxRecurrencePeriods = 1000 // periods searched for the next occurrence
+	maxRecurrenceCatchUp = 100  // occurrences created per recurrence and scheduler run
+)
+
+// ErrInvalidRecurrence is returned when a recurrence or its RRULE is malformed
+var ErrInvalidRecurrence = errors.New("invalid recurrence")
+
+var (
+	recurrenceCollection           *mongo.Collection
+	recurrenceOccurrenceCollection *mongo.Collection
+)
+
+var rruleWeekdays = map[string]time.Weekday{
+	"MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday, "TH": time.Thursday,
+	"FR": time.Friday, "SA": time.Saturday, "SU": time.Sunday,
+}
+
+// CardRecurrence creates a card from a card template in a lane at every occurrence of an RRULE.
+// Occurrences take their time of day from Start, in Timezone.
+type CardRecurrence struct {
+	ID             string              `json:"id" bson:"id"`
+	PlannerID      string              `json:"planner_id" bson:"planner_id"`
+	LaneID         string              `json:"lane_id" bson:"lane_id"`
+	RRule          string              `json:"rrule" bson:"rrule"` // e.g. FREQ=WEEKLY;BYDAY=MO,TH
+	Start          time.Time           `json:"start" bson:"start"`
+	Timezone       string              `json:"timezone,omitempty" bson:"timezone,omitempty"`
+	Card           PlannerTemplateCard `json:"card" bson:"card"`
+	Enabled        bool                `json:"enabled" bson:"enabled"`
+	NextOccurrence *time.Time          `json:"next_occurrence,omitempty" bson:"next_occurrence,omitempty"`
+	LastOccurrence *time.Time          `json:"last_occurrence,omitempty" bson:"last_occurrence,omitempty"`
+	CreatedBy      string              `json:"created_by,omitempty" bson:"created_by,omitempty"`
+	CreatedAt      time.Time           `json:"created_at" bson:"created_at"`
+	UpdatedAt      time.Time           `json:"updated_at" bson:"updated_at"`
+
+	// Set when the scheduler disabled the recurrence because a card could not be created; saving it again resumes it
+	PausedAt     *time.Time `json:"paused_at,omitempty" bson:"paused_at,omitempty"`
+	PausedReason string     `json:"paused_reason,omitempty" bson:"paused_reason,omitempty"`
+}
+
+// recurrenceRule is a parsed RRULE
+type recurrenceRule struct {
+	Freq       string
+	Interval   int
+	ByDay      []time.Weekday
+	ByMonthDay []int
+	Until      *time.Time
+}
+
+// parseRRule parses the supported RRULE subset: FREQ=DAILY, WEEKLY with BYDAY or MONTHLY with BYMONTHDAY,
+// plus INTERVAL and UNTIL
+func parseRRule(s string) (*recurrenceRule, error) {
+	rule := &recurrenceRule{Interval: 1}
+	s = strings.TrimPrefix(strings.TrimSpace(strings.ToUpper(s)), "RRULE:")
+	for _, part := range strings.Split(s, ";") {
+		if part == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("%w: malformed rrule part %q", ErrInvalidRecurrence, part)
+		}
+		switch key {
+		case "FREQ":
+			rule.Freq = value
+		case "INTERVAL":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("%w: INTERVAL must be a positive number", ErrInvalidRecurrence)
+			}
+			rule.Interval = n
+		case "BYDAY":
+			for _, day := range strings.Split(value, ",") {
+				wd, ok := rruleWeekdays[day]
+				if !ok {
+					return nil, fmt.Errorf("%w: unsupported BYDAY value %q", ErrInvalidRecurrence, day)
+				}
+				rule.ByDay = append(rule.ByDay, wd)
+			}
+		case "BYMONTHDAY":
+			for _, day := range strings.Split(value, ",") {
+				n, err := strconv.Atoi(day)
+				if err != nil || n < 1 || n > 31 {
+					return nil, fmt.Errorf("%w: BYMONTHDAY must be between 1 and 31", ErrInvalidRecurrence)
+				}
+				rule.ByMonthDay = append(rule.ByMonthDay, n)
+			}
+		case "UNTIL":
+			until, err := time.Parse("20060102T150405Z", value)
+			if err != nil {
+				if until, err = time.Parse("20060102", value); err != nil {
+					return nil, fmt.Errorf("%w: UNTIL must be YYYYMMDD or YYYYMMDDTHHMMSSZ", ErrInvalidRecurrence)
+				}
+				until = until.Add(24*time.Hour - time.Second)
+			}
+			rule.Until = &until
+		default:
+			return nil, fmt.Errorf("%w: unsupported rrule part %q", ErrInvalidRecurrence, key)
+		}
+	}
+
+	switch rule.Freq {
+	case RecurrenceDaily:
+		if len(rule.ByDay) > 0 || len(rule.ByMonthDay) > 0 {
+			return nil, fmt.Errorf("%w: DAILY takes no BYDAY or BYMONTHDAY", ErrInvalidRecurrence)
+		}
+	case RecurrenceWeekly:
+		if len(rule.ByMonthDay) > 0 {
+			return nil, fmt.Errorf("%w: WEEKLY takes BYDAY, not BYMONTHDAY", ErrInvalidRecurrence)
+		}
+	case RecurrenceMonthly:
+		if len(rule.ByDay) > 0 {
+			return nil, fmt.Errorf("%w: MONTHLY takes BYMONTHDAY, not BYDAY", ErrInvalidRecurrence)
+		}
+	default:
+		return nil, fmt.Errorf("%w: FREQ must be DAILY, WEEKLY or MONTHLY", ErrInvalidRecurrence)
+	}
+	return rule, nil
+}
+
+// mondayOffset returns the number of days from Monday to a weekday
+func mondayOffset(wd time.Weekday) int {
+	return (int(wd) + 6) % 7
+}
+
+// localDays counts the calendar days from a to b in a's location
+func localDays(a, b time.Time) int {
+	b = b.In(a.Location())
+	da := time.Date(a.Year(), a.Month(), a.Day(), 0, 0, 0, 0, time.UTC)
+	db := time.Date(b.Year(), b.Month(), b.Day(), 0, 0, 0, 0, time.UTC)
+	return int(db.Sub(da).Hours() / 24)
+}
+
+// period returns the candidate occurrences of the p-th period (day, week or month) after start, in order
+func (r *recurrenceRule) period(start time.Time, p int) []time.Time {
+	loc := start.Location()
+	h, m, s := start.Clock()
+	y, mo, d := start.Date()
+	candidates := []time.Time{}
+
+	switch r.Freq {
+	case RecurrenceDaily:
+		candidates = append(candidates, time.Date(y, mo, d+p*r.Interval, h, m, s, 0, loc))
+	case RecurrenceWeekly:
+		days := r.ByDay
+		if len(days) == 0 {
+			days = []time.Weekday{start.Weekday()}
+		}
+		offsets := []int{}
+		for _, wd := range days {
+			offsets = append(offsets, mondayOffset(wd))
+		}
+		sort.Ints(offsets)
+		monday := d - mondayOffset(start.Weekday()) + p*7*r.Interval
+		for _, off := range offsets {
+			candidates = append(candidates, time.Date(y, mo, monday+off, h, m, s, 0, loc))
+		}
+	case RecurrenceMonthly:
+		monthDays := append([]int{}, r.ByMonthDay...)
+		if len(monthDays) == 0 {
+			monthDays = []int{d}
+		}
+		sort.Ints(monthDays)
+		first := time.Date(y, mo+time.Month(p*r.Interval), 1, h, m, s, 0, loc)
+		last := first.AddDate(0, 1, -1).Day()
+		for _, md := range monthDays {
+			// Months without the day are skipped, as in RFC 5545
+			if md <= last {
+				candidates = append(candidates, time.Date(first.Year(), first.Month(), md, h, m, s, 0, loc))
+			}
+		}
+	}
+	return candidates
+}
+
+// next returns the first occurrence after a time, or false when the rule has ended
+func (r *recurrenceRule) next(start, after time.Time) (time.Time, bool) {
+	if after.Before(start) {
+		after = start.Add(-time.Nanosecond)
+	}
+
+	// Skip the periods that end before after
+	p := 0
+	switch r.Freq {
+	case RecurrenceDaily:
+		p = localDays(start, after) / r.Interval
+	case RecurrenceWeekly:
+		p = (localDays(start, after) + mondayOffset(start.Weekday())) / (7 * r.Interval)
+	case RecurrenceMonthly:
+		a := after.In(start.Location())
+		p = ((a.Year()-start.Year())*12 + int(a.Month()-start.Month())) / r.Interval
+	}
+	if p < 0 {
+		p = 0
+	}
+
+	for i := 0; i < maxRecurrencePeriods; i, p = i+1, p+1 {
+		for _, c := range r.period(start, p) {
+			if c.Before(start) || !c.After(after) {
+				continue
+			}
+			if r.Until != nil && c.After(*r.Until) {
+				return time.Time{}, false
+			}
+			return c, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// schedule parses the RRULE of a recurrence and returns it with the start in the recurrence's timezone
+func (rec *CardRecurrence) schedule() (*recurrenceRule, time.Time, error) {
+	rule, err := parseRRule(rec.RRule)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	loc := time.UTC
+	if rec.Timezone != "" {
+		if loc, err = time.LoadLocation(rec.Timezone); err != nil {
+			return nil, time.Time{}, fmt.Errorf("%w: unknown timezone %q", ErrInvalidRecurrence, rec.Timezone)
+		}
+	}
+	return rule, rec.Start.In(loc), nil
+}
+
+// recurringCardFields keeps the template fields whose columns still exist
+func recurringCardFields(planner *Planner, fields map[string]interface{}) map[string]interface{} {
+	kept := map[string]interface{}{}
+	for key, value := range fields {
+		if key == FieldTitle || key == FieldContent {
+			kept[key] = value
+			continue
+		}
+		for _, col := range planner.Columns {
+			if col.ID == key {
+				kept[key] = value
+			}
+		}
+	}
+	return kept
+}
+
+// validateRecurrence checks a recurrence against its planner and normalizes its card template
+func validateRecurrence(planner *Planner, rec *CardRecurrence) error {
+	if _, _, err := rec.schedule(); err != nil {
+		return err
+	}
+	if rec.Start.IsZero() {
+		return fmt.Errorf("%w: start is required", ErrInvalidRecurrence)
+	}
+	laneFound := false
+	for _, lane := range planner.Lanes {
+		if lane.ID == rec.LaneID {
+			laneFound = true
+		}
+	}
+	if !laneFound {
+		return fmt.Errorf("%w: unknown lane %q", ErrInvalidRecurrence, rec.LaneID)
+	}
+
+	fields, err := ValidateCardFields(planner.Columns, rec.Card.Fields)
+	if err != nil {
+		return err
+	}
+	if err := validateUserFields(planner, fields); err != nil {
+		return err
+	}
+	if title, _ := fields[FieldTitle].(string); strings.TrimSpace(title) == "" {
+		return fmt.Errorf("%w: card title is required", ErrInvalidRecurrence)
+	}
+	if rec.Card.Priority != "" && !isValidPriority(rec.Card.Priority) {
+		return fmt.Errorf("%w: invalid priority %q", ErrInvalidRecurrence, rec.Card.Priority)
+	}
+	rec.Card = PlannerTemplateCard{
+		Fields:    fields,
+		Priority:  rec.Card.Priority,
+		Labels:    normalizeLabels(rec.Card.Labels),
+		Checklist: rec.Card.Checklist,
+	}
+	return nil
+}
+
+// createRecurringCard appends a card built from a recurrence's template to its lane
+func createRecurringCard(ctx context.Context, rec *CardRecurrence, occurrence time.Time) (*PlannerCard, error) {
+	log.Printf("Creating recurring card: recurrenceID=%s, occurrence=%s", rec.ID, occurrence.Format(time.RFC3339))
+
+	var planner Planner
+	if err := plannerCollection.FindOne(ctx, bson.M{"id": rec.PlannerID, "lanes.id": rec.LaneID}).Decode(&planner); err != nil {
+		return nil, err
+	}
+	position := 1
+	for _, lane := range planner.Lanes {
+		if lane.ID != rec.LaneID {
+			continue
+		}
+		for _, card := range lane.Cards {
+			if card.Position >= position {
+				position = card.Position + 1
+			}
+		}
+	}
+
+	fields, err := ValidateCardFields(planner.Columns, recurringCardFields(&planner, rec.Card.Fields))
+	if err != nil {
+		return nil, err
+	}
+	if err := validateUserFields(&planner, fields); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	card := PlannerCard{
+		ID:           GenerateID(),
+		LaneID:       rec.LaneID,
+		Fields:       fields,
+		Priority:     rec.Card.Priority,
+		Labels:       rec.Card.Labels,
+		RecurrenceID: rec.ID,
+		Position:     position,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	for j, text := range rec.Card.Checklist {
+		card.Checklist = append(card.Checklist, ChecklistItem{
+			ID:        GenerateID(),
+			Text:      text,
+			Position:  j + 1,
+			CreatedAt: now,
+			UpdatedAt: now,
+		})
+	}
+	if err := checkWIPLimit(&planner, rec.LaneID, []PlannerCard{card}); err != nil {
+		return nil, err
+	}
+
+	result, err := plannerCollection.UpdateOne(ctx,
+		withWIPLimitGuard(bson.M{"id": rec.PlannerID, "lanes.id": rec.LaneID}, wipLimitGuard(&planner, rec.LaneID, []PlannerCard{card})),
+		bson.M{"$push": bson.M{"lanes.$.cards": card}},
+	)
+	if err != nil {
+		return nil, err
+	}
+	if result.MatchedCount == 0 {
+		return nil, wipLimitConflict(ctx, planner.ID, rec.LaneID, []PlannerCard{card})
+	}
+	normalizeCard(&card)
+	recordActivity(ctx, planner.ID, EventCardAdded, "card", card.ID, nil, &ActivitySnapshot{Card: &card})
+	recordTransition(ctx, planner.ID, card.ID, nil, findLane(&planner, rec.LaneID))
+	publishEvent(ctx, planner.ID, EventCardAdded, card)
+	return fireCardRules(ctx, planner.ID, &card,
+		ruleEvent{Trigger: RuleTriggerCardEnteredLane, CardID: card.ID, LaneID: rec.LaneID}), nil
+}
+
+// permanentRecurrenceError reports whether creating a recurring card failed for a reason retrying won't fix:
+// the planner or lane is gone, the lane is full in block mode or the template no longer fits the planner
+func permanentRecurrenceError(err error) bool {
+	var wipErr *WIPLimitError
+	return errors.As(err, &wipErr) ||
+		errors.Is(err, mongo.ErrNoDocuments) ||
+		errors.Is(err, ErrInvalidCardField) ||
+		errors.Is(err, ErrInvalidRecurrence) ||
+		errors.Is(err, ErrNotPlannerMember)
+}
+
+// FireRecurrences creates the cards of all due occurrences, catching up on occurrences missed while no scheduler ran.
+// Each occurrence is claimed in the occurrences collection first, so several instances never create it twice.
+func FireRecurrences(ctx context.Context) error {
+	now := time.Now()
+	cur, err := recurrenceCollection.Find(ctx, bson.M{"enabled": true, "next_occurrence": bson.M{"$lte": now}})
+	if err != nil {
+		return err
+	}
+	defer cur.Close(ctx)
+
+	for cur.Next(ctx) {
+		var rec CardRecurrence
+		if err := cur.Decode(&rec); err != nil {
+			return err
+		}
+		rule, start, err := rec.schedule()
+		if err != nil {
+			log.Printf("[ERROR] Recurrence %s has an invalid schedule: %v", rec.ID, err)
+			continue
+		}
+
+		occurrence, more := *rec.NextOccurrence, true
+		var last *time.Time
+		var failure error
+		for n := 0; more && n < maxRecurrenceCatchUp && !occurrence.After(now); n++ {
+			_, err := recurrenceOccurrenceCollection.InsertOne(ctx, bson.M{
+				"recurrence_id": rec.ID, "occurrence": occurrence, "created_at": now,
+			})
+			if err != nil && !mongo.IsDuplicateKeyError(err) {
+				return err
+			}
+			if err == nil {
+				if _, err := createRecurringCard(ctx, &rec, occurrence); err != nil {
+					log.Printf("[ERROR] Failed to create card for recurrence %s: %v", rec.ID, err)
+					claim := bson.M{"recurrence_id": rec.ID, "occurrence": occurrence}
+					if !permanentRecurrenceError(err) {
+						// Release the claim so that the occurrence is retried on the next run
+						recurrenceOccurrenceCollection.DeleteOne(ctx, claim)
+						break
+					}
+					// Retrying won't help, so keep the claim with the reason and pause the recurrence below
+					recurrenceOccurrenceCollection.UpdateOne(ctx, claim, bson.M{"$set": bson.M{"failed_reason": err.Error()}})
+					failure = err
+					break
+				}
+			}
+			t := occurrence
+			last = &t
+			occurrence, more = rule.next(start, occurrence)
+		}
+		if last == nil && failure == nil {
+			continue
+		}
+
+		update := bson.M{}
+		if last != nil {
+			update["$max"] = bson.M{"last_occurrence": *last}
+		}
+		switch {
+		case failure != nil:
+			update["$set"] = bson.M{"enabled": false, "paused_at": now, "paused_reason": failure.Error()}
+			update["$unset"] = bson.M{"next_occurrence": ""}
+		case more:
+			update["$set"] = bson.M{"next_occurrence": occurrence}
+		default:
+			update["$unset"] = bson.M{"next_occurrence": ""}
+		}
+		// Only advance from the occurrence this run started at, in case another instance got further
+		_, err = recurrenceCollection.UpdateOne(ctx, bson.M{"id": rec.ID, "next_occurrence": rec.NextOccurrence}, update)
+		if err != nil {
+			return err
+		}
+	}
+	return cur.Err()
+}
+
+// RunRecurrenceScheduler creates recurring cards every interval until ctx is done
+func RunRecurrenceScheduler(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := FireRecurrences(ctx); err != nil {
+			log.Printf("[ERROR] Failed to fire card recurrences: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// GetRecurrences returns the card recurrences of a planner
+func GetRecurrences(ctx context.Context, plannerID string) ([]CardRecurrence, error) {
+	log.Printf("Getting recurrences: plannerID=%s", plannerID)
+
+	cur, err := recurrenceCollection.Find(ctx, bson.M{"planner_id": plannerID},
+		options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	recurrences := []CardRecurrence{}
+	if err := cur.All(ctx, &recurrences); err != nil {
+		return nil, err
+	}
+	return recurrences, nil
+}
+
+// SaveRecurrence creates a card recurrence, or replaces the one with the same ID.
+// The next occurrence is computed from now, so saving never creates cards for past occurrences.
+func SaveRecurrence(ctx context.Context, plannerID string, rec CardRecurrence) (*CardRecurrence, error) {
+	log.Printf("Saving recurrence: plannerID=%s, recurrenceID=%s", plannerID, rec.ID)
+
+	planner, err := getPlannerColumns(ctx, plannerID)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateRecurrence(planner, &rec); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	rec.PlannerID = plannerID
+	rec.UpdatedAt = now
+	rec.LastOccurrence = nil
+	rec.NextOccurrence = nil
+	rec.PausedAt = nil
+	rec.PausedReason = ""
+	if rec.Enabled {
+		rule, start, _ := rec.schedule()
+		if next, ok := rule.next(start, now); ok {
+			rec.NextOccurrence = &next
+		}
+	}
+
+	if rec.ID == "" {
+		rec.ID = GenerateID()
+		rec.CreatedAt = now
+		if _, err := recurrenceCollection.InsertOne(ctx, rec); err != nil {
+			return nil, err
+		}
+		return &rec, nil
+	}
+
+	var existing CardRecurrence
+	if err := recurrenceCollection.FindOne(ctx, bson.M{"id": rec.ID, "planner_id": plannerID}).Decode(&existing); err != nil {
+		return nil, err
+	}
+	rec.CreatedAt = existing.CreatedAt
+	rec.CreatedBy = existing.CreatedBy
+	rec.LastOccurrence = existing.LastOccurrence
+	if _, err := recurrenceCollection.ReplaceOne(ctx, bson.M{"id": rec.ID}, rec); err != nil {
+		return nil, err
+	}
+	return &rec, nil
+}
+
+// DeleteRecurrence stops and removes a card recurrence; cards it already created are kept
+func DeleteRecurrence(ctx context.Context, plannerID, recurrenceID string) error {
+	log.Printf("Deleting recurrence: plannerID=%s, recurrenceID=%s", plannerID, recurrenceID)
+
+	result, err := recurrenceCollection.DeleteOne(ctx, bson.M{"id": recurrenceID, "planner_id": plannerID})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	_, err = recurrenceOccurrenceCollection.DeleteMany(ctx, bson.M{"recurrence_id": recurrenceID})
+	return err
+}
+
+// recurrenceFromCard uses an existing card as the template of a recurrence
+func recurrenceFromCard(ctx context.Context, rec *CardRecurrence, cardID string) error {
+	card, err := GetCard(ctx, cardID)
+	if err != nil {
+		return err
+	}
+	rec.Card = PlannerTemplateCard{Fields: map[string]interface{}{}, Priority: card.Priority, Labels: card.Labels}
+	for key, value := range card.Fields {
+		if key != "lane_id" && key != "moved_at" {
+			rec.Card.Fields[key] = value
+		}
+	}
+	for _, item := range card.Checklist {
+		rec.Card.Checklist = append(rec.Card.Checklist, item.Text)
+	}
+	if rec.LaneID == "" {
+		rec.LaneID = card.LaneID
+	}
+	return nil
+}
+
+// recurrenceErrorStatus maps recurrence errors to HTTP status codes
+func recurrenceErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, ErrInvalidRecurrence), errors.Is(err, ErrInvalidCardField), errors.Is(err, ErrNotPlannerMember):
+		return http.StatusBadRequest
+	case errors.Is(err, mongo.ErrNoDocuments):
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
+
+// HandleRecurrences handles GET, POST, PUT and DELETE on /planner/{id}/recurrences[/{recurrenceId}]
+func HandleRecurrences(w http.ResponseWriter, r *http.Request) {
+	// Extract planner and recurrence IDs from path
+	path := r.URL.Path
+	parts := strings.Split(path, "/")
+	if len(parts) < 4 || len(parts) > 5 || parts[1] != "planner" || parts[3] != "recurrences" {
+		http.Error(w, "Invalid path", http.StatusBadRequest)
+		return
+	}
+	plannerID := parts[2]
+	recurrenceID := ""
+	if len(parts) == 5 {
+		recurrenceID = parts[4]
+	}
+
+	var response interface{}
+	switch {
+	case r.Method == http.MethodGet && recurrenceID == "":
+		recurrences, err := GetRecurrences(r.Context(), plannerID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		response = recurrences
+	case (r.Method == http.MethodPost && recurrenceID == "") || (r.Method == http.MethodPut && recurrenceID != ""):
+		var request struct {
+			CardRecurrence
+			FromCardID string `json:"from_card_id,omitempty"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		rec := request.CardRecurrence
+		if request.FromCardID != "" {
+			if err := recurrenceFromCard(r.Context(), &rec, request.FromCardID); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+		rec.ID = recurrenceID
+		if userID, ok := r.Context().Value("user_id").(string); ok {
+			rec.CreatedBy = userID
+		}
+		saved, err := SaveRecurrence(r.Context(), plannerID, rec)
+		if err != nil {
+			http.Error(w, err.Error(), recurrenceErrorStatus(err))
+			return
+		}
+		response = saved
+	case r.Method == http.MethodDelete && recurrenceID != "":
+		if err := DeleteRecurrence(r.Context(), plannerID, recurrenceID); err != nil {
+			http.Error(w, err.Error(), recurrenceErrorStatus(err))
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,148 @@
+package planner
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// occurrences returns the first n occurrences of an RRULE after a time
+func occurrences(t *testing.T, rrule string, start, after time.Time, n int) []time.Time {
+	t.Helper()
+	rule, err := parseRRule(rrule)
+	if err != nil {
+		t.Fatalf("parseRRule(%q): %v", rrule, err)
+	}
+	result := []time.Time{}
+	for len(result) < n {
+		next, ok := rule.next(start, after)
+		if !ok {
+			break
+		}
+		result = append(result, next)
+		after = next
+	}
+	return result
+}
+
+func assertOccurrences(t *testing.T, name string, got []time.Time, want ...string) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("%s: got %d occurrences %v, want %v", name, len(got), got, want)
+	}
+	for i := range want {
+		if got[i].Format("2006-01-02 15:04") != want[i] {
+			t.Errorf("%s: occurrence %d is %s, want %s", name, i, got[i].Format("2006-01-02 15:04"), want[i])
+		}
+	}
+}
+
+func TestRecurrenceRuleNext(t *testing.T) {
+	// Wednesday
+	start := time.Date(2024, 1, 31, 9, 0, 0, 0, time.UTC)
+
+	assertOccurrences(t, "daily", occurrences(t, "FREQ=DAILY", start, start.AddDate(0, 0, -3), 3),
+		"2024-01-31 09:00", "2024-02-01 09:00", "2024-02-02 09:00")
+	assertOccurrences(t, "every other day", occurrences(t, "FREQ=DAILY;INTERVAL=2", start, start.Add(time.Hour), 2),
+		"2024-02-02 09:00", "2024-02-04 09:00")
+	assertOccurrences(t, "weekly", occurrences(t, "RRULE:FREQ=WEEKLY;BYDAY=MO,TH", start, start, 4),
+		"2024-02-01 09:00", "2024-02-05 09:00", "2024-02-08 09:00", "2024-02-12 09:00")
+	assertOccurrences(t, "biweekly", occurrences(t, "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO", start, start, 2),
+		"2024-02-12 09:00", "2024-02-26 09:00")
+	assertOccurrences(t, "weekly on start day", occurrences(t, "FREQ=WEEKLY", start, start.AddDate(0, 0, 90), 1),
+		"2024-05-01 09:00")
+	assertOccurrences(t, "monthly skips short months", occurrences(t, "FREQ=MONTHLY", start, start, 2),
+		"2024-03-31 09:00", "2024-05-31 09:00")
+	assertOccurrences(t, "monthly on dates", occurrences(t, "FREQ=MONTHLY;BYMONTHDAY=1,15", start, start, 3),
+		"2024-02-01 09:00", "2024-02-15 09:00", "2024-03-01 09:00")
+	assertOccurrences(t, "until", occurrences(t, "FREQ=DAILY;UNTIL=20240202", start, start, 5),
+		"2024-02-01 09:00", "2024-02-02 09:00")
+
+	// Catching up after downtime yields every missed occurrence in order
+	rule, _ := parseRRule("FREQ=WEEKLY;BYDAY=MO")
+	missed := 0
+	for next, ok := rule.next(start, start); ok && next.Before(start.AddDate(0, 0, 35)); next, ok = rule.next(start, next) {
+		missed++
+	}
+	if missed != 5 {
+		t.Errorf("expected 5 Mondays in five weeks, got %d", missed)
+	}
+}
+
+func TestParseRRuleErrors(t *testing.T) {
+	for _, rrule := range []string{
+		"",
+		"FREQ=YEARLY",
+		"FREQ=DAILY;INTERVAL=0",
+		"FREQ=WEEKLY;BYDAY=XX",
+		"FREQ=WEEKLY;BYMONTHDAY=1",
+		"FREQ=MONTHLY;BYMONTHDAY=32",
+		"FREQ=MONTHLY;BYDAY=MO",
+		"FREQ=DAILY;COUNT=3",
+		"FREQ",
+	} {
+		if _, err := parseRRule(rrule); !errors.Is(err, ErrInvalidRecurrence) {
+			t.Errorf("parseRRule(%q): expected ErrInvalidRecurrence, got %v", rrule, err)
+		}
+	}
+}
+
+func TestValidateRecurrence(t *testing.T) {
+	p := &Planner{
+		Lanes:   []PlannerLane{{ID: "todo"}},
+		Columns: []PlannerColumn{{ID: "points", Name: "Points", Type: ColumnTypeNumber}},
+	}
+	rec := CardRecurrence{
+		LaneID: "todo",
+		RRule:  "FREQ=WEEKLY;BYDAY=MO",
+		Start:  time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC),
+		Card: PlannerTemplateCard{
+			Fields: map[string]interface{}{"title": "Rotate logs", "points": 1.0},
+			Labels: []string{" ops ", "ops"},
+		},
+	}
+	if err := validateRecurrence(p, &rec); err != nil {
+		t.Fatalf("expected valid recurrence, got %v", err)
+	}
+	if len(rec.Card.Labels) != 1 || rec.Card.Labels[0] != "ops" {
+		t.Errorf("expected normalized labels, got %v", rec.Card.Labels)
+	}
+
+	invalid := rec
+	invalid.LaneID = "done"
+	if err := validateRecurrence(p, &invalid); !errors.Is(err, ErrInvalidRecurrence) {
+		t.Errorf("expected ErrInvalidRecurrence for unknown lane, got %v", err)
+	}
+	invalid = rec
+	invalid.Card.Fields = map[string]interface{}{"points": 1.0}
+	if err := validateRecurrence(p, &invalid); !errors.Is(err, ErrInvalidRecurrence) {
+		t.Errorf("expected ErrInvalidRecurrence without title, got %v", err)
+	}
+	invalid = rec
+	invalid.Timezone = "Mars/Olympus"
+	if err := validateRecurrence(p, &invalid); !errors.Is(err, ErrInvalidRecurrence) {
+		t.Errorf("expected ErrInvalidRecurrence for unknown timezone, got %v", err)
+	}
+}
+
+func TestPermanentRecurrenceError(t *testing.T) {
+	permanent := []error{
+		&WIPLimitError{LaneID: "l1", Limit: 2, Count: 3},
+		mongo.ErrNoDocuments,
+		fmt.Errorf("%w: points must be a number", ErrInvalidCardField),
+		fmt.Errorf("%w: user %q in column %q", ErrNotPlannerMember, "u1", "Owner"),
+	}
+	for _, err := range permanent {
+		if !permanentRecurrenceError(err) {
+			t.Errorf("expected %v to pause the recurrence", err)
+		}
+	}
+	for _, err := range []error{ErrCardConflict, errors.New("connection reset"), mongo.ErrClientDisconnected} {
+		if permanentRecurrenceError(err) {
+			t.Errorf("expected %v to be retried", err)
+		}
+	}
+}
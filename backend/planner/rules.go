@@ -0,0 +1,798 @@
+package planner
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Rule triggers
+const (
+	RuleTriggerCardEnteredLane = "card_entered_lane"
+	RuleTriggerCardLeftLane    = "card_left_lane"
+	RuleTriggerFieldChanged    = "field_changed"
+	RuleTriggerDueDatePassed   = "due_date_passed"
+)
+
+// Rule condition operators. List attributes (labels, assignees) match equals and contains when any entry matches.
+const (
+	RuleOpEquals      = "equals"
+	RuleOpNotEquals   = "not_equals"
+	RuleOpContains    = "contains"
+	RuleOpNotContains = "not_contains"
+	RuleOpEmpty       = "empty"
+	RuleOpNotEmpty    = "not_empty"
+)
+
+// Rule actions
+const (
+	RuleActionSetField    = "set_field"
+	RuleActionMoveToLane  = "move_to_lane"
+	RuleActionAddLabel    = "add_label"
+	RuleActionArchive     = "archive"
+	RuleActionPostWebhook = "post_webhook"
+)
+
+// maxRuleDepth bounds how many rules can trigger each other in one chain
+const maxRuleDepth = 5
+
+// ErrInvalidRule is returned when a rule definition is malformed
+var ErrInvalidRule = errors.New("invalid rule")
+
+var ruleFiringCollection *mongo.Collection
+
+// ErrWebhookTargetBlocked is returned when a webhook would connect to a loopback, private or link-local address
+var ErrWebhookTargetBlocked = errors.New("webhook target is not a public address")
+
+// ruleWebhookClient posts rule webhooks. Every connection, including redirects, goes through
+// checkWebhookDial, and proxies are not used so the checked address is the one connected to.
+var ruleWebhookClient = &http.Client{
+	Timeout: 10 * time.Second,
+	Transport: &http.Transport{
+		DialContext: (&net.Dialer{
+			Timeout: 5 * time.Second,
+			Control: checkWebhookDial,
+		}).DialContext,
+		TLSHandshakeTimeout: 5 * time.Second,
+		MaxIdleConns:        10,
+		IdleConnTimeout:     90 * time.Second,
+	},
+}
+
+// ruleWebhookWorkers bound how many webhooks are posted at once; firings beyond ruleWebhookQueue's
+// capacity are dropped rather than starting more goroutines
+const ruleWebhookWorkers = 4
+
+var (
+	ruleWebhookQueue        = make(chan ruleWebhook, 256)
+	startRuleWebhookWorkers sync.Once
+)
+
+// ruleWebhook is a webhook delivery waiting for a worker
+type ruleWebhook struct {
+	RuleID string
+	URL    string
+	Body   []byte
+}
+
+// cgnatRange is the shared address space of carrier-grade NAT (RFC 6598), which IsPrivate doesn't cover
+var cgnatRange = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// isPublicWebhookIP reports whether a webhook may connect to ip
+func isPublicWebhookIP(ip net.IP) bool {
+	return !(ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified() || cgnatRange.Contains(ip))
+}
+
+// checkWebhookDial is the dialer Control hook of ruleWebhookClient. It runs after DNS resolution,
+// so host names that resolve to internal addresses are refused too.
+func checkWebhookDial(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || !isPublicWebhookIP(ip) {
+		return fmt.Errorf("%w: %s", ErrWebhookTargetBlocked, host)
+	}
+	return nil
+}
+
+// PlannerRule is an automation rule: when its trigger fires for a card and all conditions match, its actions run in order
+type PlannerRule struct {
+	ID         string          `json:"id" bson:"id"`
+	Name       string          `json:"name" bson:"name"`
+	Enabled    bool            `json:"enabled" bson:"enabled"`
+	Trigger    RuleTrigger     `json:"trigger" bson:"trigger"`
+	Conditions []RuleCondition `json:"conditions,omitempty" bson:"conditions,omitempty"`
+	Actions    []RuleAction    `json:"actions" bson:"actions"`
+	CreatedAt  time.Time       `json:"created_at" bson:"created_at"`
+	UpdatedAt  time.Time       `json:"updated_at" bson:"updated_at"`
+}
+
+// RuleTrigger describes when a rule fires. LaneID restricts it to one lane, Field to one changed field.
+type RuleTrigger struct {
+	Type   string `json:"type" bson:"type"`
+	LaneID string `json:"lane_id,omitempty" bson:"lane_id,omitempty"`
+	Field  string `json:"field,omitempty" bson:"field,omitempty"`
+}
+
+// RuleCondition compares a card field or attribute with a value
+type RuleCondition struct {
+	Field string      `json:"field" bson:"field"`
+	Op    string      `json:"op" bson:"op"`
+	Value interface{} `json:"value,omitempty" bson:"value,omitempty"`
+}
+
+// RuleAction is a change made by a rule. Values of set_field may be "$now" or "$now+7d" (units h and d).
+type RuleAction struct {
+	Type   string      `json:"type" bson:"type"`
+	Field  string      `json:"field,omitempty" bson:"field,omitempty"`
+	Value  interface{} `json:"value,omitempty" bson:"value,omitempty"`
+	LaneID string      `json:"lane_id,omitempty" bson:"lane_id,omitempty"`
+	Label  string      `json:"label,omitempty" bson:"label,omitempty"`
+	URL    string      `json:"url,omitempty" bson:"url,omitempty"`
+}
+
+// ruleAttributes are the card attributes rules can read and set besides title, content and columns
+var ruleAttributes = []string{"priority", "labels", "assignees", "due_date", "start_date"}
+
+// rulesUsingField returns the IDs of the rules whose trigger, conditions or actions refer to a card field
+func rulesUsingField(rules []PlannerRule, field string) []string {
+	ids := []string{}
+	for _, rule := range rules {
+		uses := rule.Trigger.Field == field
+		for _, c := range rule.Conditions {
+			uses = uses || c.Field == field
+		}
+		for _, a := range rule.Actions {
+			uses = uses || a.Field == field
+		}
+		if uses {
+			ids = append(ids, rule.ID)
+		}
+	}
+	return ids
+}
+
+// ruleEvent is something that happened to a card and may trigger rules
+type ruleEvent struct {
+	Trigger string
+	CardID  string
+	LaneID  string   // lane entered or left
+	Changed []string // fields changed by an update
+}
+
+// ruleChain is carried in the context of rule actions to stop rules from triggering each other forever
+type ruleChain struct {
+	depth int
+	fired map[string]bool // rule ID + card ID
+}
+
+type ruleChainKey struct{}
+
+// validateRule checks a rule against the lanes and columns of its planner
+func validateRule(planner *Planner, rule *PlannerRule) error {
+	if strings.TrimSpace(rule.Name) == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidRule)
+	}
+	laneExists := func(id string) bool {
+		for _, lane := range planner.Lanes {
+			if lane.ID == id {
+				return true
+			}
+		}
+		return false
+	}
+	isField := func(key string) bool {
+		if key == FieldTitle || key == FieldContent || containsString(ruleAttributes, key) {
+			return true
+		}
+		for _, col := range planner.Columns {
+			if col.ID == key {
+				return true
+			}
+		}
+		return false
+	}
+
+	switch rule.Trigger.Type {
+	case RuleTriggerCardEnteredLane, RuleTriggerCardLeftLane, RuleTriggerFieldChanged, RuleTriggerDueDatePassed:
+	default:
+		return fmt.Errorf("%w: unsupported trigger %q", ErrInvalidRule, rule.Trigger.Type)
+	}
+	if rule.Trigger.LaneID != "" && !laneExists(rule.Trigger.LaneID) {
+		return fmt.Errorf("%w: unknown trigger lane %q", ErrInvalidRule, rule.Trigger.LaneID)
+	}
+	if rule.Trigger.Field != "" && !isField(rule.Trigger.Field) {
+		return fmt.Errorf("%w: unknown trigger field %q", ErrInvalidRule, rule.Trigger.Field)
+	}
+
+	for _, c := range rule.Conditions {
+		if c.Field != "lane_id" && !isField(c.Field) {
+			return fmt.Errorf("%w: unknown condition field %q", ErrInvalidRule, c.Field)
+		}
+		switch c.Op {
+		case RuleOpEquals, RuleOpNotEquals, RuleOpContains, RuleOpNotContains, RuleOpEmpty, RuleOpNotEmpty:
+		default:
+			return fmt.Errorf("%w: unsupported condition operator %q", ErrInvalidRule, c.Op)
+		}
+	}
+
+	if len(rule.Actions) == 0 {
+		return fmt.Errorf("%w: at least one action is required", ErrInvalidRule)
+	}
+	for _, a := range rule.Actions {
+		switch a.Type {
+		case RuleActionSetField:
+			if !isField(a.Field) {
+				return fmt.Errorf("%w: unknown field %q", ErrInvalidRule, a.Field)
+			}
+		case RuleActionMoveToLane:
+			if !laneExists(a.LaneID) {
+				return fmt.Errorf("%w: unknown target lane %q", ErrInvalidRule, a.LaneID)
+			}
+		case RuleActionAddLabel:
+			if strings.TrimSpace(a.Label) == "" {
+				return fmt.Errorf("%w: add_label needs a label", ErrInvalidRule)
+			}
+		case RuleActionArchive:
+		case RuleActionPostWebhook:
+			u, err := url.Parse(a.URL)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return fmt.Errorf("%w: post_webhook needs an http(s) url", ErrInvalidRule)
+			}
+			// Host names are checked again when connecting, after they are resolved
+			host := strings.ToLower(strings.TrimSuffix(u.Hostname(), "."))
+			ip := net.ParseIP(host)
+			if host == "localhost" || strings.HasSuffix(host, ".localhost") || (ip != nil && !isPublicWebhookIP(ip)) {
+				return fmt.Errorf("%w: post_webhook url must point to a public address", ErrInvalidRule)
+			}
+		default:
+			return fmt.Errorf("%w: unsupported action %q", ErrInvalidRule, a.Type)
+		}
+	}
+	return nil
+}
+
+// cardRuleValue returns the value of a card field or attribute as rules see it
+func cardRuleValue(card *PlannerCard, key string) interface{} {
+	switch key {
+	case "lane_id":
+		return card.LaneID
+	case "priority":
+		return card.Priority
+	case "labels":
+		return card.Labels
+	case "assignees":
+		return card.Assignees
+	case "due_date":
+		if card.DueDate == nil {
+			return nil
+		}
+		return *card.DueDate
+	case "start_date":
+		if card.StartDate == nil {
+			return nil
+		}
+		return *card.StartDate
+	}
+	return card.Fields[key]
+}
+
+// ruleValueString formats a value for comparison
+func ruleValueString(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case time.Time:
+		return v.UTC().Format(time.RFC3339)
+	}
+	if n, ok := toFloat(v); ok {
+		return strconv.FormatFloat(n, 'f', -1, 64)
+	}
+	return fmt.Sprint(v)
+}
+
+// matches reports whether a card satisfies the condition
+func (c RuleCondition) matches(card *PlannerCard) bool {
+	value := cardRuleValue(card, c.Field)
+	want := ruleValueString(c.Value)
+	list, isList := value.([]string)
+
+	switch c.Op {
+	case RuleOpEmpty, RuleOpNotEmpty:
+		empty := ruleValueString(value) == ""
+		if isList {
+			empty = len(list) == 0
+		}
+		return empty == (c.Op == RuleOpEmpty)
+	case RuleOpEquals, RuleOpNotEquals:
+		equal := ruleValueString(value) == want
+		if isList {
+			equal = containsString(list, want)
+		} else if t, ok := value.(time.Time); ok {
+			// Dates compare by day when the condition value has no time
+			equal = equal || (len(want) == len("2006-01-02") && t.UTC().Format("2006-01-02") == want)
+		}
+		return equal == (c.Op == RuleOpEquals)
+	case RuleOpContains, RuleOpNotContains:
+		contains := strings.Contains(strings.ToLower(ruleValueString(value)), strings.ToLower(want))
+		if isList {
+			contains = containsString(list, want)
+		}
+		return contains == (c.Op == RuleOpContains)
+	}
+	return false
+}
+
+// triggeredBy reports whether a rule fires for an event on a card
+func (rule *PlannerRule) triggeredBy(event ruleEvent, card *PlannerCard) bool {
+	if !rule.Enabled || rule.Trigger.Type != event.Trigger {
+		return false
+	}
+	switch event.Trigger {
+	case RuleTriggerCardEnteredLane, RuleTriggerCardLeftLane:
+		if rule.Trigger.LaneID != "" && rule.Trigger.LaneID != event.LaneID {
+			return false
+		}
+	default:
+		if rule.Trigger.LaneID != "" && rule.Trigger.LaneID != card.LaneID {
+			return false
+		}
+	}
+	if event.Trigger == RuleTriggerFieldChanged && rule.Trigger.Field != "" && !containsString(event.Changed, rule.Trigger.Field) {
+		return false
+	}
+	for _, c := range rule.Conditions {
+		if !c.matches(card) {
+			return false
+		}
+	}
+	return true
+}
+
+// changedRuleFields lists the fields and attributes that differ between two versions of a card
+func changedRuleFields(before, after *PlannerCard) []string {
+	changed := []string{}
+	keys := map[string]bool{}
+	for k := range before.Fields {
+		keys[k] = true
+	}
+	for k := range after.Fields {
+		keys[k] = true
+	}
+	for k := range keys {
+		if k != "moved_at" && k != "lane_id" && !reflect.DeepEqual(before.Fields[k], after.Fields[k]) {
+			changed = append(changed, k)
+		}
+	}
+	for _, k := range ruleAttributes {
+		if ruleValueString(cardRuleValue(before, k)) != ruleValueString(cardRuleValue(after, k)) {
+			changed = append(changed, k)
+		}
+	}
+	return changed
+}
+
+// resolveRuleValue turns "$now" and "$now+7d" style values into times
+func resolveRuleValue(v interface{}, now time.Time) (interface{}, error) {
+	s, ok := v.(string)
+	if !ok || !strings.HasPrefix(s, "$now") {
+		return v, nil
+	}
+	offset := strings.TrimPrefix(s, "$now")
+	if offset == "" {
+		return now, nil
+	}
+	if len(offset) < 3 || (offset[0] != '+' && offset[0] != '-') {
+		return nil, fmt.Errorf("%w: invalid time value %q", ErrInvalidRule, s)
+	}
+	n, err := strconv.Atoi(offset[1 : len(offset)-1])
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid time value %q", ErrInvalidRule, s)
+	}
+	if offset[0] == '-' {
+		n = -n
+	}
+	switch offset[len(offset)-1] {
+	case 'h':
+		return now.Add(time.Duration(n) * time.Hour), nil
+	case 'd':
+		return now.AddDate(0, 0, n), nil
+	}
+	return nil, fmt.Errorf("%w: invalid time value %q", ErrInvalidRule, s)
+}
+
+// setFieldPatch builds the card patch of a set_field action
+func setFieldPatch(action RuleAction, now time.Time) (CardPatch, error) {
+	value, err := resolveRuleValue(action.Value, now)
+	if err != nil {
+		return CardPatch{}, err
+	}
+	switch action.Field {
+	case "priority":
+		p := ruleValueString(value)
+		return CardPatch{Priority: &p}, nil
+	case "labels", "assignees":
+		list := []string{}
+		if items, ok := value.([]interface{}); ok {
+			for _, item := range items {
+				list = append(list, ruleValueString(item))
+			}
+		} else if s := ruleValueString(value); s != "" {
+			list = append(list, s)
+		}
+		if action.Field == "labels" {
+			return CardPatch{Labels: &list}, nil
+		}
+		return CardPatch{Assignees: &list}, nil
+	case "due_date", "start_date":
+		var t time.Time // zero clears the date
+		switch v := value.(type) {
+		case time.Time:
+			t = v
+		case string:
+			if v != "" {
+				if t, err = parseDate(v); err != nil {
+					return CardPatch{}, fmt.Errorf("%w: %s expects a date", ErrInvalidRule, action.Field)
+				}
+			}
+		}
+		if action.Field == "due_date" {
+			return CardPatch{DueDate: &t}, nil
+		}
+		return CardPatch{StartDate: &t}, nil
+	}
+	if t, ok := value.(time.Time); ok {
+		value = t.UTC().Format(time.RFC3339)
+	}
+	return CardPatch{Fields: map[string]interface{}{action.Field: value}}, nil
+}
+
+// executeRuleActions runs the actions of a rule on a card, logging failures instead of stopping the chain
+func executeRuleActions(ctx context.Context, plannerID string, rule *PlannerRule, card *PlannerCard, trigger string) {
+	now := time.Now()
+	for _, action := range rule.Actions {
+		var err error
+		switch action.Type {
+		case RuleActionSetField:
+			var patch CardPatch
+			if patch, err = setFieldPatch(action, now); err == nil {
+				_, err = PatchCard(ctx, card.ID, patch)
+			}
+		case RuleActionMoveToLane:
+			var lane *PlannerLane
+			lane, _, err = getLane(ctx, action.LaneID)
+			if err == nil && lane.ID != card.LaneID {
+				_, err = MoveCard(ctx, card.ID, lane.ID, len(lane.Cards)+1)
+			}
+		case RuleActionAddLabel:
+			labels := append(append([]string{}, card.Labels...), action.Label)
+			_, err = PatchCard(ctx, card.ID, CardPatch{Labels: &labels})
+		case RuleActionArchive:
+			_, err = ArchiveCard(ctx, card.ID)
+		case RuleActionPostWebhook:
+			enqueueRuleWebhook(action.URL, plannerID, rule, card, trigger)
+		}
+		if err != nil {
+			log.Printf("[ERROR] Rule %s action %s failed on card %s: %v", rule.ID, action.Type, card.ID, err)
+			continue
+		}
+		// Later actions see the card as changed by earlier ones
+		if updated, err := GetCard(ctx, card.ID); err == nil {
+			card = updated
+		}
+	}
+}
+
+// enqueueRuleWebhook queues a rule firing for delivery to a webhook URL. The card is encoded now,
+// so the webhook reports it as the rule saw it.
+func enqueueRuleWebhook(target, plannerID string, rule *PlannerRule, card *PlannerCard, trigger string) {
+	body, err := json.Marshal(map[string]interface{}{
+		"rule_id":    rule.ID,
+		"rule_name":  rule.Name,
+		"trigger":    trigger,
+		"planner_id": plannerID,
+		"card":       card,
+		"timestamp":  time.Now(),
+	})
+	if err != nil {
+		log.Printf("[ERROR] Failed to encode webhook for rule %s: %v", rule.ID, err)
+		return
+	}
+
+	startRuleWebhookWorkers.Do(func() {
+		for i := 0; i < ruleWebhookWorkers; i++ {
+			go func() {
+				for hook := range ruleWebhookQueue {
+					postRuleWebhook(hook)
+				}
+			}()
+		}
+	})
+	select {
+	case ruleWebhookQueue <- ruleWebhook{RuleID: rule.ID, URL: target, Body: body}:
+	default:
+		log.Printf("[ERROR] Webhook queue is full, dropping webhook for rule %s", rule.ID)
+	}
+}
+
+// postRuleWebhook posts a queued rule firing to its webhook URL
+func postRuleWebhook(hook ruleWebhook) {
+	resp, err := ruleWebhookClient.Post(hook.URL, "application/json", bytes.NewReader(hook.Body))
+	if err != nil {
+		log.Printf("[ERROR] Webhook for rule %s failed: %v", hook.RuleID, err)
+		return
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		log.Printf("[ERROR] Webhook for rule %s returned %s", hook.RuleID, resp.Status)
+	}
+}
+
+// runRule runs a rule's actions on a card unless the rule already fired for it in the current chain.
+// It reports false when the chain is too deep or would loop.
+func runRule(ctx context.Context, plannerID string, rule *PlannerRule, card *PlannerCard, trigger string) bool {
+	chain, _ := ctx.Value(ruleChainKey{}).(*ruleChain)
+	if chain == nil {
+		chain = &ruleChain{fired: map[string]bool{}}
+	}
+	key := rule.ID + "/" + card.ID
+	if chain.depth >= maxRuleDepth || chain.fired[key] {
+		log.Printf("[WARN] Rule %s skipped on card %s to prevent a loop", rule.ID, card.ID)
+		return false
+	}
+	chain.fired[key] = true
+
+	log.Printf("Running rule: id=%s, cardID=%s, trigger=%s", rule.ID, card.ID, trigger)
+	executeRuleActions(context.WithValue(ctx, ruleChainKey{}, &ruleChain{depth: chain.depth + 1, fired: chain.fired}),
+		plannerID, rule, card, trigger)
+	return true
+}
+
+// applyCardRules runs the planner's rules matching an event and reports whether any ran
+func applyCardRules(ctx context.Context, plannerID string, event ruleEvent) bool {
+	var planner Planner
+	opts := options.FindOne().SetProjection(bson.M{"id": 1, "rules": 1})
+	if err := plannerCollection.FindOne(ctx, bson.M{"id": plannerID}, opts).Decode(&planner); err != nil {
+		return false
+	}
+	ran := false
+	for i := range planner.Rules {
+		rule := &planner.Rules[i]
+		if !rule.Enabled || rule.Trigger.Type != event.Trigger {
+			continue
+		}
+		card, err := GetCard(ctx, event.CardID)
+		if err != nil || card.ArchivedAt != nil {
+			break
+		}
+		if rule.triggeredBy(event, card) && runRule(ctx, plannerID, rule, card, event.Trigger) {
+			ran = true
+		}
+	}
+	return ran
+}
+
+// fireCardRules applies the rules matching each event and returns the card as the rules left it
+func fireCardRules(ctx context.Context, plannerID string, card *PlannerCard, events ...ruleEvent) *PlannerCard {
+	ran := false
+	for _, event := range events {
+		if applyCardRules(ctx, plannerID, event) {
+			ran = true
+		}
+	}
+	if !ran {
+		return card
+	}
+	if updated, err := GetCard(ctx, card.ID); err == nil {
+		return updated
+	}
+	return card
+}
+
+// FireDueDateRules runs due_date_passed rules for cards whose due date has passed.
+// Each rule fires once per card and due date, even with several backend instances.
+func FireDueDateRules(ctx context.Context) error {
+	now := time.Now()
+	cur, err := plannerCollection.Find(ctx, bson.M{"rules": bson.M{"$elemMatch": bson.M{
+		"enabled": true, "trigger.type": RuleTriggerDueDatePassed,
+	}}})
+	if err != nil {
+		return err
+	}
+	defer cur.Close(ctx)
+
+	for cur.Next(ctx) {
+		var planner Planner
+		if err := cur.Decode(&planner); err != nil {
+			return err
+		}
+		for i := range planner.Rules {
+			rule := &planner.Rules[i]
+			for _, lane := range planner.Lanes {
+				for j := range lane.Cards {
+					card := &lane.Cards[j]
+					if card.ArchivedAt != nil || card.DueDate == nil || card.DueDate.After(now) {
+						continue
+					}
+					if !rule.triggeredBy(ruleEvent{Trigger: RuleTriggerDueDatePassed, CardID: card.ID}, card) {
+						continue
+					}
+					_, err := ruleFiringCollection.InsertOne(ctx, bson.M{
+						"rule_id": rule.ID, "card_id": card.ID, "due_date": card.DueDate, "fired_at": now,
+					})
+					if mongo.IsDuplicateKeyError(err) {
+						continue
+					}
+					if err != nil {
+						return err
+					}
+					runRule(ctx, planner.ID, rule, card, RuleTriggerDueDatePassed)
+				}
+			}
+		}
+	}
+	return cur.Err()
+}
+
+// RunRuleScheduler fires time-based rules every interval until ctx is done
+func RunRuleScheduler(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := FireDueDateRules(ctx); err != nil {
+			log.Printf("[ERROR] Failed to fire due date rules: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// SaveRule adds a rule to a planner, or replaces the rule with the same ID
+func SaveRule(ctx context.Context, plannerID string, rule PlannerRule) (*PlannerRule, error) {
+	log.Printf("Saving rule: plannerID=%s, ruleID=%s", plannerID, rule.ID)
+
+	planner, err := getPlannerColumns(ctx, plannerID)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateRule(planner, &rule); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	rule.UpdatedAt = now
+	if rule.ID == "" {
+		rule.ID = GenerateID()
+		rule.CreatedAt = now
+		_, err = plannerCollection.UpdateOne(ctx, bson.M{"id": plannerID},
+			bson.M{"$push": bson.M{"rules": rule}, "$set": bson.M{"updated_at": now}})
+	} else {
+		var existing *PlannerRule
+		for i := range planner.Rules {
+			if planner.Rules[i].ID == rule.ID {
+				existing = &planner.Rules[i]
+			}
+		}
+		if existing == nil {
+			return nil, mongo.ErrNoDocuments
+		}
+		rule.CreatedAt = existing.CreatedAt
+		_, err = plannerCollection.UpdateOne(ctx, bson.M{"id": plannerID, "rules.id": rule.ID},
+			bson.M{"$set": bson.M{"rules.$": rule, "updated_at": now}})
+	}
+	if err != nil {
+		return nil, err
+	}
+	publishEvent(ctx, plannerID, EventRulesUpdated, rule)
+	return &rule, nil
+}
+
+// DeleteRule removes a rule from a planner
+func DeleteRule(ctx context.Context, plannerID, ruleID string) error {
+	log.Printf("Deleting rule: plannerID=%s, ruleID=%s", plannerID, ruleID)
+
+	result, err := plannerCollection.UpdateOne(ctx, bson.M{"id": plannerID, "rules.id": ruleID},
+		bson.M{"$pull": bson.M{"rules": bson.M{"id": ruleID}}, "$set": bson.M{"updated_at": time.Now()}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	publishEvent(ctx, plannerID, EventRulesUpdated, bson.M{"id": ruleID, "deleted": true})
+	return nil
+}
+
+// ruleErrorStatus maps rule errors to HTTP status codes
+func ruleErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, ErrInvalidRule):
+		return http.StatusBadRequest
+	case errors.Is(err, mongo.ErrNoDocuments):
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
+
+// HandleRules handles GET, POST, PUT and DELETE on /planner/{id}/rules[/{ruleId}]
+func HandleRules(w http.ResponseWriter, r *http.Request) {
+	// Extract planner and rule IDs from path
+	path := r.URL.Path
+	parts := strings.Split(path, "/")
+	if len(parts) < 4 || len(parts) > 5 || parts[1] != "planner" || parts[3] != "rules" {
+		http.Error(w, "Invalid path", http.StatusBadRequest)
+		return
+	}
+	plannerID := parts[2]
+	ruleID := ""
+	if len(parts) == 5 {
+		ruleID = parts[4]
+	}
+
+	var response interface{}
+	switch {
+	case r.Method == http.MethodGet && ruleID == "":
+		planner, err := getPlannerColumns(r.Context(), plannerID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		rules := planner.Rules
+		if rules == nil {
+			rules = []PlannerRule{}
+		}
+		response = rules
+	case (r.Method == http.MethodPost && ruleID == "") || (r.Method == http.MethodPut && ruleID != ""):
+		var rule PlannerRule
+		if err := json.NewDecoder(r.Body).Decode(&rule); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		rule.ID = ruleID
+		saved, err := SaveRule(r.Context(), plannerID, rule)
+		if err != nil {
+			http.Error(w, err.Error(), ruleErrorStatus(err))
+			return
+		}
+		response = saved
+	case r.Method == http.MethodDelete && ruleID != "":
+		if err := DeleteRule(r.Context(), plannerID, ruleID); err != nil {
+			http.Error(w, err.Error(), ruleErrorStatus(err))
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,192 @@
+package planner
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRuleTriggeredBy(t *testing.T) {
+	due := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	card := &PlannerCard{
+		ID:       "c1",
+		LaneID:   "done",
+		Fields:   map[string]interface{}{"title": "Ship release", "points": 3.0},
+		Labels:   []string{"release", "backend"},
+		Priority: PriorityHigh,
+		DueDate:  &due,
+	}
+	rule := PlannerRule{
+		Enabled: true,
+		Trigger: RuleTrigger{Type: RuleTriggerCardEnteredLane, LaneID: "done"},
+		Conditions: []RuleCondition{
+			{Field: "labels", Op: RuleOpContains, Value: "release"},
+			{Field: "title", Op: RuleOpContains, Value: "ship"},
+			{Field: "points", Op: RuleOpEquals, Value: 3},
+			{Field: "due_date", Op: RuleOpEquals, Value: "2024-03-01"},
+			{Field: "assignees", Op: RuleOpEmpty},
+			{Field: "priority", Op: RuleOpNotEquals, Value: PriorityLow},
+		},
+	}
+	entered := ruleEvent{Trigger: RuleTriggerCardEnteredLane, CardID: "c1", LaneID: "done"}
+
+	if !rule.triggeredBy(entered, card) {
+		t.Fatal("expected rule to fire")
+	}
+	if rule.triggeredBy(ruleEvent{Trigger: RuleTriggerCardEnteredLane, CardID: "c1", LaneID: "todo"}, card) {
+		t.Error("rule fired for another lane")
+	}
+	if rule.triggeredBy(ruleEvent{Trigger: RuleTriggerCardLeftLane, CardID: "c1", LaneID: "done"}, card) {
+		t.Error("rule fired for another trigger")
+	}
+	rule.Conditions = append(rule.Conditions, RuleCondition{Field: "labels", Op: RuleOpContains, Value: "frontend"})
+	if rule.triggeredBy(entered, card) {
+		t.Error("rule fired although a condition does not match")
+	}
+	rule.Conditions = nil
+	rule.Enabled = false
+	if rule.triggeredBy(entered, card) {
+		t.Error("disabled rule fired")
+	}
+
+	changed := PlannerRule{Enabled: true, Trigger: RuleTrigger{Type: RuleTriggerFieldChanged, Field: "priority"}}
+	if !changed.triggeredBy(ruleEvent{Trigger: RuleTriggerFieldChanged, Changed: []string{"priority"}}, card) {
+		t.Error("expected field_changed rule to fire")
+	}
+	if changed.triggeredBy(ruleEvent{Trigger: RuleTriggerFieldChanged, Changed: []string{"title"}}, card) {
+		t.Error("field_changed rule fired for another field")
+	}
+}
+
+func TestChangedRuleFields(t *testing.T) {
+	before := &PlannerCard{Fields: map[string]interface{}{"title": "A", "status": "open"}, Labels: []string{"x"}}
+	after := &PlannerCard{Fields: map[string]interface{}{"title": "A", "moved_at": "now"}, Labels: []string{"x", "y"}}
+
+	changed := changedRuleFields(before, after)
+	if len(changed) != 2 || !containsString(changed, "status") || !containsString(changed, "labels") {
+		t.Errorf("unexpected changed fields: %v", changed)
+	}
+}
+
+func TestSetFieldPatch(t *testing.T) {
+	now := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+
+	patch, err := setFieldPatch(RuleAction{Field: "due_date", Value: "$now+7d"}, now)
+	if err != nil || patch.DueDate == nil || !patch.DueDate.Equal(now.AddDate(0, 0, 7)) {
+		t.Errorf("unexpected due date patch: %+v, %v", patch, err)
+	}
+	patch, err = setFieldPatch(RuleAction{Field: "resolved_at", Value: "$now-2h"}, now)
+	if err != nil || patch.Fields["resolved_at"] != now.Add(-2*time.Hour).Format(time.RFC3339) {
+		t.Errorf("unexpected field patch: %+v, %v", patch, err)
+	}
+	patch, err = setFieldPatch(RuleAction{Field: "labels", Value: []interface{}{"a", "b"}}, now)
+	if err != nil || patch.Labels == nil || len(*patch.Labels) != 2 {
+		t.Errorf("unexpected labels patch: %+v, %v", patch, err)
+	}
+	patch, err = setFieldPatch(RuleAction{Field: "start_date", Value: nil}, now)
+	if err != nil || patch.StartDate == nil || !patch.StartDate.IsZero() {
+		t.Errorf("expected start date to be cleared: %+v, %v", patch, err)
+	}
+	if _, err := setFieldPatch(RuleAction{Field: "due_date", Value: "$now+1w"}, now); !errors.Is(err, ErrInvalidRule) {
+		t.Errorf("expected ErrInvalidRule for unknown unit, got %v", err)
+	}
+}
+
+func TestValidateRule(t *testing.T) {
+	p := &Planner{
+		Lanes:   []PlannerLane{{ID: "todo"}, {ID: "done"}},
+		Columns: []PlannerColumn{{ID: "points", Name: "Points", Type: ColumnTypeNumber}},
+	}
+	valid := PlannerRule{
+		Name:    "Done",
+		Trigger: RuleTrigger{Type: RuleTriggerCardEnteredLane, LaneID: "done"},
+		Actions: []RuleAction{
+			{Type: RuleActionSetField, Field: "points", Value: 0},
+			{Type: RuleActionMoveToLane, LaneID: "todo"},
+			{Type: RuleActionPostWebhook, URL: "https://example.com/hook"},
+		},
+	}
+	if err := validateRule(p, &valid); err != nil {
+		t.Fatalf("expected valid rule, got %v", err)
+	}
+
+	cases := map[string]func(r *PlannerRule){
+		"no name":         func(r *PlannerRule) { r.Name = "" },
+		"unknown trigger": func(r *PlannerRule) { r.Trigger.Type = "card_created" },
+		"unknown lane":    func(r *PlannerRule) { r.Trigger.LaneID = "review" },
+		"unknown op":      func(r *PlannerRule) { r.Conditions = []RuleCondition{{Field: "title", Op: "matches"}} },
+		"no actions":      func(r *PlannerRule) { r.Actions = nil },
+		"unknown field":   func(r *PlannerRule) { r.Actions = []RuleAction{{Type: RuleActionSetField, Field: "effort"}} },
+		"bad webhook": func(r *PlannerRule) {
+			r.Actions = []RuleAction{{Type: RuleActionPostWebhook, URL: "file:///etc/passwd"}}
+		},
+		"loopback webhook": func(r *PlannerRule) {
+			r.Actions = []RuleAction{{Type: RuleActionPostWebhook, URL: "http://localhost:8080/hook"}}
+		},
+		"metadata webhook": func(r *PlannerRule) {
+			r.Actions = []RuleAction{{Type: RuleActionPostWebhook, URL: "http://169.254.169.254/latest/meta-data"}}
+		},
+		"private webhook": func(r *PlannerRule) {
+			r.Actions = []RuleAction{{Type: RuleActionPostWebhook, URL: "https://[fd00::1]/hook"}}
+		},
+	}
+	for name, mutate := range cases {
+		rule := valid
+		rule.Actions = append([]RuleAction{}, valid.Actions...)
+		mutate(&rule)
+		if err := validateRule(p, &rule); !errors.Is(err, ErrInvalidRule) {
+			t.Errorf("%s: expected ErrInvalidRule, got %v", name, err)
+		}
+	}
+}
+
+func TestRulesUsingField(t *testing.T) {
+	rules := []PlannerRule{
+		{ID: "trigger", Trigger: RuleTrigger{Type: RuleTriggerFieldChanged, Field: "col"}},
+		{ID: "condition", Conditions: []RuleCondition{{Field: "col", Op: "empty"}}},
+		{ID: "action", Actions: []RuleAction{{Type: RuleActionSetField, Field: "col"}}},
+		{ID: "other", Actions: []RuleAction{{Type: RuleActionSetField, Field: "priority"}}},
+	}
+	got := rulesUsingField(rules, "col")
+	if len(got) != 3 || got[0] != "trigger" || got[1] != "condition" || got[2] != "action" {
+		t.Errorf("unexpected rules %v", got)
+	}
+}
+
+func TestIsPublicWebhookIP(t *testing.T) {
+	for addr, want := range map[string]bool{
+		"93.184.216.34":   true,
+		"2606:4700::1111": true,
+		"127.0.0.1":       false,
+		"::1":             false,
+		"10.1.2.3":        false,
+		"172.16.0.1":      false,
+		"192.168.1.1":     false,
+		"169.254.169.254": false,
+		"100.64.0.1":      false,
+		"0.0.0.0":         false,
+		"fe80::1":         false,
+		"fd12::1":         false,
+		"::ffff:10.0.0.1": false,
+	} {
+		if got := isPublicWebhookIP(net.ParseIP(addr)); got != want {
+			t.Errorf("%s: expected %v, got %v", addr, want, got)
+		}
+	}
+}
+
+func TestRuleWebhookClientRefusesLoopback(t *testing.T) {
+	called := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer server.Close()
+
+	_, err := ruleWebhookClient.Post(server.URL, "application/json", nil)
+	if !errors.Is(err, ErrWebhookTargetBlocked) || called {
+		t.Errorf("expected the connection to be refused, got %v", err)
+	}
+}
@@ -0,0 +1,139 @@
+package planner
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// ErrInvalidSettings is returned when a planner settings update is malformed
+var ErrInvalidSettings = errors.New("invalid planner settings")
+
+// PlannerSettings holds per-planner behavior switches; zero values keep the default behavior
+type PlannerSettings struct {
+	WIPMode         string `json:"wip_mode,omitempty" bson:"wip_mode,omitempty"`                   // off (default), warn or block
+	SharedWIPLimits bool   `json:"shared_wip_limits,omitempty" bson:"shared_wip_limits,omitempty"` // split lanes share one limit
+
+	RejectBlockedDone bool `json:"reject_blocked_done,omitempty" bson:"reject_blocked_done,omitempty"` // blocked cards can't enter done lanes
+
+	StoryPointsColumn string `json:"story_points_column,omitempty" bson:"story_points_column,omitempty"` // number column holding story points
+}
+
+// PlannerSettingsPatch is a partial settings update; nil values are left unchanged
+type PlannerSettingsPatch struct {
+	WIPMode         *string `json:"wip_mode"`
+	SharedWIPLimits *bool   `json:"shared_wip_limits"`
+
+	RejectBlockedDone *bool `json:"reject_blocked_done"`
+
+	StoryPointsColumn *string `json:"story_points_column"`
+}
+
+// UpdatePlannerSettings applies a partial settings update and returns the resulting settings
+func UpdatePlannerSettings(ctx context.Context, plannerID string, patch PlannerSettingsPatch) (*PlannerSettings, error) {
+	log.Printf("Updating planner settings: plannerID=%s", plannerID)
+
+	set := bson.M{"updated_at": time.Now()}
+	if patch.WIPMode != nil {
+		switch *patch.WIPMode {
+		case WIPModeOff, WIPModeWarn, WIPModeBlock:
+			set["settings.wip_mode"] = *patch.WIPMode
+		default:
+			return nil, fmt.Errorf("%w: wip_mode must be off, warn or block", ErrInvalidSettings)
+		}
+	}
+	if patch.SharedWIPLimits != nil {
+		set["settings.shared_wip_limits"] = *patch.SharedWIPLimits
+	}
+	if patch.RejectBlockedDone != nil {
+		set["settings.reject_blocked_done"] = *patch.RejectBlockedDone
+	}
+	if patch.StoryPointsColumn != nil && *patch.StoryPointsColumn != "" {
+		planner, err := getPlannerColumns(ctx, plannerID)
+		if err != nil {
+			return nil, err
+		}
+		valid := false
+		for _, col := range planner.Columns {
+			valid = valid || (col.ID == *patch.StoryPointsColumn && col.Type == ColumnTypeNumber)
+		}
+		if !valid {
+			return nil, fmt.Errorf("%w: story_points_column must be a number column", ErrInvalidSettings)
+		}
+	}
+	if patch.StoryPointsColumn != nil {
+		set["settings.story_points_column"] = *patch.StoryPointsColumn
+	}
+
+	result, err := plannerCollection.UpdateOne(ctx, bson.M{"id": plannerID}, bson.M{"$set": set})
+	if err != nil {
+		return nil, err
+	}
+	if result.MatchedCount == 0 {
+		return nil, mongo.ErrNoDocuments
+	}
+
+	planner, err := getPlannerColumns(ctx, plannerID)
+	if err != nil {
+		return nil, err
+	}
+	publishEvent(ctx, plannerID, EventPlannerUpdated, bson.M{"id": plannerID, "settings": planner.Settings})
+	return &planner.Settings, nil
+}
+
+// HandlePlannerSettings handles GET and PUT /planner/{id}/settings
+func HandlePlannerSettings(w http.ResponseWriter, r *http.Request) {
+	// Extract planner ID from path
+	path := r.URL.Path
+	parts := strings.Split(path, "/")
+	if len(parts) != 4 || parts[1] != "planner" || parts[3] != "settings" {
+		http.Error(w, "Invalid path", http.StatusBadRequest)
+		return
+	}
+	plannerID := parts[2]
+
+	var settings *PlannerSettings
+	switch r.Method {
+	case http.MethodGet:
+		planner, err := getPlannerColumns(r.Context(), plannerID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		settings = &planner.Settings
+	case http.MethodPut:
+		var patch PlannerSettingsPatch
+		if err := json.NewDecoder(r.Body).Decode(&patch); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		var err error
+		settings, err = UpdatePlannerSettings(r.Context(), plannerID, patch)
+		if err != nil {
+			status := http.StatusInternalServerError
+			if errors.Is(err, ErrInvalidSettings) {
+				status = http.StatusBadRequest
+			} else if errors.Is(err, mongo.ErrNoDocuments) {
+				status = http.StatusNotFound
+			}
+			http.Error(w, err.Error(), status)
+			return
+		}
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(settings); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,369 @@
+package planner
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// SheetOptions filters the cards included in a spreadsheet export
+type SheetOptions struct {
+	LaneIDs   []string   // only these lanes; all lanes if empty
+	DateField string     // created_at (default), updated_at or due_date
+	From      *time.Time // inclusive
+	To        *time.Time // exclusive
+}
+
+// sheetCell is a spreadsheet cell holding either text or a number
+type sheetCell struct {
+	Text   string
+	Number *float64
+}
+
+// sheetFixedHeaders are the columns written before the planner's own columns
+var sheetFixedHeaders = []string{
+	"Lane", "Position", "Title", "Content", "Assignees", "Labels", "Priority",
+	"Start date", "Due date", "Checklist", "Created", "Updated",
+}
+
+// escapeSheetText neutralizes CSV text that spreadsheet applications would evaluate as a formula.
+// XLSX inline strings are never evaluated, so they are written as is.
+func escapeSheetText(s string) string {
+	if s == "" {
+		return s
+	}
+	switch s[0] {
+	case '=', '+', '-', '@', '\t', '\r':
+		return "'" + s
+	}
+	return s
+}
+
+func textCell(s string) sheetCell {
+	return sheetCell{Text: s}
+}
+
+func numberCell(n float64) sheetCell {
+	return sheetCell{Text: strconv.FormatFloat(n, 'f', -1, 64), Number: &n}
+}
+
+func timeCell(t *time.Time) sheetCell {
+	if t == nil || t.IsZero() {
+		return sheetCell{}
+	}
+	return sheetCell{Text: t.UTC().Format(time.RFC3339)}
+}
+
+// cardSheetDate returns the date a card is filtered on
+func cardSheetDate(card *PlannerCard, field string) *time.Time {
+	switch field {
+	case "updated_at":
+		return &card.UpdatedAt
+	case "due_date":
+		return card.DueDate
+	default:
+		return &card.CreatedAt
+	}
+}
+
+// buildPlannerSheet returns one header row and one row per card of a planner.
+// Values of planner columns get a column each, followed by any other field keys found on cards.
+func buildPlannerSheet(planner *Planner, opts SheetOptions) [][]sheetCell {
+	// Field keys without a column, e.g. moved_at; lane_id only repeats the lane
+	extraKeys := []string{}
+	columnIDs := map[string]bool{}
+	for _, col := range planner.Columns {
+		columnIDs[col.ID] = true
+	}
+	for _, lane := range planner.Lanes {
+		for _, card := range lane.Cards {
+			for key := range card.Fields {
+				if key == FieldTitle || key == FieldContent || key == "lane_id" || columnIDs[key] {
+					continue
+				}
+				if !containsString(extraKeys, key) {
+					extraKeys = append(extraKeys, key)
+				}
+			}
+		}
+	}
+	sort.Strings(extraKeys)
+
+	header := []sheetCell{}
+	for _, h := range sheetFixedHeaders {
+		header = append(header, textCell(h))
+	}
+	for _, col := range planner.Columns {
+		header = append(header, textCell(col.Name))
+	}
+	for _, key := range extraKeys {
+		header = append(header, textCell(key))
+	}
+	rows := [][]sheetCell{header}
+
+	for _, lane := range planner.Lanes {
+		if len(opts.LaneIDs) > 0 && !containsString(opts.LaneIDs, lane.ID) {
+			continue
+		}
+		for i := range lane.Cards {
+			card := &lane.Cards[i]
+			if opts.From != nil || opts.To != nil {
+				date := cardSheetDate(card, opts.DateField)
+				if date == nil || (opts.From != nil && date.Before(*opts.From)) || (opts.To != nil && !date.Before(*opts.To)) {
+					continue
+				}
+			}
+
+			title, _ := card.Fields[FieldTitle].(string)
+			content, _ := card.Fields[FieldContent].(string)
+			checklist := ""
+			if len(card.Checklist) > 0 {
+				done := 0
+				for _, item := range card.Checklist {
+					if item.Done {
+						done++
+					}
+				}
+				checklist = fmt.Sprintf("%d/%d", done, len(card.Checklist))
+			}
+			row := []sheetCell{
+				textCell(lane.Title),
+				numberCell(float64(card.Position)),
+				textCell(title),
+				textCell(content),
+				textCell(strings.Join(card.Assignees, ", ")),
+				textCell(strings.Join(card.Labels, ", ")),
+				textCell(card.Priority),
+				timeCell(card.StartDate),
+				timeCell(card.DueDate),
+				textCell(checklist),
+				timeCell(&card.CreatedAt),
+				timeCell(&card.UpdatedAt),
+			}
+			for _, col := range planner.Columns {
+				row = append(row, sheetValueCell(card.Fields[col.ID]))
+			}
+			for _, key := range extraKeys {
+				row = append(row, sheetValueCell(card.Fields[key]))
+			}
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
+
+// sheetValueCell converts a card field value to a cell
+func sheetValueCell(value interface{}) sheetCell {
+	if value == nil {
+		return sheetCell{}
+	}
+	if n, ok := toFloat(value); ok {
+		return numberCell(n)
+	}
+	return textCell(fmt.Sprint(value))
+}
+
+// writePlannerCSV writes sheet rows as CSV
+func writePlannerCSV(w io.Writer, rows [][]sheetCell) error {
+	writer := csv.NewWriter(w)
+	for _, row := range rows {
+		record := make([]string, len(row))
+		for i, cell := range row {
+			record[i] = cell.Text
+			if cell.Number == nil {
+				record[i] = escapeSheetText(cell.Text)
+			}
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// writePlannerXLSX writes sheet rows as a single-sheet Office Open XML workbook
+func writePlannerXLSX(w io.Writer, rows [][]sheetCell, sheetName string) error {
+	zw := zip.NewWriter(w)
+	files := []struct {
+		name    string
+		content string
+	}{
+		{"[Content_Types].xml", xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+			`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+			`<Default Extension="xml" ContentType="application/xml"/>` +
+			`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+			`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+			`</Types>`},
+		{"_rels/.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+			`</Relationships>`},
+		{"xl/workbook.xml", xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+			`<sheets><sheet name="` + xmlEscape(xlsxSheetName(sheetName)) + `" sheetId="1" r:id="rId1"/></sheets></workbook>`},
+		{"xl/_rels/workbook.xml.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+			`</Relationships>`},
+		{"xl/worksheets/sheet1.xml", xlsxSheet(rows)},
+	}
+	for _, f := range files {
+		fw, err := zw.Create(f.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(fw, f.content); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// xlsxSheet renders rows as worksheet XML; text is stored inline so no shared string table is needed
+func xlsxSheet(rows [][]sheetCell) string {
+	var b strings.Builder
+	b.WriteString(xml.Header)
+	b.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	for r, row := range rows {
+		fmt.Fprintf(&b, `<row r="%d">`, r+1)
+		for c, cell := range row {
+			ref := xlsxColumnName(c) + strconv.Itoa(r+1)
+			switch {
+			case cell.Number != nil:
+				fmt.Fprintf(&b, `<c r="%s"><v>%s</v></c>`, ref, cell.Text)
+			case cell.Text != "":
+				fmt.Fprintf(&b, `<c r="%s" t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`, ref, xmlEscape(cell.Text))
+			}
+		}
+		b.WriteString(`</row>`)
+	}
+	b.WriteString(`</sheetData></worksheet>`)
+	return b.String()
+}
+
+// xlsxColumnName converts a zero-based column index to A, B, ..., Z, AA, ...
+func xlsxColumnName(i int) string {
+	name := ""
+	for i >= 0 {
+		name = string(rune('A'+i%26)) + name
+		i = i/26 - 1
+	}
+	return name
+}
+
+// xlsxSheetName strips characters Excel doesn't allow in sheet names and truncates to 31 characters
+func xlsxSheetName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return -1
+		}
+		return r
+	}, name)
+	if runes := []rune(strings.TrimSpace(name)); len(runes) > 31 {
+		name = string(runes[:31])
+	}
+	if strings.TrimSpace(name) == "" {
+		return "Planner"
+	}
+	return name
+}
+
+// xmlEscape escapes text for XML and drops characters XML 1.0 can't represent
+func xmlEscape(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if r == '\t' || r == '\n' || r == '\r' || r >= 0x20 {
+			return r
+		}
+		return -1
+	}, s)
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+// ExportPlannerSheet writes a planner's cards as CSV or XLSX
+func ExportPlannerSheet(ctx context.Context, w io.Writer, id, format string, opts SheetOptions) error {
+	log.Printf("Exporting planner as %s with id=%s", format, id)
+	planner, err := GetPlanner(ctx, id)
+	if err != nil {
+		return err
+	}
+	rows := buildPlannerSheet(planner, opts)
+	if format == "xlsx" {
+		return writePlannerXLSX(w, rows, planner.Title)
+	}
+	return writePlannerCSV(w, rows)
+}
+
+// HandleExportPlannerSheet handles GET /planner/{id}/export?format={csv|xlsx}&lane_id={id}&date_field={field}&from={date}&to={date}
+func HandleExportPlannerSheet(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Extract ID from path
+	path := r.URL.Path
+	if len(path) <= len("/planner/") || !strings.HasSuffix(path, "/export") {
+		http.Error(w, "Invalid path", http.StatusBadRequest)
+		return
+	}
+	id := path[len("/planner/") : len(path)-len("/export")]
+
+	query := r.URL.Query()
+	opts := SheetOptions{DateField: query.Get("date_field")}
+	switch opts.DateField {
+	case "", "created_at", "updated_at", "due_date":
+	default:
+		http.Error(w, "date_field must be created_at, updated_at or due_date", http.StatusBadRequest)
+		return
+	}
+	for _, v := range query["lane_id"] {
+		for _, laneID := range strings.Split(v, ",") {
+			if laneID = strings.TrimSpace(laneID); laneID != "" {
+				opts.LaneIDs = append(opts.LaneIDs, laneID)
+			}
+		}
+	}
+	for name, target := range map[string]**time.Time{"from": &opts.From, "to": &opts.To} {
+		if v := query.Get(name); v != "" {
+			t, err := parseDate(v)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("invalid %s date: %v", name, err), http.StatusBadRequest)
+				return
+			}
+			*target = &t
+		}
+	}
+
+	// Render into memory first so errors can still be reported with a status code
+	format := query.Get("format")
+	var buf strings.Builder
+	if err := ExportPlannerSheet(r.Context(), &buf, id, format, opts); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			status = http.StatusNotFound
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	if format == "xlsx" {
+		w.Header().Set("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+		w.Header().Set("Content-Disposition", "attachment; filename=\"planner.xlsx\"")
+	} else {
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Header().Set("Content-Disposition", "attachment; filename=\"planner.csv\"")
+	}
+	io.WriteString(w, buf.String())
+}
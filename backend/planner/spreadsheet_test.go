@@ -0,0 +1,124 @@
+package planner
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func sheetFixture() *Planner {
+	created := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	return &Planner{
+		Title: "Ops: Q2 / Board",
+		Columns: []PlannerColumn{
+			{ID: "col-est", Name: "Estimate", Type: ColumnTypeNumber},
+			{ID: "col-note", Name: "Note", Type: ColumnTypeText},
+		},
+		Lanes: []PlannerLane{
+			{ID: "lane-a", Title: "Todo", Cards: []PlannerCard{
+				{
+					ID: "c1", Position: 1, CreatedAt: created, UpdatedAt: created,
+					Fields: map[string]interface{}{
+						FieldTitle:   "=HYPERLINK(\"http://evil\")",
+						FieldContent: "# Heading\n\n- item, with comma\n- \"quoted\"",
+						"col-est":    float64(-3),
+						"col-note":   "@SUM(A1)",
+						"moved_at":   "2024-03-02T00:00:00Z",
+					},
+					Labels: []string{"+1"},
+				},
+			}},
+			{ID: "lane-b", Title: "Done", Cards: []PlannerCard{
+				{ID: "c2", Position: 1, CreatedAt: created.AddDate(0, 1, 0), UpdatedAt: created,
+					Fields: map[string]interface{}{FieldTitle: "Later"}},
+			}},
+		},
+	}
+}
+
+func TestPlannerSheet_CSVEscapesFormulas(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writePlannerCSV(&buf, buildPlannerSheet(sheetFixture(), SheetOptions{})); err != nil {
+		t.Fatalf("writePlannerCSV failed: %v", err)
+	}
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("output is not valid CSV: %v", err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("expected header and 2 rows, got %d", len(records))
+	}
+
+	header := strings.Join(records[0], "|")
+	if !strings.HasSuffix(header, "|Estimate|Note|moved_at") {
+		t.Errorf("unexpected header %q", header)
+	}
+	row := records[1]
+	cells := map[string]string{}
+	for i, h := range records[0] {
+		cells[h] = row[i]
+	}
+	for name, want := range map[string]string{
+		"Lane":     "Todo",
+		"Title":    "'=HYPERLINK(\"http://evil\")",
+		"Content":  "# Heading\n\n- item, with comma\n- \"quoted\"",
+		"Labels":   "'+1",
+		"Estimate": "-3", // numbers are not escaped
+		"Note":     "'@SUM(A1)",
+		"Created":  "2024-03-01T09:00:00Z",
+	} {
+		if cells[name] != want {
+			t.Errorf("%s = %q, want %q", name, cells[name], want)
+		}
+	}
+}
+
+func TestPlannerSheet_Filters(t *testing.T) {
+	from := time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)
+	if rows := buildPlannerSheet(sheetFixture(), SheetOptions{LaneIDs: []string{"lane-a"}}); len(rows) != 2 || rows[1][0].Text != "Todo" {
+		t.Errorf("lane filter returned %d rows", len(rows))
+	}
+	if rows := buildPlannerSheet(sheetFixture(), SheetOptions{From: &from}); len(rows) != 2 || rows[1][0].Text != "Done" {
+		t.Errorf("date filter returned %d rows", len(rows))
+	}
+	if rows := buildPlannerSheet(sheetFixture(), SheetOptions{DateField: "due_date", From: &from}); len(rows) != 1 {
+		t.Errorf("cards without due date should be excluded, got %d rows", len(rows))
+	}
+}
+
+func TestPlannerSheet_XLSX(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writePlannerXLSX(&buf, buildPlannerSheet(sheetFixture(), SheetOptions{}), "Ops: Q2 / Board"); err != nil {
+		t.Fatalf("writePlannerXLSX failed: %v", err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("output is not a zip archive: %v", err)
+	}
+	files := map[string]string{}
+	for _, f := range zr.File {
+		rc, _ := f.Open()
+		data, _ := io.ReadAll(rc)
+		rc.Close()
+		files[f.Name] = string(data)
+	}
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels", "xl/worksheets/sheet1.xml"} {
+		if _, ok := files[name]; !ok {
+			t.Errorf("missing part %s", name)
+		}
+	}
+	if !strings.Contains(files["xl/workbook.xml"], `name="Ops Q2  Board"`) {
+		t.Errorf("sheet name not sanitized: %s", files["xl/workbook.xml"])
+	}
+	sheet := files["xl/worksheets/sheet1.xml"]
+	if !strings.Contains(sheet, `<c r="B2"><v>1</v></c>`) {
+		t.Errorf("position should be a numeric cell")
+	}
+	if !strings.Contains(sheet, `<t xml:space="preserve">=HYPERLINK(&#34;http://evil&#34;)</t>`) {
+		t.Errorf("title should be XML encoded without a formula escape")
+	}
+}
@@ -0,0 +1,685 @@
+package planner
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Sprint statuses
+const (
+	SprintStatusPlanned   = "planned"
+	SprintStatusActive    = "active"
+	SprintStatusCompleted = "completed"
+)
+
+var (
+	// ErrInvalidSprint is returned when a sprint definition is malformed
+	ErrInvalidSprint = errors.New("invalid sprint")
+	// ErrSprintState is returned when a sprint can't be started or completed in its current state
+	ErrSprintState = errors.New("sprint state conflict")
+)
+
+// Sprint is a time-boxed iteration of a planner. Cards join a sprint through their sprint_id.
+type Sprint struct {
+	ID          string     `json:"id" bson:"id"`
+	Name        string     `json:"name" bson:"name"`
+	Goal        string     `json:"goal,omitempty" bson:"goal,omitempty"`
+	StartDate   time.Time  `json:"start_date" bson:"start_date"`
+	EndDate     time.Time  `json:"end_date" bson:"end_date"`
+	Status      string     `json:"status" bson:"status"` // planned, active or completed
+	StartedAt   *time.Time `json:"started_at,omitempty" bson:"started_at,omitempty"`
+	CompletedAt *time.Time `json:"completed_at,omitempty" bson:"completed_at,omitempty"`
+
+	CommittedPoints float64          `json:"committed_points,omitempty" bson:"committed_points,omitempty"` // points in the sprint when it started
+	CompletedPoints float64          `json:"completed_points,omitempty" bson:"completed_points,omitempty"` // points done when it was completed
+	RolledOver      []SprintRollover `json:"rolled_over,omitempty" bson:"rolled_over,omitempty"`           // unfinished cards moved on at completion
+
+	CreatedAt time.Time `json:"created_at" bson:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" bson:"updated_at"`
+}
+
+// SprintRollover is an unfinished card moved out of a sprint when it was completed
+type SprintRollover struct {
+	CardID   string  `json:"card_id" bson:"card_id"`
+	Points   float64 `json:"points" bson:"points"`
+	SprintID string  `json:"sprint_id,omitempty" bson:"sprint_id,omitempty"` // empty when the card went back to the backlog
+}
+
+// SprintCompletion is the result of completing a sprint
+type SprintCompletion struct {
+	Sprint       *Sprint  `json:"sprint"`
+	NextSprintID string   `json:"next_sprint_id,omitempty"`
+	RolledOver   []string `json:"rolled_over"` // IDs of the unfinished cards
+}
+
+// BurndownPoint is the work left in a sprint at the end of a day
+type BurndownPoint struct {
+	Date      string   `json:"date"`
+	Remaining *float64 `json:"remaining"` // nil for days that haven't ended yet
+	Ideal     float64  `json:"ideal"`
+}
+
+// SprintBurndown is the daily remaining work of a sprint
+type SprintBurndown struct {
+	SprintID string          `json:"sprint_id"`
+	Unit     string          `json:"unit"` // points, or cards when the planner has no story points column
+	Total    float64         `json:"total"`
+	Days     []BurndownPoint `json:"days"`
+}
+
+// burndownCard is a card in the scope of a sprint
+type burndownCard struct {
+	ID     string
+	Points float64
+	Card   *flowCard // nil for cards that were deleted
+}
+
+// storyPointsColumn returns the planner's story points column: the one in the settings,
+// or else a number column named "Story Points" or "Points". It returns nil if there is none.
+func storyPointsColumn(planner *Planner) *PlannerColumn {
+	for i := range planner.Columns {
+		col := &planner.Columns[i]
+		if col.Type == ColumnTypeNumber && col.ID == planner.Settings.StoryPointsColumn {
+			return col
+		}
+	}
+	for i := range planner.Columns {
+		col := &planner.Columns[i]
+		name := strings.ToLower(strings.TrimSpace(col.Name))
+		if col.Type == ColumnTypeNumber && (name == "story points" || name == "points") {
+			return col
+		}
+	}
+	return nil
+}
+
+// cardPoints returns the estimate of a card; without a story points column every card counts as one
+func cardPoints(card *PlannerCard, col *PlannerColumn) float64 {
+	if col == nil {
+		return 1
+	}
+	n, _ := toFloat(card.Fields[col.ID])
+	return n
+}
+
+// findSprint returns the sprint of a planner with the given ID, or nil
+func findSprint(planner *Planner, sprintID string) *Sprint {
+	for i := range planner.Sprints {
+		if planner.Sprints[i].ID == sprintID {
+			return &planner.Sprints[i]
+		}
+	}
+	return nil
+}
+
+// validateSprint checks a sprint's name and dates
+func validateSprint(sprint *Sprint) error {
+	sprint.Name = strings.TrimSpace(sprint.Name)
+	if sprint.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidSprint)
+	}
+	if sprint.StartDate.IsZero() || sprint.EndDate.IsZero() {
+		return fmt.Errorf("%w: start_date and end_date are required", ErrInvalidSprint)
+	}
+	if sprint.EndDate.Before(sprint.StartDate) {
+		return fmt.Errorf("%w: end_date must not be before start_date", ErrInvalidSprint)
+	}
+	return nil
+}
+
+// checkSprintDates rejects changing the dates of a completed sprint, whose burndown and points are final
+func checkSprintDates(existing, sprint *Sprint) error {
+	if existing.Status != SprintStatusCompleted {
+		return nil
+	}
+	if !sprint.StartDate.Equal(existing.StartDate) || !sprint.EndDate.Equal(existing.EndDate) {
+		return fmt.Errorf("%w: the dates of a completed sprint can't change", ErrSprintState)
+	}
+	return nil
+}
+
+// sprintCompletionGuards returns filter conditions that only match while the sprint's cards are as they were
+// read: each still in the same lane and unchanged, the lane unchanged, and no other card added to the sprint.
+// Completing a sprint splits done from unfinished cards on that read.
+func sprintCompletionGuards(planner *Planner, sprintID string) []bson.M {
+	guards := []bson.M{}
+	cardIDs := []string{}
+	for _, lane := range planner.Lanes {
+		for _, card := range lane.Cards {
+			if card.SprintID != sprintID {
+				continue
+			}
+			cardIDs = append(cardIDs, card.ID)
+			guards = append(guards, bson.M{"lanes": bson.M{"$elemMatch": bson.M{
+				"id":         lane.ID,
+				"updated_at": lane.UpdatedAt,
+				"cards":      bson.M{"$elemMatch": bson.M{"id": card.ID, "sprint_id": sprintID, "updated_at": card.UpdatedAt}},
+			}}})
+		}
+	}
+	guards = append(guards, bson.M{"lanes.cards": bson.M{"$not": bson.M{"$elemMatch": bson.M{
+		"sprint_id": sprintID, "id": bson.M{"$nin": cardIDs},
+	}}}})
+	return guards
+}
+
+// sprintCards returns the active cards of a sprint and whether each is done
+func sprintCards(planner *Planner, sprintID string) (done, open []PlannerCard) {
+	for i := range planner.Lanes {
+		lane := &planner.Lanes[i]
+		for _, card := range lane.Cards {
+			if card.SprintID != sprintID || (card.ArchivedAt != nil && laneCategory(lane) != LaneCategoryDone) {
+				continue
+			}
+			if laneCategory(lane) == LaneCategoryDone {
+				done = append(done, card)
+			} else {
+				open = append(open, card)
+			}
+		}
+	}
+	return done, open
+}
+
+// nextSprint returns the planned sprint that starts first, other than the given one, or nil
+func nextSprint(planner *Planner, exceptID string) *Sprint {
+	var next *Sprint
+	for i := range planner.Sprints {
+		s := &planner.Sprints[i]
+		if s.ID == exceptID || s.Status != SprintStatusPlanned {
+			continue
+		}
+		if next == nil || s.StartDate.Before(next.StartDate) {
+			next = s
+		}
+	}
+	return next
+}
+
+// GetSprints returns the sprints of a planner ordered by start date
+func GetSprints(ctx context.Context, plannerID string) ([]Sprint, error) {
+	log.Printf("Getting sprints: plannerID=%s", plannerID)
+
+	planner, err := getPlannerColumns(ctx, plannerID)
+	if err != nil {
+		return nil, err
+	}
+	sprints := planner.Sprints
+	if sprints == nil {
+		sprints = []Sprint{}
+	}
+	sort.SliceStable(sprints, func(i, j int) bool {
+		return sprints[i].StartDate.Before(sprints[j].StartDate)
+	})
+	return sprints, nil
+}
+
+// SaveSprint creates a sprint, or replaces the name, goal and dates of an existing one
+func SaveSprint(ctx context.Context, plannerID string, sprint Sprint) (*Sprint, error) {
+	log.Printf("Saving sprint: plannerID=%s, sprintID=%s", plannerID, sprint.ID)
+
+	if err := validateSprint(&sprint); err != nil {
+		return nil, err
+	}
+	planner, err := getPlannerColumns(ctx, plannerID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	sprint.UpdatedAt = now
+	if sprint.ID == "" {
+		sprint = Sprint{
+			ID:        GenerateID(),
+			Name:      sprint.Name,
+			Goal:      sprint.Goal,
+			StartDate: sprint.StartDate,
+			EndDate:   sprint.EndDate,
+			Status:    SprintStatusPlanned,
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+		_, err = plannerCollection.UpdateOne(ctx, bson.M{"id": plannerID},
+			bson.M{"$push": bson.M{"sprints": sprint}, "$set": bson.M{"updated_at": now}})
+	} else {
+		existing := findSprint(planner, sprint.ID)
+		if existing == nil {
+			return nil, mongo.ErrNoDocuments
+		}
+		if err := checkSprintDates(existing, &sprint); err != nil {
+			return nil, err
+		}
+		updated := *existing
+		updated.Name = sprint.Name
+		updated.Goal = sprint.Goal
+		updated.StartDate = sprint.StartDate
+		updated.EndDate = sprint.EndDate
+		updated.UpdatedAt = now
+		sprint = updated
+		// Only replace the sprint in the state it was read in, so a concurrent start or completion isn't undone
+		var result *mongo.UpdateResult
+		result, err = plannerCollection.UpdateOne(ctx,
+			bson.M{"id": plannerID, "sprints": bson.M{"$elemMatch": bson.M{"id": sprint.ID, "status": existing.Status}}},
+			bson.M{"$set": bson.M{"sprints.$": sprint, "updated_at": now}})
+		if err == nil && result.MatchedCount == 0 {
+			err = fmt.Errorf("%w: sprint changed status, please retry", ErrSprintState)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	publishEvent(ctx, plannerID, EventSprintsUpdated, sprint)
+	return &sprint, nil
+}
+
+// DeleteSprint removes a sprint from a planner; its cards go back to the backlog
+func DeleteSprint(ctx context.Context, plannerID, sprintID string) error {
+	log.Printf("Deleting sprint: plannerID=%s, sprintID=%s", plannerID, sprintID)
+
+	result, err := plannerCollection.UpdateOne(ctx, bson.M{"id": plannerID, "sprints.id": sprintID},
+		bson.M{
+			"$pull":  bson.M{"sprints": bson.M{"id": sprintID}},
+			"$unset": bson.M{"lanes.$[].cards.$[elem].sprint_id": ""},
+			"$set":   bson.M{"updated_at": time.Now()},
+		},
+		options.Update().SetArrayFilters(options.ArrayFilters{
+			Filters: []interface{}{bson.M{"elem.sprint_id": sprintID}},
+		}),
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	publishEvent(ctx, plannerID, EventSprintsUpdated, bson.M{"id": sprintID, "deleted": true})
+	return nil
+}
+
+// StartSprint makes a planned sprint the planner's active sprint and records the points committed to it
+func StartSprint(ctx context.Context, plannerID, sprintID string) (*Sprint, error) {
+	log.Printf("Starting sprint: plannerID=%s, sprintID=%s", plannerID, sprintID)
+
+	planner, err := getPlannerColumns(ctx, plannerID)
+	if err != nil {
+		return nil, err
+	}
+	sprint := findSprint(planner, sprintID)
+	if sprint == nil {
+		return nil, mongo.ErrNoDocuments
+	}
+	if sprint.Status != SprintStatusPlanned {
+		return nil, fmt.Errorf("%w: only planned sprints can be started", ErrSprintState)
+	}
+
+	col := storyPointsColumn(planner)
+	done, open := sprintCards(planner, sprintID)
+	committed := 0.0
+	for _, card := range append(done, open...) {
+		committed += cardPoints(&card, col)
+	}
+
+	now := time.Now()
+	filter := bson.M{
+		"id":             plannerID,
+		"sprints":        bson.M{"$elemMatch": bson.M{"id": sprintID, "status": SprintStatusPlanned}},
+		"sprints.status": bson.M{"$ne": SprintStatusActive},
+	}
+	result, err := plannerCollection.UpdateOne(ctx, filter,
+		bson.M{"$set": bson.M{
+			"sprints.$[s].status":           SprintStatusActive,
+			"sprints.$[s].started_at":       now,
+			"sprints.$[s].committed_points": committed,
+			"sprints.$[s].updated_at":       now,
+			"updated_at":                    now,
+		}},
+		options.Update().SetArrayFilters(options.ArrayFilters{
+			Filters: []interface{}{bson.M{"s.id": sprintID}},
+		}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if result.MatchedCount == 0 {
+		return nil, fmt.Errorf("%w: another sprint is already active", ErrSprintState)
+	}
+
+	sprint.Status = SprintStatusActive
+	sprint.StartedAt = &now
+	sprint.CommittedPoints = committed
+	sprint.UpdatedAt = now
+	publishEvent(ctx, plannerID, EventSprintStarted, sprint)
+	return sprint, nil
+}
+
+// CompleteSprint completes the active sprint. Unfinished cards move to nextSprintID, or else to the planned sprint
+// that starts first; when there is none they go back to the backlog.
+func CompleteSprint(ctx context.Context, plannerID, sprintID, nextSprintID string) (*SprintCompletion, error) {
+	log.Printf("Completing sprint: plannerID=%s, sprintID=%s, nextSprintID=%s", plannerID, sprintID, nextSprintID)
+
+	planner, err := getPlannerColumns(ctx, plannerID)
+	if err != nil {
+		return nil, err
+	}
+	sprint := findSprint(planner, sprintID)
+	if sprint == nil {
+		return nil, mongo.ErrNoDocuments
+	}
+	if sprint.Status != SprintStatusActive {
+		return nil, fmt.Errorf("%w: only the active sprint can be completed", ErrSprintState)
+	}
+	var next *Sprint
+	if nextSprintID != "" {
+		next = findSprint(planner, nextSprintID)
+		if next == nil || next.ID == sprintID || next.Status != SprintStatusPlanned {
+			return nil, fmt.Errorf("%w: next sprint must be a planned sprint", ErrInvalidSprint)
+		}
+	} else {
+		next = nextSprint(planner, sprintID)
+	}
+
+	col := storyPointsColumn(planner)
+	done, open := sprintCards(planner, sprintID)
+	completed := 0.0
+	for _, card := range done {
+		completed += cardPoints(&card, col)
+	}
+	completion := &SprintCompletion{Sprint: sprint, RolledOver: []string{}}
+	rolledOver := []SprintRollover{}
+	for _, card := range open {
+		rollover := SprintRollover{CardID: card.ID, Points: cardPoints(&card, col)}
+		if next != nil {
+			rollover.SprintID = next.ID
+		}
+		rolledOver = append(rolledOver, rollover)
+		completion.RolledOver = append(completion.RolledOver, card.ID)
+	}
+
+	now := time.Now()
+	update := bson.M{"$set": bson.M{
+		"sprints.$[s].status":           SprintStatusCompleted,
+		"sprints.$[s].completed_at":     now,
+		"sprints.$[s].completed_points": completed,
+		"sprints.$[s].rolled_over":      rolledOver,
+		"sprints.$[s].updated_at":       now,
+		"updated_at":                    now,
+	}}
+	if next != nil {
+		update["$set"].(bson.M)["lanes.$[].cards.$[elem].sprint_id"] = next.ID
+		completion.NextSprintID = next.ID
+	} else {
+		update["$unset"] = bson.M{"lanes.$[].cards.$[elem].sprint_id": ""}
+	}
+	filter := bson.M{
+		"id":      plannerID,
+		"sprints": bson.M{"$elemMatch": bson.M{"id": sprintID, "status": SprintStatusActive}},
+		"$and":    sprintCompletionGuards(planner, sprintID),
+	}
+	result, err := plannerCollection.UpdateOne(ctx, filter, update,
+		options.Update().SetArrayFilters(options.ArrayFilters{
+			Filters: []interface{}{
+				bson.M{"s.id": sprintID},
+				bson.M{"elem.id": bson.M{"$in": completion.RolledOver}},
+			},
+		}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if result.MatchedCount == 0 {
+		return nil, fmt.Errorf("%w: sprint is no longer active or its cards changed, please retry", ErrSprintState)
+	}
+
+	sprint.Status = SprintStatusCompleted
+	sprint.CompletedAt = &now
+	sprint.CompletedPoints = completed
+	sprint.RolledOver = rolledOver
+	sprint.UpdatedAt = now
+	publishEvent(ctx, plannerID, EventSprintCompleted, completion)
+	return completion, nil
+}
+
+// sprintBurndown computes the remaining work of a sprint at the end of each of its days, up to now.
+// transitions holds the lane transitions of each card, sorted by timestamp.
+func sprintBurndown(sprint *Sprint, scope []burndownCard, transitions map[string][]CardTransition, now time.Time) (float64, []BurndownPoint) {
+	day := func(t time.Time) time.Time {
+		t = t.UTC()
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	}
+	start, end := day(sprint.StartDate), day(sprint.EndDate)
+	last := now
+	if sprint.CompletedAt != nil && sprint.CompletedAt.Before(last) {
+		last = *sprint.CompletedAt
+	}
+
+	total := sprint.CommittedPoints
+	if sprint.StartedAt == nil {
+		total = 0
+		for _, c := range scope {
+			total += c.Points
+		}
+	}
+	days := int(end.Sub(start).Hours()/24) + 1
+
+	points := []BurndownPoint{}
+	for i := 0; i < days; i++ {
+		d := start.AddDate(0, 0, i)
+		point := BurndownPoint{Date: d.Format("2006-01-02"), Ideal: total}
+		if days > 1 {
+			point.Ideal = math.Round(total*(1-float64(i)/float64(days-1))*100) / 100
+		}
+		if !d.After(last) {
+			dayEnd := d.AddDate(0, 0, 1)
+			if dayEnd.After(last) {
+				dayEnd = last
+			}
+			remaining := 0.0
+			for _, c := range scope {
+				history := transitions[c.ID]
+				var created time.Time
+				switch {
+				case c.Card != nil:
+					created = c.Card.CreatedAt
+				case len(history) > 0:
+					created = history[0].Timestamp
+				}
+				category := categoryBefore(c.Card, history, created, dayEnd)
+				if category != "" && category != LaneCategoryDone {
+					remaining += c.Points
+				}
+			}
+			point.Remaining = &remaining
+		}
+		points = append(points, point)
+	}
+	return total, points
+}
+
+// GetSprintBurndown returns the daily remaining work of a sprint. The scope is the sprint's current cards plus
+// the unfinished cards rolled over when it was completed.
+func GetSprintBurndown(ctx context.Context, plannerID, sprintID string) (*SprintBurndown, error) {
+	log.Printf("Getting sprint burndown: plannerID=%s, sprintID=%s", plannerID, sprintID)
+
+	planner, err := loadPlanner(ctx, plannerID, true)
+	if err != nil {
+		return nil, err
+	}
+	sprint := findSprint(planner, sprintID)
+	if sprint == nil {
+		return nil, mongo.ErrNoDocuments
+	}
+	col := storyPointsColumn(planner)
+
+	scope := []burndownCard{}
+	cardIDs := []string{}
+	inScope := map[string]bool{}
+	for _, r := range sprint.RolledOver {
+		scope = append(scope, burndownCard{ID: r.CardID, Points: r.Points})
+		cardIDs = append(cardIDs, r.CardID)
+		inScope[r.CardID] = true
+	}
+	for i := range planner.Lanes {
+		lane := &planner.Lanes[i]
+		for j := range lane.Cards {
+			card := &lane.Cards[j]
+			fc := &flowCard{CreatedAt: card.CreatedAt, ArchivedAt: card.ArchivedAt, Category: laneCategory(lane)}
+			if inScope[card.ID] {
+				for k := range scope {
+					if scope[k].ID == card.ID {
+						scope[k].Card = fc
+					}
+				}
+				continue
+			}
+			if card.SprintID == sprintID {
+				scope = append(scope, burndownCard{ID: card.ID, Points: cardPoints(card, col), Card: fc})
+				cardIDs = append(cardIDs, card.ID)
+			}
+		}
+	}
+
+	transitions := map[string][]CardTransition{}
+	if len(cardIDs) > 0 {
+		cur, err := cardTransitionCollection.Find(ctx, bson.M{"card_id": bson.M{"$in": cardIDs}},
+			options.Find().SetSort(bson.D{{Key: "timestamp", Value: 1}}))
+		if err != nil {
+			return nil, err
+		}
+		defer cur.Close(ctx)
+		var list []CardTransition
+		if err := cur.All(ctx, &list); err != nil {
+			return nil, err
+		}
+		for _, tr := range list {
+			transitions[tr.CardID] = append(transitions[tr.CardID], tr)
+		}
+	}
+
+	burndown := &SprintBurndown{SprintID: sprintID, Unit: "points"}
+	burndown.Total, burndown.Days = sprintBurndown(sprint, scope, transitions, time.Now())
+	if col == nil {
+		burndown.Unit = "cards"
+	}
+	return burndown, nil
+}
+
+// sprintErrorStatus maps sprint errors to HTTP status codes
+func sprintErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, ErrInvalidSprint):
+		return http.StatusBadRequest
+	case errors.Is(err, ErrSprintState):
+		return http.StatusConflict
+	case errors.Is(err, mongo.ErrNoDocuments):
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
+
+// sprintRequest is the JSON body of sprint create and update requests
+type sprintRequest struct {
+	Name      string `json:"name"`
+	Goal      string `json:"goal"`
+	StartDate string `json:"start_date"`
+	EndDate   string `json:"end_date"`
+}
+
+// toSprint parses the dates of a sprint request
+func (r sprintRequest) toSprint() (Sprint, error) {
+	sprint := Sprint{Name: r.Name, Goal: r.Goal}
+	var err error
+	if r.StartDate != "" {
+		if sprint.StartDate, err = parseDate(r.StartDate); err != nil {
+			return sprint, fmt.Errorf("%w: start_date must be a date", ErrInvalidSprint)
+		}
+	}
+	if r.EndDate != "" {
+		if sprint.EndDate, err = parseDate(r.EndDate); err != nil {
+			return sprint, fmt.Errorf("%w: end_date must be a date", ErrInvalidSprint)
+		}
+	}
+	return sprint, nil
+}
+
+// HandleSprints handles /planner/{id}/sprints[/{sprintId}[/start|/complete|/burndown]]
+func HandleSprints(w http.ResponseWriter, r *http.Request) {
+	// Extract planner and sprint IDs from path
+	path := r.URL.Path
+	parts := strings.Split(path, "/")
+	if len(parts) < 4 || len(parts) > 6 || parts[1] != "planner" || parts[3] != "sprints" {
+		http.Error(w, "Invalid path", http.StatusBadRequest)
+		return
+	}
+	plannerID := parts[2]
+	sprintID, action := "", ""
+	if len(parts) >= 5 {
+		sprintID = parts[4]
+	}
+	if len(parts) == 6 {
+		action = parts[5]
+	}
+
+	var response interface{}
+	var err error
+	switch {
+	case r.Method == http.MethodGet && sprintID == "":
+		response, err = GetSprints(r.Context(), plannerID)
+	case (r.Method == http.MethodPost && sprintID == "") || (r.Method == http.MethodPut && sprintID != "" && action == ""):
+		var request sprintRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		var sprint Sprint
+		if sprint, err = request.toSprint(); err == nil {
+			sprint.ID = sprintID
+			response, err = SaveSprint(r.Context(), plannerID, sprint)
+		}
+	case r.Method == http.MethodDelete && sprintID != "" && action == "":
+		if err := DeleteSprint(r.Context(), plannerID, sprintID); err != nil {
+			http.Error(w, err.Error(), sprintErrorStatus(err))
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return
+	case r.Method == http.MethodPost && action == "start":
+		response, err = StartSprint(r.Context(), plannerID, sprintID)
+	case r.Method == http.MethodPost && action == "complete":
+		var request struct {
+			NextSprintID string `json:"next_sprint_id"`
+		}
+		if r.ContentLength != 0 {
+			if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+		response, err = CompleteSprint(r.Context(), plannerID, sprintID, request.NextSprintID)
+	case r.Method == http.MethodGet && action == "burndown":
+		response, err = GetSprintBurndown(r.Context(), plannerID, sprintID)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), sprintErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
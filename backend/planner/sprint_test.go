@@ -0,0 +1,133 @@
+package planner
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func sprintFixture() *Planner {
+	return &Planner{
+		Columns: []PlannerColumn{{ID: "sp", Name: "Story Points", Type: ColumnTypeNumber}},
+		Sprints: []Sprint{
+			{ID: "s1", Status: SprintStatusActive, StartDate: time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)},
+			{ID: "s3", Status: SprintStatusPlanned, StartDate: time.Date(2024, 4, 29, 0, 0, 0, 0, time.UTC)},
+			{ID: "s2", Status: SprintStatusPlanned, StartDate: time.Date(2024, 4, 15, 0, 0, 0, 0, time.UTC)},
+		},
+		Lanes: []PlannerLane{
+			{ID: "todo", Category: LaneCategoryTodo, Cards: []PlannerCard{
+				{ID: "c1", SprintID: "s1", Fields: map[string]interface{}{"sp": 3.0}},
+				{ID: "c2", SprintID: "s2", Fields: map[string]interface{}{"sp": 5.0}},
+			}},
+			{ID: "done", Title: "Done", Cards: []PlannerCard{
+				{ID: "c3", SprintID: "s1", Fields: map[string]interface{}{"sp": 2.0}},
+			}},
+		},
+	}
+}
+
+func TestSprintCards(t *testing.T) {
+	p := sprintFixture()
+	done, open := sprintCards(p, "s1")
+	if len(done) != 1 || done[0].ID != "c3" || len(open) != 1 || open[0].ID != "c1" {
+		t.Errorf("unexpected sprint cards: done=%v open=%v", done, open)
+	}
+	col := storyPointsColumn(p)
+	if col == nil || cardPoints(&open[0], col) != 3 {
+		t.Errorf("expected story points from the Story Points column, got %v", col)
+	}
+	if cardPoints(&open[0], nil) != 1 {
+		t.Error("without a points column every card should count as one")
+	}
+	if next := nextSprint(p, "s1"); next == nil || next.ID != "s2" {
+		t.Errorf("expected s2 to be next, got %v", next)
+	}
+}
+
+func TestValidateSprint(t *testing.T) {
+	start := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+	valid := Sprint{Name: " Sprint 1 ", StartDate: start, EndDate: start.AddDate(0, 0, 13)}
+	if err := validateSprint(&valid); err != nil || valid.Name != "Sprint 1" {
+		t.Errorf("expected valid sprint, got %v (%q)", err, valid.Name)
+	}
+	for name, s := range map[string]Sprint{
+		"no name":  {StartDate: start, EndDate: start},
+		"no dates": {Name: "x"},
+		"reversed": {Name: "x", StartDate: start, EndDate: start.AddDate(0, 0, -1)},
+	} {
+		if err := validateSprint(&s); !errors.Is(err, ErrInvalidSprint) {
+			t.Errorf("%s: expected ErrInvalidSprint, got %v", name, err)
+		}
+	}
+}
+
+func TestSprintBurndown(t *testing.T) {
+	at := func(d, h int) time.Time { return time.Date(2024, 4, d, h, 0, 0, 0, time.UTC) }
+	started := at(1, 9)
+	sprint := &Sprint{StartDate: at(1, 0), EndDate: at(5, 0), StartedAt: &started, CommittedPoints: 8}
+	scope := []burndownCard{
+		{ID: "a", Points: 3, Card: &flowCard{CreatedAt: at(1, 8), Category: LaneCategoryDone}},
+		{ID: "b", Points: 5, Card: &flowCard{CreatedAt: at(1, 8), Category: LaneCategoryInProgress}},
+	}
+	transitions := map[string][]CardTransition{
+		"a": {
+			{CardID: "a", ToLaneID: "todo", ToCategory: LaneCategoryTodo, Timestamp: at(1, 8)},
+			{CardID: "a", FromLaneID: "todo", FromCategory: LaneCategoryTodo, ToLaneID: "done", ToCategory: LaneCategoryDone, Timestamp: at(2, 15)},
+		},
+	}
+
+	total, days := sprintBurndown(sprint, scope, transitions, at(3, 12))
+	if total != 8 || len(days) != 5 {
+		t.Fatalf("expected 5 days with 8 points, got %d days with %v", len(days), total)
+	}
+	if days[0].Ideal != 8 || days[2].Ideal != 4 || days[4].Ideal != 0 {
+		t.Errorf("unexpected ideal line: %+v", days)
+	}
+	want := []float64{8, 5, 5}
+	for i, w := range want {
+		if days[i].Remaining == nil || *days[i].Remaining != w {
+			t.Errorf("day %d: expected %v remaining, got %v", i+1, w, days[i].Remaining)
+		}
+	}
+	if days[3].Remaining != nil || days[4].Remaining != nil {
+		t.Error("future days should have no remaining value")
+	}
+}
+
+func TestSprintCompletionGuards(t *testing.T) {
+	guards := sprintCompletionGuards(sprintFixture(), "s1")
+	if len(guards) != 3 {
+		t.Fatalf("expected a guard per sprint card and one against new cards, got %d", len(guards))
+	}
+	lane := guards[0]["lanes"].(bson.M)["$elemMatch"].(bson.M)
+	card := lane["cards"].(bson.M)["$elemMatch"].(bson.M)
+	if lane["id"] != "todo" || card["id"] != "c1" || card["sprint_id"] != "s1" {
+		t.Errorf("expected c1 pinned to todo, got %v", lane)
+	}
+	if lane := guards[1]["lanes"].(bson.M)["$elemMatch"].(bson.M); lane["id"] != "done" {
+		t.Errorf("expected c3 pinned to done, got %v", lane)
+	}
+	others := guards[2]["lanes.cards"].(bson.M)["$not"].(bson.M)["$elemMatch"].(bson.M)
+	if ids := others["id"].(bson.M)["$nin"].([]string); len(ids) != 2 || ids[0] != "c1" || ids[1] != "c3" {
+		t.Errorf("expected only c1 and c3 in the sprint, got %v", others)
+	}
+}
+
+func TestCheckSprintDates(t *testing.T) {
+	start := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, 13)
+	completed := &Sprint{ID: "s1", Status: SprintStatusCompleted, StartDate: start, EndDate: end}
+
+	if err := checkSprintDates(completed, &Sprint{Name: "Renamed", StartDate: start, EndDate: end}); err != nil {
+		t.Errorf("renaming a completed sprint should be allowed: %v", err)
+	}
+	if err := checkSprintDates(completed, &Sprint{StartDate: start, EndDate: end.AddDate(0, 0, 1)}); !errors.Is(err, ErrSprintState) {
+		t.Errorf("expected ErrSprintState for a new end date, got %v", err)
+	}
+	active := &Sprint{ID: "s2", Status: SprintStatusActive, StartDate: start, EndDate: end}
+	if err := checkSprintDates(active, &Sprint{StartDate: start.AddDate(0, 0, 1), EndDate: end}); err != nil {
+		t.Errorf("an active sprint's dates can change: %v", err)
+	}
+}
@@ -0,0 +1,284 @@
+package planner
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Swimlane groupings; any other group_by value is the ID of a planner column
+const (
+	GroupByAssignee = "assignee"
+	GroupByPriority = "priority"
+	GroupByLabel    = "label"
+	GroupByNone     = "none" // disables a saved view's grouping for one request
+)
+
+// ErrInvalidGroupBy is returned for an unknown swimlane grouping
+var ErrInvalidGroupBy = errors.New("invalid group_by")
+
+// Swimlane is a horizontal row of a grouped planner. Cards without a value are in the swimlane with an empty key.
+type Swimlane struct {
+	Key   string         `json:"key"`
+	Title string         `json:"title"`
+	Lanes []SwimlaneCell `json:"lanes"`
+}
+
+// SwimlaneCell holds the cards of one lane within a swimlane
+type SwimlaneCell struct {
+	LaneID string        `json:"lane_id"`
+	Cards  []PlannerCard `json:"cards"`
+}
+
+// resolveGroupBy checks a grouping and returns the column it refers to, if any
+func resolveGroupBy(planner *Planner, groupBy string) (*PlannerColumn, error) {
+	switch groupBy {
+	case GroupByAssignee, GroupByPriority, GroupByLabel:
+		return nil, nil
+	}
+	for i := range planner.Columns {
+		if planner.Columns[i].ID == groupBy {
+			return &planner.Columns[i], nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %q is not assignee, priority, label or a column ID", ErrInvalidGroupBy, groupBy)
+}
+
+// swimlaneKey returns the swimlane a card belongs to. Multi-valued attributes group by their first value.
+func swimlaneKey(card *PlannerCard, groupBy string) string {
+	switch groupBy {
+	case GroupByAssignee:
+		if len(card.Assignees) > 0 {
+			return card.Assignees[0]
+		}
+		return ""
+	case GroupByPriority:
+		return card.Priority
+	case GroupByLabel:
+		if len(card.Labels) > 0 {
+			return card.Labels[0]
+		}
+		return ""
+	}
+	switch v := card.Fields[groupBy].(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// GroupPlanner buckets the cards of a planner by swimlane and lane.
+// Lanes keep their metadata but their cards move into the swimlane cells.
+func GroupPlanner(planner *Planner, groupBy string) error {
+	col, err := resolveGroupBy(planner, groupBy)
+	if err != nil {
+		return err
+	}
+
+	// Swimlanes with a known set of values are always listed so cards can be dropped into empty rows
+	keys := []string{}
+	seen := map[string]bool{"": true}
+	addKey := func(key string) {
+		if !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+	switch {
+	case groupBy == GroupByAssignee:
+		for _, id := range PlannerMemberIDs(planner) {
+			addKey(id)
+		}
+	case groupBy == GroupByPriority:
+		for _, p := range []string{PriorityUrgent, PriorityHigh, PriorityMedium, PriorityLow} {
+			addKey(p)
+		}
+	case col != nil && col.Type == ColumnTypeStatus:
+		for _, o := range col.Options {
+			addKey(o)
+		}
+	}
+	fixed := len(keys)
+	for i := range planner.Lanes {
+		for j := range planner.Lanes[i].Cards {
+			addKey(swimlaneKey(&planner.Lanes[i].Cards[j], groupBy))
+		}
+	}
+	extra := keys[fixed:]
+	if col != nil && col.Type == ColumnTypeNumber {
+		sort.Slice(extra, func(a, b int) bool {
+			x, _ := strconv.ParseFloat(extra[a], 64)
+			y, _ := strconv.ParseFloat(extra[b], 64)
+			return x < y
+		})
+	} else {
+		sort.Strings(extra)
+	}
+	keys = append(keys, "")
+
+	name := groupBy
+	if col != nil {
+		name = col.Name
+	}
+	index := map[string]int{}
+	planner.Swimlanes = []Swimlane{}
+	for i, key := range keys {
+		index[key] = i
+		title := key
+		if key == "" {
+			title = "No " + name
+		}
+		row := Swimlane{Key: key, Title: title, Lanes: []SwimlaneCell{}}
+		for _, lane := range planner.Lanes {
+			row.Lanes = append(row.Lanes, SwimlaneCell{LaneID: lane.ID, Cards: []PlannerCard{}})
+		}
+		planner.Swimlanes = append(planner.Swimlanes, row)
+	}
+
+	for i := range planner.Lanes {
+		for _, card := range planner.Lanes[i].Cards {
+			cell := &planner.Swimlanes[index[swimlaneKey(&card, groupBy)]].Lanes[i]
+			cell.Cards = append(cell.Cards, card)
+		}
+		planner.Lanes[i].Cards = []PlannerCard{}
+	}
+	planner.GroupBy = groupBy
+	return nil
+}
+
+// replaceFirst puts value in front of a list in place of its current first entry; an empty value clears the list
+func replaceFirst(values []string, value string) []string {
+	if value == "" {
+		return []string{}
+	}
+	result := []string{value}
+	for i, v := range values {
+		if i > 0 && v != value {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+// swimlanePatch returns the change that moves a card into the swimlane with the given key
+func swimlanePatch(card *PlannerCard, groupBy string, col *PlannerColumn, key string) (CardPatch, error) {
+	switch groupBy {
+	case GroupByAssignee:
+		assignees := replaceFirst(card.Assignees, key)
+		return CardPatch{Assignees: &assignees}, nil
+	case GroupByPriority:
+		return CardPatch{Priority: &key}, nil
+	case GroupByLabel:
+		labels := replaceFirst(card.Labels, key)
+		return CardPatch{Labels: &labels}, nil
+	}
+	var value interface{}
+	if key != "" {
+		value = key
+		if col.Type == ColumnTypeNumber {
+			n, err := strconv.ParseFloat(key, 64)
+			if err != nil {
+				return CardPatch{}, fmt.Errorf("%w: column %q expects a number", ErrInvalidCardField, col.Name)
+			}
+			value = n
+		}
+	}
+	return CardPatch{Fields: map[string]interface{}{col.ID: value}}, nil
+}
+
+// SetSwimlaneGroupBy stores the planner's default grouping, returned as swimlane_group_by; empty clears it
+func SetSwimlaneGroupBy(ctx context.Context, plannerID, groupBy string) error {
+	log.Printf("Setting swimlane grouping: plannerID=%s, groupBy=%s", plannerID, groupBy)
+
+	update := bson.M{"$unset": bson.M{"swimlane_group_by": ""}, "$set": bson.M{"updated_at": time.Now()}}
+	if groupBy != "" && groupBy != GroupByNone {
+		planner, err := getPlannerColumns(ctx, plannerID)
+		if err != nil {
+			return err
+		}
+		if _, err := resolveGroupBy(planner, groupBy); err != nil {
+			return err
+		}
+		update = bson.M{"$set": bson.M{"swimlane_group_by": groupBy, "updated_at": time.Now()}}
+	}
+
+	result, err := plannerCollection.UpdateOne(ctx, bson.M{"id": plannerID}, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	publishEvent(ctx, plannerID, EventPlannerUpdated, bson.M{"id": plannerID, "swimlane_group_by": groupBy})
+	return nil
+}
+
+// applyRequestedGrouping groups a planner by ?group_by=, or by the applied view's grouping when there is none.
+// Planners are only grouped on request: swimlane_group_by is the default for clients to ask for, so clients
+// that don't know swimlanes keep getting the cards in their lanes. A stale view grouping (e.g. a deleted
+// column) is ignored rather than failing the request.
+func applyRequestedGrouping(planner *Planner, r *http.Request, view *PlannerView) error {
+	groupBy := r.URL.Query().Get("group_by")
+	if groupBy == "" && view != nil && view.GroupBy != GroupByNone {
+		if _, err := resolveGroupBy(planner, view.GroupBy); err != nil {
+			return nil
+		}
+		groupBy = view.GroupBy
+	}
+	if groupBy == "" || groupBy == GroupByNone {
+		return nil
+	}
+	return GroupPlanner(planner, groupBy)
+}
+
+// HandleSwimlanes handles PUT /planner/{id}/swimlanes
+func HandleSwimlanes(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Extract planner ID from path
+	path := r.URL.Path
+	parts := strings.Split(path, "/")
+	if len(parts) != 4 || parts[1] != "planner" || parts[3] != "swimlanes" {
+		http.Error(w, "Invalid path", http.StatusBadRequest)
+		return
+	}
+	plannerID := parts[2]
+
+	var request struct {
+		GroupBy string `json:"group_by"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := SetSwimlaneGroupBy(r.Context(), plannerID, request.GroupBy); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, ErrInvalidGroupBy) {
+			status = http.StatusBadRequest
+		} else if errors.Is(err, mongo.ErrNoDocuments) {
+			status = http.StatusNotFound
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,152 @@
+package planner
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func swimlaneFixture() *Planner {
+	return &Planner{
+		UserID:  "ana",
+		Members: []string{"raj"},
+		Columns: []PlannerColumn{
+			{ID: "col-status", Name: "Status", Type: ColumnTypeStatus, Options: []string{"Open", "Blocked"}},
+			{ID: "col-points", Name: "Points", Type: ColumnTypeNumber},
+		},
+		Lanes: []PlannerLane{
+			{ID: "todo", Cards: []PlannerCard{
+				{ID: "c1", Assignees: []string{"raj", "ana"}, Priority: PriorityHigh, Labels: []string{"ops"},
+					Fields: map[string]interface{}{"col-status": "Blocked", "col-points": float64(13)}},
+				{ID: "c2", Fields: map[string]interface{}{"col-points": float64(2)}},
+			}},
+			{ID: "done", Cards: []PlannerCard{
+				{ID: "c3", Assignees: []string{"ana"}, Labels: []string{"bug", "ops"}, Fields: map[string]interface{}{"col-status": "Open"}},
+			}},
+		},
+	}
+}
+
+func swimlaneCards(p *Planner) map[string][][]string {
+	result := map[string][][]string{}
+	for _, row := range p.Swimlanes {
+		cells := [][]string{}
+		for _, cell := range row.Lanes {
+			ids := []string{}
+			for _, c := range cell.Cards {
+				ids = append(ids, c.ID)
+			}
+			cells = append(cells, ids)
+		}
+		result[row.Key] = cells
+	}
+	return result
+}
+
+func swimlaneKeys(p *Planner) []string {
+	keys := []string{}
+	for _, row := range p.Swimlanes {
+		keys = append(keys, row.Key)
+	}
+	return keys
+}
+
+func TestGroupPlanner(t *testing.T) {
+	p := swimlaneFixture()
+	if err := GroupPlanner(p, GroupByAssignee); err != nil {
+		t.Fatalf("GroupPlanner failed: %v", err)
+	}
+	if got, want := swimlaneKeys(p), []string{"ana", "raj", ""}; !reflect.DeepEqual(got, want) {
+		t.Errorf("assignee swimlanes = %v, want %v", got, want)
+	}
+	want := map[string][][]string{
+		"ana": {{}, {"c3"}},
+		"raj": {{"c1"}, {}},
+		"":    {{"c2"}, {}},
+	}
+	if got := swimlaneCards(p); !reflect.DeepEqual(got, want) {
+		t.Errorf("cells = %v, want %v", got, want)
+	}
+	if len(p.Lanes[0].Cards) != 0 || p.GroupBy != GroupByAssignee || p.Swimlanes[2].Title != "No assignee" {
+		t.Errorf("lanes should be emptied and grouping recorded")
+	}
+
+	p = swimlaneFixture()
+	if err := GroupPlanner(p, "col-status"); err != nil {
+		t.Fatalf("GroupPlanner failed: %v", err)
+	}
+	if got, want := swimlaneKeys(p), []string{"Open", "Blocked", ""}; !reflect.DeepEqual(got, want) {
+		t.Errorf("status swimlanes = %v, want %v", got, want)
+	}
+
+	p = swimlaneFixture()
+	if err := GroupPlanner(p, "col-points"); err != nil {
+		t.Fatalf("GroupPlanner failed: %v", err)
+	}
+	if got, want := swimlaneKeys(p), []string{"2", "13", ""}; !reflect.DeepEqual(got, want) {
+		t.Errorf("number swimlanes = %v, want %v", got, want)
+	}
+
+	if err := GroupPlanner(swimlaneFixture(), "col-missing"); !errors.Is(err, ErrInvalidGroupBy) {
+		t.Errorf("expected ErrInvalidGroupBy, got %v", err)
+	}
+}
+
+func TestSwimlanePatch(t *testing.T) {
+	p := swimlaneFixture()
+	card := &p.Lanes[1].Cards[0]
+
+	patch, _ := swimlanePatch(card, GroupByLabel, nil, "feature")
+	if !reflect.DeepEqual(*patch.Labels, []string{"feature", "ops"}) {
+		t.Errorf("labels = %v", *patch.Labels)
+	}
+	patch, _ = swimlanePatch(card, GroupByAssignee, nil, "")
+	if len(*patch.Assignees) != 0 {
+		t.Errorf("moving to the empty swimlane should clear assignees, got %v", *patch.Assignees)
+	}
+	patch, err := swimlanePatch(card, "col-points", &p.Columns[1], "8")
+	if err != nil || patch.Fields["col-points"] != float64(8) {
+		t.Errorf("number field patch = %v, %v", patch.Fields, err)
+	}
+	patch, _ = swimlanePatch(card, "col-status", &p.Columns[0], "")
+	if v, ok := patch.Fields["col-status"]; !ok || v != nil {
+		t.Errorf("empty swimlane should clear the field, got %v", patch.Fields)
+	}
+}
+
+func TestApplyRequestedGrouping(t *testing.T) {
+	cases := []struct {
+		url  string
+		view *PlannerView
+		want string
+	}{
+		{"/planner/p1", nil, ""}, // the stored default is only returned, not applied
+		{"/planner/p1?group_by=priority", nil, GroupByPriority},
+		{"/planner/p1?group_by=none", nil, ""},
+		{"/planner/p1", &PlannerView{}, ""},
+		{"/planner/p1", &PlannerView{GroupBy: "col-status"}, "col-status"},
+		{"/planner/p1", &PlannerView{GroupBy: "deleted-column"}, ""},
+		{"/planner/p1?group_by=none", &PlannerView{GroupBy: "col-status"}, ""},
+		{"/planner/p1?group_by=label", &PlannerView{GroupBy: "col-status"}, GroupByLabel},
+	}
+	for _, c := range cases {
+		p := swimlaneFixture()
+		p.SwimlaneGroupBy = GroupByAssignee
+		if err := applyRequestedGrouping(p, httptest.NewRequest(http.MethodGet, c.url, nil), c.view); err != nil {
+			t.Fatalf("%s %+v: %v", c.url, c.view, err)
+		}
+		if p.GroupBy != c.want {
+			t.Errorf("%s %+v: expected grouping %q, got %q", c.url, c.view, c.want, p.GroupBy)
+		}
+		if c.want == "" && (p.Swimlanes != nil || len(p.Lanes[0].Cards) != 2) {
+			t.Errorf("%s %+v: ungrouped planners should keep their cards in lanes", c.url, c.view)
+		}
+	}
+
+	err := applyRequestedGrouping(swimlaneFixture(), httptest.NewRequest(http.MethodGet, "/planner/p1?group_by=color", nil), nil)
+	if !errors.Is(err, ErrInvalidGroupBy) {
+		t.Errorf("expected ErrInvalidGroupBy for an unknown group_by, got %v", err)
+	}
+}
@@ -3,19 +3,45 @@ package planner
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
+	"strings"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
+
+// Template scopes
+const (
+	TemplateScopeBuiltIn   = "builtin"   // shipped with the server, read-only
+	TemplateScopeUser      = "user"      // visible to its owner only
+	TemplateScopeWorkspace = "workspace" // visible to every user, editable by its owner
+)
+
+// ErrTemplateNotFound is returned when a template doesn't exist or isn't visible to the caller
+var ErrTemplateNotFound = errors.New("template not found")
+
+// ErrInvalidTemplate is returned when a template definition is malformed
+var ErrInvalidTemplate = errors.New("invalid template")
+
 type PlannerTemplate struct {
-	ID          string                `json:"id"`
-	Name        string                `json:"name"`
-	Type        string                `json:"type"` // scrum, kanban, personal
-	Description string                `json:"description"`
-	CreatedAt   time.Time             `json:"created_at"`
-	Lanes       []PlannerTemplateLane `json:"lanes,omitempty"`
+	ID          string                  `json:"id"`
+	Name        string                  `json:"name"`
+	Type        string                  `json:"type"` // scrum, kanban, personal
+	Description string                  `json:"description"`
+	Scope       string                  `json:"scope"`
+	OwnerID     string                  `json:"owner_id,omitempty"`
+	Slug        string                  `json:"slug,omitempty"`    // stable identifier of built-in and packaged templates
+	Version     int                     `json:"version,omitempty"` // bumped whenever a packaged template changes
+	CreatedAt   time.Time               `json:"created_at"`
+	UpdatedAt   time.Time               `json:"updated_at"`
+	Lanes       []PlannerTemplateLane   `json:"lanes,omitempty"`
+	Columns     []PlannerTemplateColumn `json:"columns,omitempty"`
+	Cards       []PlannerTemplateCard   `json:"cards,omitempty"`
 }
 
 // PlannerTemplateLane represents a predefined lane in a template
@@ -24,61 +50,142 @@ type PlannerTemplateLane struct {
 	TemplateID  string    `json:"template_id"`
 	Name        string    `json:"name"`
 	Description string    `json:"description"`
+	Color       string    `json:"color,omitempty"`
+	Category    string    `json:"category,omitempty"` // backlog, todo, in-progress or done
 	Position    int       `json:"position"`
 	CreatedAt   time.Time `json:"created_at"`
 }
 
- // InitializeTemplates creates the default templates if they don't exist in MongoDB
+// PlannerTemplateColumn represents a predefined custom column in a template
+type PlannerTemplateColumn struct {
+	ID       string   `json:"id"`
+	Name     string   `json:"name"`
+	Type     string   `json:"type"`
+	Options  []string `json:"options,omitempty"`
+	Position int      `json:"position"`
+}
+
+// PlannerTemplateCard represents a sample card in a template.
+// LaneID refers to a template lane and Fields are keyed by title, content or template column ID.
+type PlannerTemplateCard struct {
+	ID        string                 `json:"id"`
+	LaneID    string                 `json:"lane_id"`
+	Fields    map[string]interface{} `json:"fields"`
+	Priority  string                 `json:"priority,omitempty"`
+	Labels    []string               `json:"labels,omitempty"`
+	Checklist []string               `json:"checklist,omitempty"`
+	Position  int                    `json:"position"`
+}
+
+// InitializeTemplates upserts the built-in templates embedded in the binary.
+// A stored built-in is replaced when its file has a newer version, keeping its ID so planners still refer to it.
 func InitializeTemplates(ctx context.Context) error {
 	log.Println("Initializing planner templates")
 
-	count, err := plannerCollection.Database().Collection("planner_templates").CountDocuments(ctx, bson.M{})
+	templates, err := loadBuiltinTemplates()
 	if err != nil {
 		return err
 	}
-	if count > 0 {
-		log.Println("Templates already exist, skipping initialization")
-		return nil
-	}
-
-	templates := []PlannerTemplate{
-		{
-			ID:          GenerateID(),
-			Name:        "Scrum Board",
-			Type:        "scrum",
-			Description: "A board for managing Scrum sprints",
-			CreatedAt:   time.Now(),
-			Lanes: []PlannerTemplateLane{
-				{ID: GenerateID(), Name: "Backlog", Description: "Items not ready", Position: 1, CreatedAt: time.Now()},
-				{ID: GenerateID(), Name: "To Do", Description: "Planned items", Position: 2, CreatedAt: time.Now()},
-			},
-		},
-		{
-			ID:          GenerateID(),
-			Name:        "Kanban Board",
-			Type:        "kanban",
-			Description: "A board for visualizing work",
-			CreatedAt:   time.Now(),
-			Lanes: []PlannerTemplateLane{
-				{ID: GenerateID(), Name: "To Do", Description: "Items to do", Position: 1, CreatedAt: time.Now()},
-				{ID: GenerateID(), Name: "In Progress", Description: "Ongoing items", Position: 2, CreatedAt: time.Now()},
-				{ID: GenerateID(), Name: "Done", Description: "Completed items", Position: 3, CreatedAt: time.Now()},
-			},
-		},
-	}
 
-	// convert to []interface{} for InsertMany
-	var docs []interface{}
+	collection := plannerCollection.Database().Collection("planner_templates")
 	for _, t := range templates {
-		docs = append(docs, t)
+		var existing PlannerTemplate
+		err := collection.FindOne(ctx, bson.M{"scope": TemplateScopeBuiltIn, "slug": t.Slug}).Decode(&existing)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			// Templates seeded before they had a scope are matched by name and adopted, keeping their ID
+			err = collection.FindOneAndUpdate(ctx,
+				bson.M{"scope": bson.M{"$exists": false}, "name": t.Name},
+				bson.M{"$set": bson.M{"scope": TemplateScopeBuiltIn, "slug": t.Slug}},
+				options.FindOneAndUpdate().SetReturnDocument(options.After),
+			).Decode(&existing)
+			if mongo.IsDuplicateKeyError(err) {
+				err = collection.FindOne(ctx, bson.M{"scope": TemplateScopeBuiltIn, "slug": t.Slug}).Decode(&existing)
+			}
+		}
+		if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+			return err
+		}
+		if existing.ID != "" && existing.Version >= t.Version {
+			continue
+		}
+
+		t.ID = existing.ID
+		if t.ID == "" {
+			t.ID = GenerateID()
+		}
+		if err := prepareTemplate(t); err != nil {
+			return fmt.Errorf("built-in template %s: %w", t.Slug, err)
+		}
+
+		filter, update := builtinTemplateUpsert(t, time.Now())
+		_, err = collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+		if mongo.IsDuplicateKeyError(err) {
+			// Another instance installed this or a newer version first
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		log.Printf("Installed built-in template: slug=%s, version=%d", t.Slug, t.Version)
 	}
 
-	_, err = plannerCollection.Database().Collection("planner_templates").InsertMany(ctx, docs)
+	log.Println("Successfully initialized planner templates in MongoDB")
+	return nil
+}
+
+// builtinTemplateUpsert returns the filter and update installing a built-in template. The filter only matches
+// older versions, so with the unique (scope, slug) index a concurrent or outdated install fails with a
+// duplicate key error instead of adding a second copy or downgrading the template.
+func builtinTemplateUpsert(t *PlannerTemplate, now time.Time) (bson.M, bson.M) {
+	filter := bson.M{
+		"scope": TemplateScopeBuiltIn,
+		"slug":  t.Slug,
+		"$or": []bson.M{
+			{"version": bson.M{"$lt": t.Version}},
+			{"version": bson.M{"$exists": false}},
+		},
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"name":        t.Name,
+			"type":        t.Type,
+			"description": t.Description,
+			"version":     t.Version,
+			"updatedat":   now,
+			"lanes":       t.Lanes,
+			"columns":     t.Columns,
+			"cards":       t.Cards,
+		},
+		"$setOnInsert": bson.M{"id": t.ID, "createdat": now},
+	}
+	return filter, update
+}
+
+// removeDuplicateBuiltinTemplates keeps the oldest copy of each built-in template, so that the unique
+// (scope, slug) index can be created on databases seeded by concurrent instances
+func removeDuplicateBuiltinTemplates(ctx context.Context, collection *mongo.Collection) error {
+	cursor, err := collection.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"scope": TemplateScopeBuiltIn}}},
+		{{Key: "$sort", Value: bson.D{{Key: "createdat", Value: 1}}}},
+		{{Key: "$group", Value: bson.M{"_id": "$slug", "ids": bson.M{"$push": "$id"}}}},
+		{{Key: "$match", Value: bson.M{"ids.1": bson.M{"$exists": true}}}},
+	})
 	if err != nil {
 		return err
 	}
-
-	log.Println("Successfully initialized planner templates in MongoDB")
+	var groups []struct {
+		Slug string   `bson:"_id"`
+		IDs  []string `bson:"ids"`
+	}
+	if err := cursor.All(ctx, &groups); err != nil {
+		return err
+	}
+	for _, g := range groups {
+		log.Printf("Removing duplicate built-in templates: slug=%s, count=%d", g.Slug, len(g.IDs)-1)
+		if _, err := collection.DeleteMany(ctx, bson.M{"id": bson.M{"$in": g.IDs[1:]}}); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -86,7 +193,8 @@ func InitializeTemplates(ctx context.Context) error {
 func GetTemplates(ctx context.Context) ([]PlannerTemplate, error) {
 	log.Println("Getting all planner templates")
 
-	cur, err := plannerCollection.Database().Collection("planner_templates").Find(ctx, bson.M{})
+	userID, _ := ctx.Value("user_id").(string)
+	cur, err := plannerCollection.Database().Collection("planner_templates").Find(ctx, templateVisibilityFilter(userID))
 	if err != nil {
 		return nil, err
 	}
@@ -98,6 +206,9 @@ func GetTemplates(ctx context.Context) ([]PlannerTemplate, error) {
 		if err := cur.Decode(&t); err != nil {
 			return nil, err
 		}
+		if t.Scope == "" {
+			t.Scope = TemplateScopeBuiltIn
+		}
 		templates = append(templates, t)
 	}
 	return templates, nil
@@ -108,11 +219,20 @@ func GetTemplates(ctx context.Context) ([]PlannerTemplate, error) {
 func GetTemplate(ctx context.Context, id string) (*PlannerTemplate, error) {
 	log.Printf("Getting template: id=%s", id)
 
+	userID, _ := ctx.Value("user_id").(string)
+	filter := bson.M{"$and": []bson.M{{"id": id}, templateVisibilityFilter(userID)}}
+
 	var t PlannerTemplate
-	err := plannerCollection.Database().Collection("planner_templates").FindOne(ctx, bson.M{"id": id}).Decode(&t)
+	err := plannerCollection.Database().Collection("planner_templates").FindOne(ctx, filter).Decode(&t)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, fmt.Errorf("%w: %s", ErrTemplateNotFound, id)
+	}
 	if err != nil {
 		return nil, err
 	}
+	if t.Scope == "" {
+		t.Scope = TemplateScopeBuiltIn
+	}
 
 	return &t, nil
 }
@@ -143,17 +263,15 @@ func HandleGetTemplate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	
-	// Extract ID from path
-	path := r.URL.Path
-	if len(path) <= len("/planner/templates/") {
+	id, ok := templateIDFromPath(r.URL.Path)
+	if !ok {
 		http.Error(w, "Template ID is required", http.StatusBadRequest)
 		return
 	}
-	id := path[len("/planner/templates/"):]
 	
 	template, err := GetTemplate(r.Context(), id)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusNotFound)
+		http.Error(w, err.Error(), templateErrorStatus(err))
 		return
 	}
 	
@@ -162,3 +280,499 @@ func HandleGetTemplate(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 	}
 }
+
+// templateVisibilityFilter matches built-in and workspace templates and the user's own templates
+func templateVisibilityFilter(userID string) bson.M {
+	visible := []bson.M{
+		{"scope": bson.M{"$in": []string{TemplateScopeBuiltIn, TemplateScopeWorkspace}}},
+		{"scope": bson.M{"$exists": false}}, // seeded before templates had a scope
+	}
+	if userID != "" {
+		visible = append(visible, bson.M{"scope": TemplateScopeUser, "ownerid": userID})
+	}
+	return bson.M{"$or": visible}
+}
+
+// checkTemplateEditable returns ErrForbidden unless the caller owns a user or workspace template
+func checkTemplateEditable(ctx context.Context, t *PlannerTemplate) error {
+	if t.Scope == TemplateScopeBuiltIn {
+		return fmt.Errorf("%w: built-in templates are read-only", ErrForbidden)
+	}
+	actorID, _ := ctx.Value("user_id").(string)
+	if actorID == "" || actorID != t.OwnerID {
+		return fmt.Errorf("%w: only the template owner can change it", ErrForbidden)
+	}
+	return nil
+}
+
+// prepareTemplate validates a template, assigns missing IDs and sets positions from slice order.
+// Values of user columns are dropped because users don't carry over between planners.
+func prepareTemplate(t *PlannerTemplate) error {
+	t.Name = strings.TrimSpace(t.Name)
+	if t.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidTemplate)
+	}
+	now := time.Now()
+
+	lanes := map[string]bool{}
+	for i := range t.Lanes {
+		lane := &t.Lanes[i]
+		if strings.TrimSpace(lane.Name) == "" {
+			return fmt.Errorf("%w: lane %d has no name", ErrInvalidTemplate, i+1)
+		}
+		if lane.ID == "" {
+			lane.ID = GenerateID()
+		}
+		if lanes[lane.ID] {
+			return fmt.Errorf("%w: duplicate lane id %q", ErrInvalidTemplate, lane.ID)
+		}
+		if !isValidLaneCategory(lane.Category) {
+			return fmt.Errorf("%w: lane %d has invalid category %q", ErrInvalidTemplate, i+1, lane.Category)
+		}
+		lanes[lane.ID] = true
+		lane.TemplateID = t.ID
+		lane.Position = i + 1
+		if lane.CreatedAt.IsZero() {
+			lane.CreatedAt = now
+		}
+	}
+
+	columns := map[string]PlannerColumn{}
+	for i := range t.Columns {
+		col := &t.Columns[i]
+		if err := validateColumnDefinition(col.Name, col.Type, col.Options); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidTemplate, err)
+		}
+		if col.ID == "" {
+			col.ID = GenerateID()
+		}
+		if _, exists := columns[col.ID]; exists || col.ID == FieldTitle || col.ID == FieldContent {
+			return fmt.Errorf("%w: duplicate column id %q", ErrInvalidTemplate, col.ID)
+		}
+		col.Position = i + 1
+		columns[col.ID] = PlannerColumn{ID: col.ID, Name: col.Name, Type: col.Type, Options: col.Options}
+	}
+
+	positions := map[string]int{}
+	for i := range t.Cards {
+		card := &t.Cards[i]
+		if !lanes[card.LaneID] {
+			return fmt.Errorf("%w: card %d refers to unknown lane %q", ErrInvalidTemplate, i+1, card.LaneID)
+		}
+		if card.ID == "" {
+			card.ID = GenerateID()
+		}
+		fields := map[string]interface{}{}
+		for key, value := range card.Fields {
+			if key == FieldTitle || key == FieldContent {
+				s, ok := value.(string)
+				if !ok {
+					return fmt.Errorf("%w: card %d field %q must be a string", ErrInvalidTemplate, i+1, key)
+				}
+				fields[key] = s
+				continue
+			}
+			col, ok := columns[key]
+			if !ok {
+				return fmt.Errorf("%w: card %d refers to unknown column %q", ErrInvalidTemplate, i+1, key)
+			}
+			if col.Type == ColumnTypeUser {
+				continue
+			}
+			v, err := validateColumnValue(col, value)
+			if err != nil {
+				return fmt.Errorf("%w: card %d: %v", ErrInvalidTemplate, i+1, err)
+			}
+			fields[key] = v
+		}
+		card.Fields = fields
+		if card.Priority != "" && !isValidPriority(card.Priority) {
+			return fmt.Errorf("%w: card %d has invalid priority %q", ErrInvalidTemplate, i+1, card.Priority)
+		}
+		card.Labels = normalizeLabels(card.Labels)
+		positions[card.LaneID]++
+		card.Position = positions[card.LaneID]
+	}
+	return nil
+}
+
+// normalizeTemplateScope defaults user-created templates to the user scope
+func normalizeTemplateScope(scope string) (string, error) {
+	switch scope {
+	case "":
+		return TemplateScopeUser, nil
+	case TemplateScopeUser, TemplateScopeWorkspace:
+		return scope, nil
+	}
+	return "", fmt.Errorf("%w: scope must be %q or %q", ErrInvalidTemplate, TemplateScopeUser, TemplateScopeWorkspace)
+}
+
+// CreateTemplate stores a new user or workspace template owned by the caller
+func CreateTemplate(ctx context.Context, t *PlannerTemplate) (*PlannerTemplate, error) {
+	log.Printf("Creating template: name=%s, scope=%s", t.Name, t.Scope)
+
+	actorID, _ := ctx.Value("user_id").(string)
+	if actorID == "" {
+		return nil, fmt.Errorf("%w: sign in to create templates", ErrForbidden)
+	}
+	scope, err := normalizeTemplateScope(t.Scope)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	t.ID = GenerateID()
+	t.Scope = scope
+	t.OwnerID = actorID
+	t.CreatedAt = now
+	t.UpdatedAt = now
+	if t.Type == "" {
+		t.Type = "custom"
+	}
+	if err := prepareTemplate(t); err != nil {
+		return nil, err
+	}
+
+	if _, err := plannerCollection.Database().Collection("planner_templates").InsertOne(ctx, t); err != nil {
+		return nil, fmt.Errorf("failed to insert template: %w", err)
+	}
+	return t, nil
+}
+
+// UpdateTemplate replaces the definition of a template owned by the caller
+func UpdateTemplate(ctx context.Context, id string, t *PlannerTemplate) (*PlannerTemplate, error) {
+	log.Printf("Updating template: id=%s", id)
+
+	existing, err := GetTemplate(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkTemplateEditable(ctx, existing); err != nil {
+		return nil, err
+	}
+	if t.Scope == "" {
+		t.Scope = existing.Scope
+	}
+	scope, err := normalizeTemplateScope(t.Scope)
+	if err != nil {
+		return nil, err
+	}
+
+	t.ID = existing.ID
+	t.Scope = scope
+	t.OwnerID = existing.OwnerID
+	t.CreatedAt = existing.CreatedAt
+	t.UpdatedAt = time.Now()
+	if t.Type == "" {
+		t.Type = existing.Type
+	}
+	if err := prepareTemplate(t); err != nil {
+		return nil, err
+	}
+
+	if _, err := plannerCollection.Database().Collection("planner_templates").ReplaceOne(ctx, bson.M{"id": id}, t); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// DeleteTemplate removes a template owned by the caller. Planners created from it are not affected.
+func DeleteTemplate(ctx context.Context, id string) error {
+	log.Printf("Deleting template: id=%s", id)
+
+	existing, err := GetTemplate(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := checkTemplateEditable(ctx, existing); err != nil {
+		return err
+	}
+	_, err = plannerCollection.Database().Collection("planner_templates").DeleteOne(ctx, bson.M{"id": id})
+	return err
+}
+
+// SaveAsTemplate captures the lanes, colors and columns of a planner, and optionally its cards, as a new template
+func SaveAsTemplate(ctx context.Context, plannerID, name, description, scope string, includeCards bool) (*PlannerTemplate, error) {
+	log.Printf("Saving planner as template: plannerID=%s, includeCards=%t", plannerID, includeCards)
+
+	planner, err := GetPlanner(ctx, plannerID)
+	if err != nil {
+		return nil, err
+	}
+	actorID, _ := ctx.Value("user_id").(string)
+	if planner.UserID != "" && !IsPlannerMember(planner, actorID) {
+		return nil, fmt.Errorf("%w: not a member of this planner", ErrForbidden)
+	}
+	if strings.TrimSpace(name) == "" {
+		name = planner.Title
+	}
+	if description == "" {
+		description = planner.Description
+	}
+
+	template := &PlannerTemplate{Name: name, Description: description, Scope: scope}
+	laneIDs := map[string]string{}
+	for _, lane := range planner.Lanes {
+		laneIDs[lane.ID] = GenerateID()
+		template.Lanes = append(template.Lanes, PlannerTemplateLane{
+			ID:          laneIDs[lane.ID],
+			Name:        lane.Title,
+			Description: lane.Description,
+			Color:       lane.Color,
+			Category:    laneCategory(&lane),
+		})
+	}
+	columnIDs := map[string]string{}
+	for _, col := range planner.Columns {
+		columnIDs[col.ID] = GenerateID()
+		template.Columns = append(template.Columns, PlannerTemplateColumn{
+			ID:      columnIDs[col.ID],
+			Name:    col.Name,
+			Type:    col.Type,
+			Options: col.Options,
+		})
+	}
+	if includeCards {
+		for _, lane := range planner.Lanes {
+			for _, card := range lane.Cards {
+				fields := map[string]interface{}{}
+				for key, value := range card.Fields {
+					if key == FieldTitle || key == FieldContent {
+						fields[key] = value
+					} else if id, ok := columnIDs[key]; ok {
+						fields[id] = value
+					}
+				}
+				checklist := []string{}
+				for _, item := range card.Checklist {
+					checklist = append(checklist, item.Text)
+				}
+				template.Cards = append(template.Cards, PlannerTemplateCard{
+					LaneID:    laneIDs[lane.ID],
+					Fields:    fields,
+					Priority:  card.Priority,
+					Labels:    card.Labels,
+					Checklist: checklist,
+				})
+			}
+		}
+	}
+
+	return CreateTemplate(ctx, template)
+}
+
+// templateIDFromPath extracts the template ID from /planner/templates/{id}
+func templateIDFromPath(path string) (string, bool) {
+	path = strings.TrimPrefix(path, "/api")
+	if !strings.HasPrefix(path, "/planner/templates/") {
+		return "", false
+	}
+	id := strings.TrimPrefix(path, "/planner/templates/")
+	return id, id != "" && !strings.Contains(id, "/")
+}
+
+// templateErrorStatus maps template errors to HTTP status codes
+func templateErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, ErrInvalidTemplate):
+		return http.StatusBadRequest
+	case errors.Is(err, ErrForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, ErrTemplateNotFound), errors.Is(err, mongo.ErrNoDocuments):
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
+
+// HandleCreateTemplate handles POST /planner/templates
+func HandleCreateTemplate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var template PlannerTemplate
+	if err := json.NewDecoder(r.Body).Decode(&template); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	created, err := CreateTemplate(r.Context(), &template)
+	if err != nil {
+		http.Error(w, err.Error(), templateErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(created); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// HandleUpdateTemplate handles PUT /planner/templates/{id}
+func HandleUpdateTemplate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	id, ok := templateIDFromPath(r.URL.Path)
+	if !ok {
+		http.Error(w, "Template ID is required", http.StatusBadRequest)
+		return
+	}
+
+	var template PlannerTemplate
+	if err := json.NewDecoder(r.Body).Decode(&template); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	updated, err := UpdateTemplate(r.Context(), id, &template)
+	if err != nil {
+		http.Error(w, err.Error(), templateErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(updated); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// HandleDeleteTemplate handles DELETE /planner/templates/{id}
+func HandleDeleteTemplate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	id, ok := templateIDFromPath(r.URL.Path)
+	if !ok {
+		http.Error(w, "Template ID is required", http.StatusBadRequest)
+		return
+	}
+
+	if err := DeleteTemplate(r.Context(), id); err != nil {
+		http.Error(w, err.Error(), templateErrorStatus(err))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// HandleSaveAsTemplate handles POST /planner/{id}/save-as-template
+func HandleSaveAsTemplate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Extract planner ID from path
+	path := r.URL.Path
+	parts := strings.Split(path, "/")
+	if len(parts) != 4 || parts[1] != "planner" || parts[3] != "save-as-template" {
+		http.Error(w, "Invalid path", http.StatusBadRequest)
+		return
+	}
+	plannerID := parts[2]
+
+	var request struct {
+		Name         string `json:"name"`
+		Description  string `json:"description"`
+		Scope        string `json:"scope"`
+		IncludeCards bool   `json:"include_cards"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	template, err := SaveAsTemplate(r.Context(), plannerID, request.Name, request.Description, request.Scope, request.IncludeCards)
+	if err != nil {
+		http.Error(w, err.Error(), templateErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(template); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// instantiateTemplate materializes the lanes, columns and starter cards of a template on a new planner.
+// Every element gets a fresh ID; lanes remember the template lane they came from.
+func instantiateTemplate(planner *Planner, t *PlannerTemplate) {
+	now := planner.CreatedAt
+
+	laneIndex := map[string]int{}
+	for i, tl := range t.Lanes {
+		laneIndex[tl.ID] = len(planner.Lanes)
+		category := tl.Category
+		if category == "" {
+			category = inferLaneCategory(tl.Name)
+		}
+		planner.Lanes = append(planner.Lanes, PlannerLane{
+			ID:             GenerateID(),
+			PlannerID:      planner.ID,
+			TemplateLaneID: tl.ID,
+			Title:          tl.Name,
+			Description:    tl.Description,
+			Color:          tl.Color,
+			Category:       category,
+			Position:       i + 1,
+			CreatedAt:      now,
+			UpdatedAt:      now,
+			Cards:          []PlannerCard{},
+		})
+	}
+
+	columnIDs := map[string]string{}
+	for i, tc := range t.Columns {
+		columnIDs[tc.ID] = GenerateID()
+		planner.Columns = append(planner.Columns, PlannerColumn{
+			ID:        columnIDs[tc.ID],
+			PlannerID: planner.ID,
+			Name:      tc.Name,
+			Type:      tc.Type,
+			Options:   tc.Options,
+			Position:  i + 1,
+			CreatedAt: now,
+			UpdatedAt: now,
+		})
+	}
+
+	for _, tc := range t.Cards {
+		i, ok := laneIndex[tc.LaneID]
+		if !ok {
+			continue
+		}
+		lane := &planner.Lanes[i]
+		fields := map[string]interface{}{}
+		for key, value := range tc.Fields {
+			if key == FieldTitle || key == FieldContent {
+				fields[key] = value
+			} else if id, ok := columnIDs[key]; ok {
+				fields[id] = value
+			}
+		}
+		card := PlannerCard{
+			ID:        GenerateID(),
+			LaneID:    lane.ID,
+			Fields:    fields,
+			Priority:  tc.Priority,
+			Labels:    tc.Labels,
+			Position:  len(lane.Cards) + 1,
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+		for j, text := range tc.Checklist {
+			card.Checklist = append(card.Checklist, ChecklistItem{
+				ID:        GenerateID(),
+				Text:      text,
+				Position:  j + 1,
+				CreatedAt: now,
+				UpdatedAt: now,
+			})
+		}
+		normalizeCard(&card)
+		lane.Cards = append(lane.Cards, card)
+	}
+}
@@ -0,0 +1,219 @@
+package planner
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"net/http"
+	"strings"
+)
+
+// TemplatePackageFormat identifies template package documents
+const TemplatePackageFormat = "zurabase.template"
+
+// TemplatePackageFormatVersion is the newest package format version this server reads and writes
+const TemplatePackageFormatVersion = 1
+
+//go:embed templates/*.json
+var builtinTemplateFiles embed.FS
+
+// TemplatePackage is the portable form of a template used for built-ins and for sharing templates between instances.
+// Lane and column IDs only need to be unique within the package; they are replaced when the package is imported.
+type TemplatePackage struct {
+	Format        string                  `json:"format"`
+	FormatVersion int                     `json:"format_version"`
+	Slug          string                  `json:"slug,omitempty"`
+	Version       int                     `json:"version,omitempty"`
+	Name          string                  `json:"name"`
+	Type          string                  `json:"type,omitempty"`
+	Description   string                  `json:"description,omitempty"`
+	Lanes         []TemplatePackageLane   `json:"lanes"`
+	Columns       []PlannerTemplateColumn `json:"columns,omitempty"`
+	Cards         []PlannerTemplateCard   `json:"cards,omitempty"`
+}
+
+// TemplatePackageLane is a lane of a template package
+type TemplatePackageLane struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Color       string `json:"color,omitempty"`
+	Category    string `json:"category,omitempty"`
+}
+
+// ParseTemplatePackage decodes and checks a template package document
+func ParseTemplatePackage(data []byte) (*TemplatePackage, error) {
+	var pkg TemplatePackage
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTemplate, err)
+	}
+	if pkg.Format != TemplatePackageFormat {
+		return nil, fmt.Errorf("%w: format must be %q", ErrInvalidTemplate, TemplatePackageFormat)
+	}
+	if pkg.FormatVersion < 1 || pkg.FormatVersion > TemplatePackageFormatVersion {
+		return nil, fmt.Errorf("%w: unsupported format_version %d", ErrInvalidTemplate, pkg.FormatVersion)
+	}
+	return &pkg, nil
+}
+
+// Template converts a package into an unsaved template
+func (pkg *TemplatePackage) Template() *PlannerTemplate {
+	t := &PlannerTemplate{
+		Name:        pkg.Name,
+		Type:        pkg.Type,
+		Description: pkg.Description,
+		Slug:        pkg.Slug,
+		Version:     pkg.Version,
+		Columns:     pkg.Columns,
+		Cards:       pkg.Cards,
+	}
+	for _, lane := range pkg.Lanes {
+		t.Lanes = append(t.Lanes, PlannerTemplateLane{
+			ID:          lane.ID,
+			Name:        lane.Name,
+			Description: lane.Description,
+			Color:       lane.Color,
+			Category:    lane.Category,
+		})
+	}
+	return t
+}
+
+// NewTemplatePackage converts a template into its portable form, leaving out ownership and timestamps
+func NewTemplatePackage(t *PlannerTemplate) *TemplatePackage {
+	pkg := &TemplatePackage{
+		Format:        TemplatePackageFormat,
+		FormatVersion: TemplatePackageFormatVersion,
+		Slug:          t.Slug,
+		Version:       t.Version,
+		Name:          t.Name,
+		Type:          t.Type,
+		Description:   t.Description,
+		Lanes:         []TemplatePackageLane{},
+		Columns:       t.Columns,
+		Cards:         t.Cards,
+	}
+	for _, lane := range t.Lanes {
+		pkg.Lanes = append(pkg.Lanes, TemplatePackageLane{
+			ID:          lane.ID,
+			Name:        lane.Name,
+			Description: lane.Description,
+			Color:       lane.Color,
+			Category:    lane.Category,
+		})
+	}
+	return pkg
+}
+
+// loadBuiltinTemplates parses the template packages embedded in the binary
+func loadBuiltinTemplates() ([]*PlannerTemplate, error) {
+	files, err := fs.Glob(builtinTemplateFiles, "templates/*.json")
+	if err != nil {
+		return nil, err
+	}
+
+	templates := []*PlannerTemplate{}
+	slugs := map[string]bool{}
+	for _, name := range files {
+		data, err := builtinTemplateFiles.ReadFile(name)
+		if err != nil {
+			return nil, err
+		}
+		pkg, err := ParseTemplatePackage(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		if pkg.Slug == "" || pkg.Version < 1 {
+			return nil, fmt.Errorf("%s: built-in templates need a slug and a version", name)
+		}
+		if slugs[pkg.Slug] {
+			return nil, fmt.Errorf("%s: duplicate slug %q", name, pkg.Slug)
+		}
+		slugs[pkg.Slug] = true
+
+		t := pkg.Template()
+		t.Scope = TemplateScopeBuiltIn
+		templates = append(templates, t)
+	}
+	return templates, nil
+}
+
+// ExportTemplatePackage returns a visible template as a package
+func ExportTemplatePackage(ctx context.Context, id string) (*TemplatePackage, error) {
+	log.Printf("Exporting template package: id=%s", id)
+
+	t, err := GetTemplate(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return NewTemplatePackage(t), nil
+}
+
+// ImportTemplatePackage creates a user or workspace template owned by the caller from a package
+func ImportTemplatePackage(ctx context.Context, data []byte, scope string) (*PlannerTemplate, error) {
+	log.Printf("Importing template package: scope=%s", scope)
+
+	pkg, err := ParseTemplatePackage(data)
+	if err != nil {
+		return nil, err
+	}
+	t := pkg.Template()
+	t.Scope = scope
+	return CreateTemplate(ctx, t)
+}
+
+// HandleExportTemplatePackage handles GET /planner/templates/{id}/export
+func HandleExportTemplatePackage(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	id, ok := templateIDFromPath(strings.TrimSuffix(r.URL.Path, "/export"))
+	if !ok {
+		http.Error(w, "Template ID is required", http.StatusBadRequest)
+		return
+	}
+
+	pkg, err := ExportTemplatePackage(r.Context(), id)
+	if err != nil {
+		http.Error(w, err.Error(), templateErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", "attachment; filename=\"template.json\"")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(pkg); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// HandleImportTemplatePackage handles POST /planner/templates/import?scope=user|workspace
+func HandleImportTemplatePackage(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, 10<<20))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	template, err := ImportTemplatePackage(r.Context(), data, r.URL.Query().Get("scope"))
+	if err != nil {
+		http.Error(w, err.Error(), templateErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(template); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,266 @@
+package planner
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func templateFixture() *PlannerTemplate {
+	return &PlannerTemplate{
+		ID:   "tpl",
+		Name: " Release ",
+		Lanes: []PlannerTemplateLane{
+			{ID: "tl-todo", Name: "To Do", Color: "#cccccc"},
+			{ID: "tl-done", Name: "Done", Color: "#00ff00"},
+		},
+		Columns: []PlannerTemplateColumn{
+			{ID: "tc-status", Name: "Status", Type: ColumnTypeStatus, Options: []string{"Open", "Closed"}},
+			{ID: "tc-owner", Name: "Owner", Type: ColumnTypeUser},
+		},
+		Cards: []PlannerTemplateCard{
+			{LaneID: "tl-todo", Fields: map[string]interface{}{FieldTitle: "Write notes", "tc-status": "Open", "tc-owner": "user-1"}, Checklist: []string{"draft", "review"}},
+			{LaneID: "tl-todo", Fields: map[string]interface{}{FieldTitle: "Tag release"}, Labels: []string{" ops ", "ops"}},
+		},
+	}
+}
+
+func TestPrepareTemplate(t *testing.T) {
+	tpl := templateFixture()
+	if err := prepareTemplate(tpl); err != nil {
+		t.Fatalf("prepareTemplate failed: %v", err)
+	}
+	if tpl.Name != "Release" || tpl.Lanes[1].Position != 2 || tpl.Lanes[0].TemplateID != "tpl" {
+		t.Errorf("lanes not normalized: %+v", tpl.Lanes)
+	}
+	if _, ok := tpl.Cards[0].Fields["tc-owner"]; ok {
+		t.Errorf("user column values should be dropped")
+	}
+	if tpl.Cards[1].Position != 2 || len(tpl.Cards[1].Labels) != 1 {
+		t.Errorf("unexpected second card: %+v", tpl.Cards[1])
+	}
+
+	for name, broken := range map[string]func(*PlannerTemplate){
+		"unknown lane":   func(t *PlannerTemplate) { t.Cards[0].LaneID = "nope" },
+		"unknown column": func(t *PlannerTemplate) { t.Cards[0].Fields["tc-missing"] = "x" },
+		"bad status":     func(t *PlannerTemplate) { t.Cards[0].Fields["tc-status"] = "Maybe" },
+		"bad column":     func(t *PlannerTemplate) { t.Columns[0].Type = "color" },
+		"no name":        func(t *PlannerTemplate) { t.Name = "" },
+	} {
+		tpl := templateFixture()
+		broken(tpl)
+		if err := prepareTemplate(tpl); !errors.Is(err, ErrInvalidTemplate) {
+			t.Errorf("%s: expected ErrInvalidTemplate, got %v", name, err)
+		}
+	}
+}
+
+func TestInstantiateTemplate(t *testing.T) {
+	tpl := templateFixture()
+	if err := prepareTemplate(tpl); err != nil {
+		t.Fatalf("prepareTemplate failed: %v", err)
+	}
+	planner := &Planner{ID: "p1", CreatedAt: time.Now(), Lanes: []PlannerLane{}, Columns: []PlannerColumn{}}
+	instantiateTemplate(planner, tpl)
+
+	if len(planner.Lanes) != 2 || planner.Lanes[0].TemplateLaneID != "tl-todo" || planner.Lanes[1].Color != "#00ff00" {
+		t.Fatalf("unexpected lanes: %+v", planner.Lanes)
+	}
+	if planner.Lanes[0].ID == "tl-todo" || planner.Lanes[0].PlannerID != "p1" {
+		t.Errorf("lane should get a fresh ID on the planner")
+	}
+	if len(planner.Columns) != 2 || planner.Columns[0].ID == "tc-status" {
+		t.Fatalf("unexpected columns: %+v", planner.Columns)
+	}
+
+	cards := planner.Lanes[0].Cards
+	if len(cards) != 2 || cards[0].LaneID != planner.Lanes[0].ID {
+		t.Fatalf("unexpected cards: %+v", cards)
+	}
+	if cards[0].Fields[planner.Columns[0].ID] != "Open" {
+		t.Errorf("column value not remapped: %+v", cards[0].Fields)
+	}
+	if len(cards[0].Checklist) != 2 || cards[0].Checklist[1].Text != "review" || cards[0].Checklist[0].ID == "" {
+		t.Errorf("checklist not materialized: %+v", cards[0].Checklist)
+	}
+}
+
+func TestLoadBuiltinTemplates(t *testing.T) {
+	templates, err := loadBuiltinTemplates()
+	if err != nil {
+		t.Fatalf("loadBuiltinTemplates failed: %v", err)
+	}
+	slugs := map[string]*PlannerTemplate{}
+	for _, tpl := range templates {
+		tpl.ID = "builtin-" + tpl.Slug
+		if err := prepareTemplate(tpl); err != nil {
+			t.Errorf("%s: %v", tpl.Slug, err)
+		}
+		if tpl.Scope != TemplateScopeBuiltIn || tpl.Version < 1 {
+			t.Errorf("%s: scope %q version %d", tpl.Slug, tpl.Scope, tpl.Version)
+		}
+		slugs[tpl.Slug] = tpl
+	}
+	scrum, ok := slugs["scrum"]
+	if !ok {
+		t.Fatalf("scrum template missing, have %v", slugs)
+	}
+	if got := scrum.Lanes[len(scrum.Lanes)-1].Name; len(scrum.Lanes) != 5 || got != "Done" {
+		t.Errorf("unexpected scrum lanes: %+v", scrum.Lanes)
+	}
+}
+
+func TestTemplatePackage_RoundTrip(t *testing.T) {
+	tpl := templateFixture()
+	if err := prepareTemplate(tpl); err != nil {
+		t.Fatalf("prepareTemplate failed: %v", err)
+	}
+	tpl.OwnerID = "user-1"
+	tpl.Scope = TemplateScopeUser
+
+	data, err := json.Marshal(NewTemplatePackage(tpl))
+	if err != nil {
+		t.Fatalf("marshal failed: %v", err)
+	}
+	if strings.Contains(string(data), "user-1") || strings.Contains(string(data), "owner_id") {
+		t.Errorf("package should not carry ownership: %s", data)
+	}
+
+	pkg, err := ParseTemplatePackage(data)
+	if err != nil {
+		t.Fatalf("ParseTemplatePackage failed: %v", err)
+	}
+	back := pkg.Template()
+	back.ID = "imported"
+	if err := prepareTemplate(back); err != nil {
+		t.Fatalf("imported template invalid: %v", err)
+	}
+	if back.Name != tpl.Name || len(back.Lanes) != 2 || back.Lanes[0].Color != "#cccccc" || len(back.Cards) != 2 {
+		t.Errorf("round trip lost data: %+v", back)
+	}
+
+	for _, doc := range []string{
+		`{"format":"other","format_version":1,"name":"x"}`,
+		`{"format":"zurabase.template","format_version":99,"name":"x"}`,
+		`not json`,
+	} {
+		if _, err := ParseTemplatePackage([]byte(doc)); !errors.Is(err, ErrInvalidTemplate) {
+			t.Errorf("%s: expected ErrInvalidTemplate, got %v", doc, err)
+		}
+	}
+}
+
+func TestTemplateVisibilityFilter(t *testing.T) {
+	anonymous := templateVisibilityFilter("")["$or"].([]bson.M)
+	if len(anonymous) != 2 {
+		t.Errorf("anonymous users should only see shared templates, got %v", anonymous)
+	}
+	signedIn := templateVisibilityFilter("u1")["$or"].([]bson.M)
+	if len(signedIn) != 3 || signedIn[2]["scope"] != TemplateScopeUser || signedIn[2]["ownerid"] != "u1" {
+		t.Errorf("expected the user's own templates to be visible, got %v", signedIn)
+	}
+}
+
+func TestCheckTemplateEditable(t *testing.T) {
+	owner := context.WithValue(context.Background(), "user_id", "u1")
+	other := context.WithValue(context.Background(), "user_id", "u2")
+	cases := []struct {
+		ctx  context.Context
+		tpl  PlannerTemplate
+		want bool
+	}{
+		{owner, PlannerTemplate{Scope: TemplateScopeUser, OwnerID: "u1"}, true},
+		{owner, PlannerTemplate{Scope: TemplateScopeWorkspace, OwnerID: "u1"}, true},
+		{other, PlannerTemplate{Scope: TemplateScopeWorkspace, OwnerID: "u1"}, false},
+		{owner, PlannerTemplate{Scope: TemplateScopeBuiltIn, OwnerID: "u1"}, false},
+		{context.Background(), PlannerTemplate{Scope: TemplateScopeUser}, false},
+	}
+	for i, c := range cases {
+		err := checkTemplateEditable(c.ctx, &c.tpl)
+		if c.want && err != nil {
+			t.Errorf("case %d: expected editable, got %v", i, err)
+		}
+		if !c.want && !errors.Is(err, ErrForbidden) {
+			t.Errorf("case %d: expected ErrForbidden, got %v", i, err)
+		}
+	}
+}
+
+func TestCreateTemplateValidation(t *testing.T) {
+	ctx := context.WithValue(context.Background(), "user_id", "u1")
+	if _, err := CreateTemplate(context.Background(), templateFixture()); !errors.Is(err, ErrForbidden) {
+		t.Errorf("expected anonymous users to be rejected, got %v", err)
+	}
+	tpl := templateFixture()
+	tpl.Scope = TemplateScopeBuiltIn
+	if _, err := CreateTemplate(ctx, tpl); !errors.Is(err, ErrInvalidTemplate) {
+		t.Errorf("expected built-in scope to be rejected, got %v", err)
+	}
+	tpl = templateFixture()
+	tpl.Lanes[0].Name = ""
+	if _, err := CreateTemplate(ctx, tpl); !errors.Is(err, ErrInvalidTemplate) {
+		t.Errorf("expected an invalid template to be rejected, got %v", err)
+	}
+
+	if scope, err := normalizeTemplateScope(""); err != nil || scope != TemplateScopeUser {
+		t.Errorf("expected templates to default to the user scope, got %q (%v)", scope, err)
+	}
+}
+
+func TestTemplateIDFromPath(t *testing.T) {
+	for path, want := range map[string]string{
+		"/planner/templates/t1":     "t1",
+		"/api/planner/templates/t1": "t1",
+		"/planner/templates/":       "",
+		"/planner/templates/t1/x":   "",
+		"/planner/t1":               "",
+	} {
+		id, ok := templateIDFromPath(path)
+		if ok != (want != "") || (ok && id != want) {
+			t.Errorf("%s: expected %q, got %q (%v)", path, want, id, ok)
+		}
+	}
+}
+
+func TestTemplateErrorStatus(t *testing.T) {
+	cases := map[error]int{
+		ErrInvalidTemplate:            http.StatusBadRequest,
+		ErrForbidden:                  http.StatusForbidden,
+		ErrTemplateNotFound:           http.StatusNotFound,
+		mongo.ErrNoDocuments:          http.StatusNotFound,
+		errors.New("connection lost"): http.StatusInternalServerError,
+	}
+	for err, want := range cases {
+		if got := templateErrorStatus(err); got != want {
+			t.Errorf("%v: expected %d, got %d", err, want, got)
+		}
+	}
+}
+
+func TestBuiltinTemplateUpsert(t *testing.T) {
+	tpl := templateFixture()
+	tpl.Slug = "release"
+	tpl.Version = 3
+	now := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	filter, update := builtinTemplateUpsert(tpl, now)
+	if filter["scope"] != TemplateScopeBuiltIn || filter["slug"] != "release" {
+		t.Errorf("expected the upsert to be keyed on scope and slug, got %v", filter)
+	}
+	if older := filter["$or"].([]bson.M)[0]["version"].(bson.M)["$lt"]; older != 3 {
+		t.Errorf("expected only older versions to be replaced, got %v", filter)
+	}
+	if _, ok := update["$set"].(bson.M)["id"]; ok {
+		t.Error("an existing template must keep its ID")
+	}
+	if insert := update["$setOnInsert"].(bson.M); insert["id"] != "tpl" || insert["createdat"] != now {
+		t.Errorf("unexpected $setOnInsert %v", insert)
+	}
+}
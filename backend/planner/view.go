@@ -0,0 +1,861 @@
+package planner
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Saved view scopes
+const (
+	ViewScopePlanner = "planner" // visible to every planner member
+	ViewScopeUser    = "user"    // visible to its owner only
+)
+
+var (
+	// ErrInvalidFilter is returned when a card filter or sort can't be parsed
+	ErrInvalidFilter = errors.New("invalid filter")
+	// ErrInvalidView is returned when a saved view is malformed
+	ErrInvalidView = errors.New("invalid view")
+)
+
+var viewCollection *mongo.Collection
+
+// PlannerView is a saved, named combination of filter, sort and grouping for a planner
+type PlannerView struct {
+	ID        string    `json:"id" bson:"id"`
+	PlannerID string    `json:"planner_id" bson:"planner_id"`
+	UserID    string    `json:"user_id" bson:"user_id"` // owner; for planner views the member who created it
+	Scope     string    `json:"scope" bson:"scope"`     // planner or user
+	Name      string    `json:"name" bson:"name"`
+	Filter    string    `json:"filter,omitempty" bson:"filter,omitempty"`
+	Sort      string    `json:"sort,omitempty" bson:"sort,omitempty"`
+	GroupBy   string    `json:"group_by,omitempty" bson:"group_by,omitempty"`
+	CreatedAt time.Time `json:"created_at" bson:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" bson:"updated_at"`
+}
+
+// filterTerm is one space-separated term of a filter, e.g. -label:bug or "Story Points":>3
+type filterTerm struct {
+	Negate bool
+	Key    string // empty for free text
+	Value  string
+}
+
+// cardSortKey is one key of a card sort, as an aggregation expression on $$card
+type cardSortKey struct {
+	Expr interface{}
+	Desc bool
+}
+
+// cardQuery filters and sorts the cards of a planner inside MongoDB
+type cardQuery struct {
+	Filter interface{} // aggregation expression on $$card and $$lane, nil to keep every card
+	Sort   []cardSortKey
+}
+
+// filterContext is what a filter is compiled against: the planner without its cards and the current user
+type filterContext struct {
+	planner *Planner
+	userID  string
+}
+
+// filterTokens splits a filter on spaces outside double quotes
+func filterTokens(filter string) ([]string, error) {
+	tokens := []string{}
+	var current strings.Builder
+	quoted := false
+	for _, r := range filter {
+		switch {
+		case r == '"':
+			quoted = !quoted
+			current.WriteRune(r)
+		case !quoted && (r == ' ' || r == '\t' || r == '\n'):
+			if current.Len() > 0 {
+				tokens = append(tokens, current.String())
+				current.Reset()
+			}
+		default:
+			current.WriteRune(r)
+		}
+	}
+	if quoted {
+		return nil, fmt.Errorf("%w: unterminated quote", ErrInvalidFilter)
+	}
+	if current.Len() > 0 {
+		tokens = append(tokens, current.String())
+	}
+	return tokens, nil
+}
+
+// parseFilterTerm parses one token of a filter
+func parseFilterTerm(token string) (filterTerm, error) {
+	term := filterTerm{}
+	if len(token) > 1 && token[0] == '-' {
+		term.Negate = true
+		token = token[1:]
+	}
+	colon := -1
+	inQuotes := false
+	for i, r := range token {
+		if r == '"' {
+			inQuotes = !inQuotes
+		} else if r == ':' && !inQuotes {
+			colon = i
+			break
+		}
+	}
+	if colon < 0 {
+		term.Value = strings.ReplaceAll(token, `"`, "")
+	} else {
+		term.Key = strings.ToLower(strings.ReplaceAll(token[:colon], `"`, ""))
+		term.Value = strings.ReplaceAll(token[colon+1:], `"`, "")
+		if term.Key == "" || term.Value == "" {
+			return term, fmt.Errorf("%w: %q needs a key and a value", ErrInvalidFilter, token)
+		}
+	}
+	return term, nil
+}
+
+// splitFilter splits a filter into terms on spaces outside double quotes
+func splitFilter(filter string) ([]filterTerm, error) {
+	tokens, err := filterTokens(filter)
+	if err != nil {
+		return nil, err
+	}
+	terms := []filterTerm{}
+	for _, token := range tokens {
+		term, err := parseFilterTerm(token)
+		if err != nil {
+			return nil, err
+		}
+		terms = append(terms, term)
+	}
+	return terms, nil
+}
+
+// splitValues splits a comma-separated list of alternatives
+func splitValues(value string) []string {
+	values := []string{}
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// comparison splits a value into an operator (=, >, >=, <, <= or ..) and its operands
+func comparison(value string) (string, string, string) {
+	for _, op := range []string{">=", "<=", ">", "<"} {
+		if strings.HasPrefix(value, op) {
+			return op, strings.TrimSpace(value[len(op):]), ""
+		}
+	}
+	if i := strings.Index(value, ".."); i >= 0 {
+		return "..", strings.TrimSpace(value[:i]), strings.TrimSpace(value[i+2:])
+	}
+	return "=", value, ""
+}
+
+func present(path string) bson.M {
+	return bson.M{"$ne": bson.A{bson.M{"$ifNull": bson.A{path, nil}}, nil}}
+}
+
+func missing(path string) bson.M {
+	return bson.M{"$eq": bson.A{bson.M{"$ifNull": bson.A{path, nil}}, nil}}
+}
+
+// anyOf matches cards whose array attribute holds at least one of the values
+func anyOf(path string, values []string) bson.M {
+	return bson.M{"$gt": bson.A{
+		bson.M{"$size": bson.M{"$setIntersection": bson.A{bson.M{"$ifNull": bson.A{path, bson.A{}}}, values}}}, 0,
+	}}
+}
+
+// dateCondition compares a date with whole UTC days. Date attributes are BSON dates; date columns are
+// YYYY-MM-DD or RFC 3339 strings, which compare correctly as strings.
+func dateCondition(path, value string, asString bool) (bson.M, error) {
+	if value == "none" {
+		return missing(path), nil
+	}
+	op, a, b := comparison(value)
+	parse := func(s string) (time.Time, error) {
+		t, err := parseDate(s)
+		if err != nil {
+			return t, fmt.Errorf("%w: %q is not a date", ErrInvalidFilter, s)
+		}
+		t = t.UTC()
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC), nil
+	}
+	bound := func(t time.Time) interface{} {
+		if asString {
+			return t.Format("2006-01-02")
+		}
+		return t
+	}
+	from, err := parse(a)
+	if err != nil {
+		return nil, err
+	}
+	conds := bson.A{present(path)}
+	switch op {
+	case "=":
+		conds = append(conds, bson.M{"$gte": bson.A{path, bound(from)}}, bson.M{"$lt": bson.A{path, bound(from.AddDate(0, 0, 1))}})
+	case ">":
+		conds = append(conds, bson.M{"$gte": bson.A{path, bound(from.AddDate(0, 0, 1))}})
+	case ">=":
+		conds = append(conds, bson.M{"$gte": bson.A{path, bound(from)}})
+	case "<":
+		conds = append(conds, bson.M{"$lt": bson.A{path, bound(from)}})
+	case "<=":
+		conds = append(conds, bson.M{"$lt": bson.A{path, bound(from.AddDate(0, 0, 1))}})
+	case "..":
+		to, err := parse(b)
+		if err != nil {
+			return nil, err
+		}
+		conds = append(conds, bson.M{"$gte": bson.A{path, bound(from)}}, bson.M{"$lt": bson.A{path, bound(to.AddDate(0, 0, 1))}})
+	}
+	return bson.M{"$and": conds}, nil
+}
+
+// numberCondition compares a number column
+func numberCondition(path, value string) (bson.M, error) {
+	if value == "none" {
+		return missing(path), nil
+	}
+	op, a, b := comparison(value)
+	parse := func(s string) (float64, error) {
+		n, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return 0, fmt.Errorf("%w: %q is not a number", ErrInvalidFilter, s)
+		}
+		return n, nil
+	}
+	if op == "=" {
+		nums := bson.A{}
+		for _, v := range splitValues(a) {
+			n, err := parse(v)
+			if err != nil {
+				return nil, err
+			}
+			nums = append(nums, n)
+		}
+		return bson.M{"$in": bson.A{path, nums}}, nil
+	}
+	n, err := parse(a)
+	if err != nil {
+		return nil, err
+	}
+	conds := bson.A{present(path)}
+	switch op {
+	case ">":
+		conds = append(conds, bson.M{"$gt": bson.A{path, n}})
+	case ">=":
+		conds = append(conds, bson.M{"$gte": bson.A{path, n}})
+	case "<":
+		conds = append(conds, bson.M{"$lt": bson.A{path, n}})
+	case "<=":
+		conds = append(conds, bson.M{"$lte": bson.A{path, n}})
+	case "..":
+		m, err := parse(b)
+		if err != nil {
+			return nil, err
+		}
+		conds = append(conds, bson.M{"$gte": bson.A{path, n}}, bson.M{"$lte": bson.A{path, m}})
+	}
+	return bson.M{"$and": conds}, nil
+}
+
+// textCondition matches a case-insensitive substring
+func textCondition(path, text string) bson.M {
+	return bson.M{"$regexMatch": bson.M{
+		"input":   bson.M{"$toString": bson.M{"$ifNull": bson.A{path, ""}}},
+		"regex":   regexp.QuoteMeta(text),
+		"options": "i",
+	}}
+}
+
+// laneCondition matches cards in the given lanes
+func laneCondition(laneIDs []string) bson.M {
+	return bson.M{"$in": bson.A{"$$lane.id", laneIDs}}
+}
+
+// findColumn returns the column with the given ID or (case-insensitive) name
+func findColumn(planner *Planner, key string) *PlannerColumn {
+	for i := range planner.Columns {
+		if planner.Columns[i].ID == key || strings.EqualFold(planner.Columns[i].Name, key) {
+			return &planner.Columns[i]
+		}
+	}
+	return nil
+}
+
+// users resolves "me" to the current user
+func (fc filterContext) users(values []string) []string {
+	result := []string{}
+	for _, v := range values {
+		if v == "me" {
+			v = fc.userID
+		}
+		result = append(result, v)
+	}
+	return result
+}
+
+// compileTerm turns a filter term into an aggregation expression on $$card
+func (fc filterContext) compileTerm(term filterTerm) (bson.M, error) {
+	values := splitValues(term.Value)
+	switch term.Key {
+	case "":
+		return bson.M{"$or": bson.A{
+			textCondition("$$card.fields."+FieldTitle, term.Value),
+			textCondition("$$card.fields."+FieldContent, term.Value),
+		}}, nil
+	case "label", "labels":
+		if term.Value == "none" {
+			return bson.M{"$eq": bson.A{bson.M{"$size": bson.M{"$ifNull": bson.A{"$$card.labels", bson.A{}}}}, 0}}, nil
+		}
+		return anyOf("$$card.labels", values), nil
+	case "assignee", "assignees":
+		if term.Value == "none" {
+			return bson.M{"$eq": bson.A{bson.M{"$size": bson.M{"$ifNull": bson.A{"$$card.assignees", bson.A{}}}}, 0}}, nil
+		}
+		return anyOf("$$card.assignees", fc.users(values)), nil
+	case "priority":
+		if term.Value == "none" {
+			return missing("$$card.priority"), nil
+		}
+		for _, v := range values {
+			if !isValidPriority(v) {
+				return nil, fmt.Errorf("%w: priority must be one of low, medium, high, urgent", ErrInvalidFilter)
+			}
+		}
+		return bson.M{"$in": bson.A{"$$card.priority", values}}, nil
+	case "lane":
+		ids := []string{}
+		for _, v := range values {
+			found := false
+			for _, lane := range fc.planner.Lanes {
+				if lane.ID == v || strings.EqualFold(lane.Title, v) {
+					ids = append(ids, lane.ID)
+					found = true
+				}
+			}
+			if !found {
+				return nil, fmt.Errorf("%w: unknown lane %q", ErrInvalidFilter, v)
+			}
+		}
+		return laneCondition(ids), nil
+	case "category":
+		ids := []string{}
+		for _, v := range values {
+			if v == "" || !isValidLaneCategory(v) {
+				return nil, fmt.Errorf("%w: category must be backlog, todo, in-progress or done", ErrInvalidFilter)
+			}
+			for i := range fc.planner.Lanes {
+				if laneCategory(&fc.planner.Lanes[i]) == v {
+					ids = append(ids, fc.planner.Lanes[i].ID)
+				}
+			}
+		}
+		return laneCondition(ids), nil
+	case "sprint":
+		if term.Value == "none" {
+			return missing("$$card.sprint_id"), nil
+		}
+		// Like lanes, unknown sprints are an error; "active" is always valid and matches nothing without an active sprint
+		ids := []string{}
+		for _, v := range values {
+			found := v == SprintStatusActive
+			for _, s := range fc.planner.Sprints {
+				if s.ID == v || strings.EqualFold(s.Name, v) || (v == SprintStatusActive && s.Status == SprintStatusActive) {
+					ids = append(ids, s.ID)
+					found = true
+				}
+			}
+			if !found {
+				return nil, fmt.Errorf("%w: unknown sprint %q", ErrInvalidFilter, v)
+			}
+		}
+		return bson.M{"$in": bson.A{"$$card.sprint_id", ids}}, nil
+	case "due":
+		return dateCondition("$$card.due_date", term.Value, false)
+	case "start":
+		return dateCondition("$$card.start_date", term.Value, false)
+	case "created":
+		return dateCondition("$$card.created_at", term.Value, false)
+	case "updated":
+		return dateCondition("$$card.updated_at", term.Value, false)
+	}
+
+	col := findColumn(fc.planner, term.Key)
+	if col == nil {
+		return nil, fmt.Errorf("%w: unknown key %q", ErrInvalidFilter, term.Key)
+	}
+	path := "$$card.fields." + col.ID
+	switch col.Type {
+	case ColumnTypeNumber:
+		return numberCondition(path, term.Value)
+	case ColumnTypeDate:
+		return dateCondition(path, term.Value, true)
+	case ColumnTypeText:
+		if term.Value == "none" {
+			return bson.M{"$in": bson.A{bson.M{"$ifNull": bson.A{path, ""}}, bson.A{""}}}, nil
+		}
+		return textCondition(path, term.Value), nil
+	}
+	if term.Value == "none" {
+		return bson.M{"$in": bson.A{bson.M{"$ifNull": bson.A{path, ""}}, bson.A{""}}}, nil
+	}
+	if col.Type == ColumnTypeUser {
+		values = fc.users(values)
+	}
+	return bson.M{"$in": bson.A{path, values}}, nil
+}
+
+// compileFilter turns a filter into an aggregation expression on $$card and $$lane; all terms must match.
+// It returns nil for an empty filter.
+func (fc filterContext) compileFilter(filter string) (interface{}, error) {
+	terms, err := splitFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+	if len(terms) == 0 {
+		return nil, nil
+	}
+	conds := bson.A{}
+	for _, term := range terms {
+		cond, err := fc.compileTerm(term)
+		if err != nil {
+			return nil, err
+		}
+		if term.Negate {
+			cond = bson.M{"$not": bson.A{cond}}
+		}
+		conds = append(conds, cond)
+	}
+	return bson.M{"$and": conds}, nil
+}
+
+// compileSort turns a comma-separated list of sort keys, each optionally prefixed with - for descending,
+// into aggregation expressions. Keys are title, priority, due, start, created, updated, position or a column.
+func (fc filterContext) compileSort(sort string) ([]cardSortKey, error) {
+	keys := []cardSortKey{}
+	for _, key := range splitValues(sort) {
+		desc := strings.HasPrefix(key, "-")
+		key = strings.TrimPrefix(key, "-")
+		var expr interface{}
+		switch strings.ToLower(key) {
+		case "position":
+			expr = "$$card.position"
+		case "title":
+			expr = bson.M{"$toLower": bson.M{"$ifNull": bson.A{"$$card.fields." + FieldTitle, ""}}}
+		case "priority":
+			expr = bson.M{"$switch": bson.M{
+				"branches": bson.A{
+					bson.M{"case": bson.M{"$eq": bson.A{"$$card.priority", PriorityLow}}, "then": 1},
+					bson.M{"case": bson.M{"$eq": bson.A{"$$card.priority", PriorityMedium}}, "then": 2},
+					bson.M{"case": bson.M{"$eq": bson.A{"$$card.priority", PriorityHigh}}, "then": 3},
+					bson.M{"case": bson.M{"$eq": bson.A{"$$card.priority", PriorityUrgent}}, "then": 4},
+				},
+				"default": nil,
+			}}
+		case "due", "due_date":
+			expr = "$$card.due_date"
+		case "start", "start_date":
+			expr = "$$card.start_date"
+		case "created", "created_at":
+			expr = "$$card.created_at"
+		case "updated", "updated_at":
+			expr = "$$card.updated_at"
+		default:
+			col := findColumn(fc.planner, key)
+			if col == nil {
+				return nil, fmt.Errorf("%w: unknown sort key %q", ErrInvalidFilter, key)
+			}
+			expr = "$$card.fields." + col.ID
+			if col.Type == ColumnTypeText || col.Type == ColumnTypeStatus {
+				expr = bson.M{"$cond": bson.A{
+					present(expr.(string)),
+					bson.M{"$toLower": expr},
+					nil,
+				}}
+			}
+		}
+		keys = append(keys, cardSortKey{Expr: expr, Desc: desc})
+	}
+	return keys, nil
+}
+
+// pipeline returns the aggregation that loads a planner with its cards filtered and sorted.
+// It also returns the number of active cards of each lane as lane_card_counts, so WIP limits still count every card.
+func (q *cardQuery) pipeline(plannerID string) []bson.M {
+	laneCards := bson.M{"$ifNull": bson.A{"$$lane.cards", bson.A{}}}
+	var cards interface{} = laneCards
+	if q.Filter != nil {
+		cards = bson.M{"$filter": bson.M{"input": laneCards, "as": "card", "cond": q.Filter}}
+	}
+	unset := []string{}
+	if len(q.Sort) > 0 {
+		keys := bson.M{}
+		sortBy := bson.D{}
+		for i, key := range q.Sort {
+			m, s := fmt.Sprintf("_sort_missing_%d", i), fmt.Sprintf("_sort_%d", i)
+			keys[s] = key.Expr
+			keys[m] = bson.M{"$cond": bson.A{bson.M{"$eq": bson.A{bson.M{"$ifNull": bson.A{key.Expr, nil}}, nil}}, 1, 0}}
+			dir := 1
+			if key.Desc {
+				dir = -1
+			}
+			// Cards without a value go last in either direction
+			sortBy = append(sortBy, bson.E{Key: m, Value: 1}, bson.E{Key: s, Value: dir})
+			unset = append(unset, "lanes.cards."+m, "lanes.cards."+s)
+		}
+		sortBy = append(sortBy, bson.E{Key: "position", Value: 1})
+		cards = bson.M{"$sortArray": bson.M{
+			"input":  bson.M{"$map": bson.M{"input": cards, "as": "card", "in": bson.M{"$mergeObjects": bson.A{"$$card", keys}}}},
+			"sortBy": sortBy,
+		}}
+	}
+
+	pipeline := []bson.M{
+		{"$match": bson.M{"id": plannerID}},
+		{"$set": bson.M{
+			"lane_card_counts": bson.M{"$map": bson.M{
+				"input": bson.M{"$ifNull": bson.A{"$lanes", bson.A{}}},
+				"as":    "lane",
+				"in": bson.M{
+					"id": "$$lane.id",
+					"count": bson.M{"$size": bson.M{"$filter": bson.M{
+						"input": laneCards,
+						"as":    "card",
+						"cond":  missing("$$card.archived_at"),
+					}}},
+				},
+			}},
+			"lanes": bson.M{"$map": bson.M{
+				"input": bson.M{"$ifNull": bson.A{"$lanes", bson.A{}}},
+				"as":    "lane",
+				"in":    bson.M{"$mergeObjects": bson.A{"$$lane", bson.M{"cards": cards}}},
+			}},
+		}},
+	}
+	if len(unset) > 0 {
+		pipeline = append(pipeline, bson.M{"$unset": unset})
+	}
+	return pipeline
+}
+
+// plannerOutline returns a planner without its cards, which is enough to compile filters
+func plannerOutline(ctx context.Context, plannerID string) (*Planner, error) {
+	var planner Planner
+	opts := options.FindOne().SetProjection(bson.M{"lanes.cards": 0})
+	if err := plannerCollection.FindOne(ctx, bson.M{"id": plannerID}, opts).Decode(&planner); err != nil {
+		return nil, err
+	}
+	return &planner, nil
+}
+
+// getView returns a saved view of a planner if the user can see it
+func getView(ctx context.Context, plannerID, userID, viewID string) (*PlannerView, error) {
+	var view PlannerView
+	filter := bson.M{"id": viewID, "planner_id": plannerID, "$or": []bson.M{
+		{"scope": ViewScopePlanner},
+		{"scope": ViewScopeUser, "user_id": userID},
+	}}
+	if err := viewCollection.FindOne(ctx, filter).Decode(&view); err != nil {
+		return nil, err
+	}
+	return &view, nil
+}
+
+// GetPlannerView returns a planner with its cards filtered and sorted by MongoDB. The filter and sort come from
+// the saved view if one is given, unless filter or sort are set explicitly. The view is returned for its grouping.
+func GetPlannerView(ctx context.Context, plannerID, userID, viewID, filter, sort string) (*Planner, *PlannerView, error) {
+	log.Printf("Getting planner view: id=%s, viewID=%s, filter=%q, sort=%q", plannerID, viewID, filter, sort)
+
+	var view *PlannerView
+	if viewID != "" {
+		var err error
+		if view, err = getView(ctx, plannerID, userID, viewID); err != nil {
+			return nil, nil, err
+		}
+		if filter == "" {
+			filter = view.Filter
+		}
+		if sort == "" {
+			sort = view.Sort
+		}
+	}
+
+	outline, err := plannerOutline(ctx, plannerID)
+	if err != nil {
+		return nil, nil, err
+	}
+	fc := filterContext{planner: outline, userID: userID}
+	query := &cardQuery{}
+	if query.Filter, err = fc.compileFilter(filter); err != nil {
+		return nil, nil, err
+	}
+	if query.Sort, err = fc.compileSort(sort); err != nil {
+		return nil, nil, err
+	}
+
+	planner, err := queryPlanner(ctx, plannerID, false, query)
+	if err != nil {
+		return nil, nil, err
+	}
+	planner.ViewID = viewID
+	planner.Filter = filter
+	planner.Sort = sort
+	return planner, view, nil
+}
+
+// GetViews returns the planner's shared views and the user's own views
+func GetViews(ctx context.Context, plannerID, userID string) ([]PlannerView, error) {
+	log.Printf("Getting views: plannerID=%s", plannerID)
+
+	cur, err := viewCollection.Find(ctx,
+		bson.M{"planner_id": plannerID, "$or": []bson.M{
+			{"scope": ViewScopePlanner},
+			{"scope": ViewScopeUser, "user_id": userID},
+		}},
+		options.Find().SetSort(bson.D{{Key: "name", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+	views := []PlannerView{}
+	if err := cur.All(ctx, &views); err != nil {
+		return nil, err
+	}
+	return views, nil
+}
+
+// validateView checks a view's name and scope and that its filter, sort and grouping apply to the planner
+func validateView(planner *Planner, view *PlannerView) error {
+	view.Name = strings.TrimSpace(view.Name)
+	if view.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidView)
+	}
+	if view.Scope == "" {
+		view.Scope = ViewScopeUser
+	}
+	if view.Scope != ViewScopeUser && view.Scope != ViewScopePlanner {
+		return fmt.Errorf("%w: scope must be %q or %q", ErrInvalidView, ViewScopeUser, ViewScopePlanner)
+	}
+	fc := filterContext{planner: planner}
+	if _, err := fc.compileFilter(view.Filter); err != nil {
+		return err
+	}
+	if _, err := fc.compileSort(view.Sort); err != nil {
+		return err
+	}
+	if view.GroupBy != "" && view.GroupBy != GroupByNone {
+		if _, err := resolveGroupBy(planner, view.GroupBy); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidView, err)
+		}
+	}
+	return nil
+}
+
+// dropColumnFromView removes the filter terms, sort keys and grouping of a view that applied to a column
+// of before but no longer apply to after, the same planner without that column. It reports whether the view changed.
+func dropColumnFromView(before, after *Planner, view *PlannerView) bool {
+	old, current := filterContext{planner: before}, filterContext{planner: after}
+	changed := false
+	if tokens, err := filterTokens(view.Filter); err == nil {
+		kept := []string{}
+		for _, token := range tokens {
+			if term, err := parseFilterTerm(token); err == nil {
+				if _, err := old.compileTerm(term); err == nil {
+					if _, err := current.compileTerm(term); err != nil {
+						changed = true
+						continue
+					}
+				}
+			}
+			kept = append(kept, token)
+		}
+		if changed {
+			view.Filter = strings.Join(kept, " ")
+		}
+	}
+	keys := []string{}
+	sortChanged := false
+	for _, key := range splitValues(view.Sort) {
+		if _, err := old.compileSort(key); err == nil {
+			if _, err := current.compileSort(key); err != nil {
+				sortChanged = true
+				continue
+			}
+		}
+		keys = append(keys, key)
+	}
+	if sortChanged {
+		view.Sort = strings.Join(keys, ",")
+		changed = true
+	}
+	if view.GroupBy != "" && view.GroupBy != GroupByNone {
+		if _, err := resolveGroupBy(before, view.GroupBy); err == nil {
+			if _, err := resolveGroupBy(after, view.GroupBy); err != nil {
+				view.GroupBy = ""
+				changed = true
+			}
+		}
+	}
+	return changed
+}
+
+// canEditView reports whether a user may change a view: its owner, or the planner owner for shared views
+func canEditView(planner *Planner, view *PlannerView, userID string) bool {
+	return view.UserID == userID || (view.Scope == ViewScopePlanner && planner.UserID == userID)
+}
+
+// SaveView creates a view, or replaces one the user may edit
+func SaveView(ctx context.Context, plannerID, userID string, view PlannerView) (*PlannerView, error) {
+	log.Printf("Saving view: plannerID=%s, viewID=%s", plannerID, view.ID)
+
+	planner, err := plannerOutline(ctx, plannerID)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateView(planner, &view); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	view.PlannerID = plannerID
+	view.UpdatedAt = now
+	if view.ID == "" {
+		view.ID = GenerateID()
+		view.UserID = userID
+		view.CreatedAt = now
+		if _, err := viewCollection.InsertOne(ctx, view); err != nil {
+			return nil, err
+		}
+	} else {
+		existing, err := getView(ctx, plannerID, userID, view.ID)
+		if err != nil {
+			return nil, err
+		}
+		if !canEditView(planner, existing, userID) {
+			return nil, fmt.Errorf("%w: only the view's owner can change it", ErrForbidden)
+		}
+		view.UserID = existing.UserID
+		view.CreatedAt = existing.CreatedAt
+		if _, err := viewCollection.ReplaceOne(ctx, bson.M{"id": view.ID}, view); err != nil {
+			return nil, err
+		}
+	}
+	if view.Scope == ViewScopePlanner {
+		publishEvent(ctx, plannerID, EventViewsUpdated, view)
+	}
+	return &view, nil
+}
+
+// DeleteView removes a view the user may edit
+func DeleteView(ctx context.Context, plannerID, userID, viewID string) error {
+	log.Printf("Deleting view: plannerID=%s, viewID=%s", plannerID, viewID)
+
+	planner, err := plannerOutline(ctx, plannerID)
+	if err != nil {
+		return err
+	}
+	view, err := getView(ctx, plannerID, userID, viewID)
+	if err != nil {
+		return err
+	}
+	if !canEditView(planner, view, userID) {
+		return fmt.Errorf("%w: only the view's owner can delete it", ErrForbidden)
+	}
+	if _, err := viewCollection.DeleteOne(ctx, bson.M{"id": viewID}); err != nil {
+		return err
+	}
+	if view.Scope == ViewScopePlanner {
+		publishEvent(ctx, plannerID, EventViewsUpdated, bson.M{"id": viewID, "deleted": true})
+	}
+	return nil
+}
+
+// viewErrorStatus maps view and filter errors to HTTP status codes
+func viewErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, ErrInvalidView), errors.Is(err, ErrInvalidFilter), errors.Is(err, ErrInvalidGroupBy):
+		return http.StatusBadRequest
+	case errors.Is(err, ErrForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, mongo.ErrNoDocuments):
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
+
+// HandleViews handles GET and POST /planner/{id}/views and PUT and DELETE /planner/{id}/views/{viewId}
+func HandleViews(w http.ResponseWriter, r *http.Request) {
+	// Extract planner and view IDs from path
+	path := r.URL.Path
+	parts := strings.Split(path, "/")
+	if len(parts) < 4 || len(parts) > 5 || parts[1] != "planner" || parts[3] != "views" {
+		http.Error(w, "Invalid path", http.StatusBadRequest)
+		return
+	}
+	plannerID := parts[2]
+	viewID := ""
+	if len(parts) == 5 {
+		viewID = parts[4]
+	}
+	userID, _ := r.Context().Value("user_id").(string)
+
+	var response interface{}
+	switch {
+	case r.Method == http.MethodGet && viewID == "":
+		views, err := GetViews(r.Context(), plannerID, userID)
+		if err != nil {
+			http.Error(w, err.Error(), viewErrorStatus(err))
+			return
+		}
+		response = views
+	case (r.Method == http.MethodPost && viewID == "") || (r.Method == http.MethodPut && viewID != ""):
+		var view PlannerView
+		if err := json.NewDecoder(r.Body).Decode(&view); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		view.ID = viewID
+		saved, err := SaveView(r.Context(), plannerID, userID, view)
+		if err != nil {
+			http.Error(w, err.Error(), viewErrorStatus(err))
+			return
+		}
+		response = saved
+	case r.Method == http.MethodDelete && viewID != "":
+		if err := DeleteView(r.Context(), plannerID, userID, viewID); err != nil {
+			http.Error(w, err.Error(), viewErrorStatus(err))
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
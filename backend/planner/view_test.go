@@ -0,0 +1,159 @@
+package planner
+
+import (
+	"errors"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func viewFixture() *Planner {
+	return &Planner{
+		UserID: "owner",
+		Columns: []PlannerColumn{
+			{ID: "sp", Name: "Story Points", Type: ColumnTypeNumber},
+			{ID: "env", Name: "Environment", Type: ColumnTypeStatus},
+			{ID: "ship", Name: "Ship date", Type: ColumnTypeDate},
+		},
+		Lanes: []PlannerLane{
+			{ID: "l1", Title: "To Do"},
+			{ID: "l2", Title: "Review", Category: LaneCategoryInProgress},
+			{ID: "l3", Title: "Done"},
+		},
+		Sprints: []Sprint{{ID: "s1", Name: "Sprint 1", Status: SprintStatusActive}},
+	}
+}
+
+func TestSplitFilter(t *testing.T) {
+	terms, err := splitFilter(`label:bug,ui  -lane:"In Progress" "Story Points":>=3 login`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []filterTerm{
+		{Key: "label", Value: "bug,ui"},
+		{Negate: true, Key: "lane", Value: "In Progress"},
+		{Key: "story points", Value: ">=3"},
+		{Value: "login"},
+	}
+	if len(terms) != len(want) {
+		t.Fatalf("expected %d terms, got %+v", len(want), terms)
+	}
+	for i := range want {
+		if terms[i] != want[i] {
+			t.Errorf("term %d: expected %+v, got %+v", i, want[i], terms[i])
+		}
+	}
+	for _, bad := range []string{`label:"bug`, "label:", ":bug"} {
+		if _, err := splitFilter(bad); !errors.Is(err, ErrInvalidFilter) {
+			t.Errorf("%q: expected ErrInvalidFilter, got %v", bad, err)
+		}
+	}
+}
+
+func TestCompileFilter(t *testing.T) {
+	fc := filterContext{planner: viewFixture(), userID: "u1"}
+
+	cond, err := fc.compileTerm(filterTerm{Key: "category", Value: "done,in-progress"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := cond["$in"].(bson.A)[1].([]string)
+	if len(ids) != 2 || ids[0] != "l3" || ids[1] != "l2" {
+		t.Errorf("expected done and in-progress lanes, got %v", ids)
+	}
+	cond, _ = fc.compileTerm(filterTerm{Key: "assignee", Value: "me,u2"})
+	if got := cond["$gt"].(bson.A)[0].(bson.M)["$size"].(bson.M)["$setIntersection"].(bson.A)[1].([]string); got[0] != "u1" || got[1] != "u2" {
+		t.Errorf("expected me to resolve to the current user, got %v", got)
+	}
+	cond, _ = fc.compileTerm(filterTerm{Key: "sprint", Value: "active"})
+	if got := cond["$in"].(bson.A)[1].([]string); len(got) != 1 || got[0] != "s1" {
+		t.Errorf("expected the active sprint, got %v", got)
+	}
+	cond, _ = fc.compileTerm(filterTerm{Key: "ship date", Value: "2024-05-01..2024-05-31"})
+	and := cond["$and"].(bson.A)
+	if and[1].(bson.M)["$gte"].(bson.A)[1] != "2024-05-01" || and[2].(bson.M)["$lt"].(bson.A)[1] != "2024-06-01" {
+		t.Errorf("expected date column bounds as strings, got %v", and)
+	}
+
+	if expr, err := fc.compileFilter("  "); err != nil || expr != nil {
+		t.Errorf("expected no filter for blank input, got %v, %v", expr, err)
+	}
+	if _, err := fc.compileFilter(`-label:bug "Story Points":3..8 env:prod due:none login`); err != nil {
+		t.Errorf("expected valid filter, got %v", err)
+	}
+	for _, bad := range []string{"colour:red", "sprint:Nope", "priority:soon", "lane:Nowhere", "category:later", "due:tomorrow", "sp:>many"} {
+		if _, err := fc.compileFilter(bad); !errors.Is(err, ErrInvalidFilter) {
+			t.Errorf("%q: expected ErrInvalidFilter, got %v", bad, err)
+		}
+	}
+}
+
+func TestCompileSort(t *testing.T) {
+	fc := filterContext{planner: viewFixture()}
+	keys, err := fc.compileSort("-priority, due,Story Points")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 3 || !keys[0].Desc || keys[1].Desc || keys[1].Expr != "$$card.due_date" || keys[2].Expr != "$$card.fields.sp" {
+		t.Errorf("unexpected sort keys: %+v", keys)
+	}
+	if _, err := fc.compileSort("colour"); !errors.Is(err, ErrInvalidFilter) {
+		t.Errorf("expected ErrInvalidFilter for unknown sort key, got %v", err)
+	}
+
+	q := &cardQuery{Sort: keys}
+	pipeline := q.pipeline("p1")
+	if len(pipeline) != 3 {
+		t.Fatalf("expected match, set and unset stages, got %d", len(pipeline))
+	}
+	if unset := pipeline[2]["$unset"].([]string); len(unset) != 6 {
+		t.Errorf("expected the sort helper fields to be removed, got %v", unset)
+	}
+}
+
+func TestValidateView(t *testing.T) {
+	p := viewFixture()
+	view := PlannerView{Name: " Mine ", Filter: "assignee:me", Sort: "-due", GroupBy: GroupByPriority}
+	if err := validateView(p, &view); err != nil || view.Name != "Mine" || view.Scope != ViewScopeUser {
+		t.Errorf("expected valid user view, got %v (%+v)", err, view)
+	}
+	for name, v := range map[string]PlannerView{
+		"no name":   {Filter: "label:bug"},
+		"bad scope": {Name: "x", Scope: "team"},
+		"bad group": {Name: "x", GroupBy: "nothing"},
+	} {
+		if err := validateView(p, &v); !errors.Is(err, ErrInvalidView) {
+			t.Errorf("%s: expected ErrInvalidView, got %v", name, err)
+		}
+	}
+	if err := validateView(p, &PlannerView{Name: "x", Filter: "colour:red"}); !errors.Is(err, ErrInvalidFilter) {
+		t.Errorf("expected ErrInvalidFilter, got %v", err)
+	}
+
+	shared := &PlannerView{UserID: "u1", Scope: ViewScopePlanner}
+	if !canEditView(p, shared, "u1") || !canEditView(p, shared, "owner") || canEditView(p, shared, "u2") {
+		t.Error("shared views should be editable by their creator and the planner owner only")
+	}
+	if canEditView(p, &PlannerView{UserID: "u1", Scope: ViewScopeUser}, "owner") {
+		t.Error("personal views should only be editable by their owner")
+	}
+}
+
+func TestDropColumnFromView(t *testing.T) {
+	before := viewFixture()
+	after := viewFixture()
+	after.Columns = after.Columns[1:] // drop Story Points
+
+	view := PlannerView{Filter: `label:bug "Story Points":>3 -sp:none login`, Sort: "-sp,due", GroupBy: "sp"}
+	if !dropColumnFromView(before, after, &view) {
+		t.Fatal("expected the view to change")
+	}
+	if view.Filter != "label:bug login" || view.Sort != "due" || view.GroupBy != "" {
+		t.Errorf("unexpected view %+v", view)
+	}
+
+	untouched := PlannerView{Filter: "env:prod  colour:red", Sort: "title", GroupBy: "env"}
+	if dropColumnFromView(before, after, &untouched) || untouched.Filter != "env:prod  colour:red" {
+		t.Errorf("expected a view without the column to stay as it is, got %+v", untouched)
+	}
+}
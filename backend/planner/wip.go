@@ -0,0 +1,236 @@
+package planner
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// WIP limit enforcement modes
+const (
+	WIPModeOff   = "off"   // limits are ignored
+	WIPModeWarn  = "warn"  // over-limit lanes are flagged
+	WIPModeBlock = "block" // changes that would exceed a limit are rejected
+)
+
+// WIPLimitError is returned in block mode when a change would push a lane over its WIP limit
+type WIPLimitError struct {
+	LaneID  string   `json:"lane_id"`
+	LaneIDs []string `json:"lane_ids"` // lanes counted against the limit
+	Limit   int      `json:"limit"`
+	Count   int      `json:"count"` // cards the lanes would hold after the change
+}
+
+func (e *WIPLimitError) Error() string {
+	return fmt.Sprintf("WIP limit exceeded: lane %s would hold %d cards, limit is %d", e.LaneID, e.Count, e.Limit)
+}
+
+// wipGroup returns the lanes sharing a lane's WIP limit and the limit itself.
+// With shared limits, split lanes with the same template lane count together against the highest limit among them.
+func wipGroup(planner *Planner, lane *PlannerLane) ([]*PlannerLane, int) {
+	if !planner.Settings.SharedWIPLimits || lane.TemplateLaneID == "" {
+		return []*PlannerLane{lane}, lane.WIPLimit
+	}
+	group := []*PlannerLane{}
+	limit := 0
+	for i := range planner.Lanes {
+		if planner.Lanes[i].TemplateLaneID == lane.TemplateLaneID {
+			group = append(group, &planner.Lanes[i])
+			if planner.Lanes[i].WIPLimit > limit {
+				limit = planner.Lanes[i].WIPLimit
+			}
+		}
+	}
+	return group, limit
+}
+
+// checkWIPLimit returns a *WIPLimitError if moving the incoming cards into a lane would exceed its limit in block mode.
+// Cards already counted against the limit don't count twice, and changes that don't grow an over-limit lane are allowed.
+func checkWIPLimit(planner *Planner, laneID string, incoming []PlannerCard) error {
+	if planner.Settings.WIPMode != WIPModeBlock {
+		return nil
+	}
+	if err := exceedsWIPLimit(planner, laneID, incoming); err != nil {
+		return err
+	}
+	return nil
+}
+
+// wipLimitWarning returns the limit that moving the incoming cards into a lane exceeds in warn mode, for the response
+func wipLimitWarning(planner *Planner, laneID string, incoming []PlannerCard) *WIPLimitError {
+	if planner.Settings.WIPMode != WIPModeWarn {
+		return nil
+	}
+	return exceedsWIPLimit(planner, laneID, incoming)
+}
+
+// exceedsWIPLimit returns the limit that moving the incoming cards into a lane exceeds, whatever the mode
+func exceedsWIPLimit(planner *Planner, laneID string, incoming []PlannerCard) *WIPLimitError {
+	var lane *PlannerLane
+	for i := range planner.Lanes {
+		if planner.Lanes[i].ID == laneID {
+			lane = &planner.Lanes[i]
+			break
+		}
+	}
+	if lane == nil {
+		return nil
+	}
+	group, limit := wipGroup(planner, lane)
+	if limit <= 0 {
+		return nil
+	}
+
+	present := map[string]bool{}
+	laneIDs := []string{}
+	for _, l := range group {
+		laneIDs = append(laneIDs, l.ID)
+		if l.ArchivedAt != nil {
+			continue
+		}
+		for _, card := range l.Cards {
+			if card.ArchivedAt == nil {
+				present[card.ID] = true
+			}
+		}
+	}
+	count := len(present)
+	for _, card := range incoming {
+		if !present[card.ID] {
+			count++
+		}
+	}
+	if count > limit && count > len(present) {
+		return &WIPLimitError{LaneID: laneID, LaneIDs: laneIDs, Limit: limit, Count: count}
+	}
+	return nil
+}
+
+// wipLimitGuard returns a filter condition for a write that moves the incoming cards into a lane. In block mode
+// it only matches while they still fit under the lane's limit, counting the cards the lanes hold at write time,
+// so a change checked against an earlier read can't exceed the limit. It returns nil when no limit applies.
+func wipLimitGuard(planner *Planner, laneID string, incoming []PlannerCard) bson.M {
+	ids := []string{}
+	for _, card := range incoming {
+		ids = append(ids, card.ID)
+	}
+	return wipLimitGuardCount(planner, laneID, ids, len(ids))
+}
+
+// wipLimitGuardCount is wipLimitGuard for writes that place the cards with the given IDs, after of which
+// end up in the lane. The other active cards of the lanes sharing the limit plus after must fit under it,
+// unless the write doesn't grow the lanes.
+func wipLimitGuardCount(planner *Planner, laneID string, ids []string, after int) bson.M {
+	if planner.Settings.WIPMode != WIPModeBlock {
+		return nil
+	}
+	lane := findLane(planner, laneID)
+	if lane == nil {
+		return nil
+	}
+	group, limit := wipGroup(planner, lane)
+	if limit <= 0 {
+		return nil
+	}
+	laneIDs := []string{}
+	for _, l := range group {
+		laneIDs = append(laneIDs, l.ID)
+	}
+
+	active := func(v string) bson.M {
+		return bson.M{"$eq": bson.A{bson.M{"$ifNull": bson.A{v + ".archived_at", nil}}, nil}}
+	}
+	cards := bson.M{"$filter": bson.M{
+		"input": bson.M{"$reduce": bson.M{
+			"input": bson.M{"$filter": bson.M{
+				"input": bson.M{"$ifNull": bson.A{"$lanes", bson.A{}}},
+				"as":    "lane",
+				"cond":  bson.M{"$and": bson.A{bson.M{"$in": bson.A{"$$lane.id", laneIDs}}, active("$$lane")}},
+			}},
+			"initialValue": bson.A{},
+			"in":           bson.M{"$concatArrays": bson.A{"$$value", bson.M{"$ifNull": bson.A{"$$this.cards", bson.A{}}}}},
+		}},
+		"as":   "card",
+		"cond": active("$$card"),
+	}}
+	others := bson.M{"$size": bson.M{"$filter": bson.M{
+		"input": cards,
+		"as":    "card",
+		"cond":  bson.M{"$not": bson.A{bson.M{"$in": bson.A{"$$card.id", ids}}}},
+	}}}
+	count := bson.M{"$add": bson.A{others, after}}
+	return bson.M{"$expr": bson.M{"$or": bson.A{
+		bson.M{"$lte": bson.A{count, limit}},
+		bson.M{"$lte": bson.A{count, bson.M{"$size": cards}}},
+	}}}
+}
+
+// withWIPLimitGuard adds a WIP limit guard to a write filter
+func withWIPLimitGuard(filter, guard bson.M) bson.M {
+	if guard != nil {
+		filter["$expr"] = guard["$expr"]
+	}
+	return filter
+}
+
+// wipLimitConflict explains a guarded write that matched nothing: the WIP limit the incoming cards now exceed,
+// or ErrCardConflict if the lane or cards changed in another way
+func wipLimitConflict(ctx context.Context, plannerID, laneID string, incoming []PlannerCard) error {
+	var planner Planner
+	if err := plannerCollection.FindOne(ctx, bson.M{"id": plannerID}).Decode(&planner); err != nil {
+		return err
+	}
+	if err := checkWIPLimit(&planner, laneID, incoming); err != nil {
+		return err
+	}
+	return ErrCardConflict
+}
+
+// flagWIPLimits sets the WIP count and over-limit flag of lanes with a limit unless limits are off
+func flagWIPLimits(planner *Planner) {
+	counts := map[string]int{}
+	for _, l := range planner.Lanes {
+		counts[l.ID] = len(l.Cards)
+	}
+	flagWIPLimitCounts(planner, counts)
+}
+
+// flagWIPLimitCounts is flagWIPLimits with the number of cards in each lane given, for planners
+// whose cards have been filtered
+func flagWIPLimitCounts(planner *Planner, counts map[string]int) {
+	if planner.Settings.WIPMode == "" || planner.Settings.WIPMode == WIPModeOff {
+		return
+	}
+	for i := range planner.Lanes {
+		group, limit := wipGroup(planner, &planner.Lanes[i])
+		if limit <= 0 {
+			continue
+		}
+		count := 0
+		for _, l := range group {
+			count += counts[l.ID]
+		}
+		planner.Lanes[i].WIPCount = count
+		planner.Lanes[i].OverLimit = count > limit
+	}
+}
+
+// writeWIPLimitError writes a structured 409 response if err is a WIP limit violation
+func writeWIPLimitError(w http.ResponseWriter, err error) bool {
+	var wipErr *WIPLimitError
+	if !errors.As(err, &wipErr) {
+		return false
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusConflict)
+	json.NewEncoder(w).Encode(struct {
+		Error   string `json:"error"`
+		Message string `json:"message"`
+		*WIPLimitError
+	}{"wip_limit_exceeded", wipErr.Error(), wipErr})
+	return true
+}
@@ -0,0 +1,110 @@
+package planner
+
+import (
+	"errors"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func wipFixture(mode string, shared bool) *Planner {
+	return &Planner{
+		Settings: PlannerSettings{WIPMode: mode, SharedWIPLimits: shared},
+		Lanes: []PlannerLane{
+			{ID: "doing-a", TemplateLaneID: "doing", WIPLimit: 2, Cards: []PlannerCard{{ID: "c1"}, {ID: "c2"}}},
+			{ID: "doing-b", TemplateLaneID: "doing", WIPLimit: 3, Cards: []PlannerCard{{ID: "c3"}}},
+			{ID: "todo", Cards: []PlannerCard{{ID: "c4"}}},
+		},
+	}
+}
+
+func TestCheckWIPLimit(t *testing.T) {
+	incoming := []PlannerCard{{ID: "c4"}}
+
+	var wipErr *WIPLimitError
+	err := checkWIPLimit(wipFixture(WIPModeBlock, false), "doing-a", incoming)
+	if !errors.As(err, &wipErr) || wipErr.Limit != 2 || wipErr.Count != 3 {
+		t.Fatalf("expected WIP error for full lane, got %v", err)
+	}
+	if err := checkWIPLimit(wipFixture(WIPModeWarn, false), "doing-a", incoming); err != nil {
+		t.Errorf("warn mode should not block: %v", err)
+	}
+	if err := checkWIPLimit(wipFixture(WIPModeBlock, false), "doing-a", []PlannerCard{{ID: "c1"}}); err != nil {
+		t.Errorf("moving within a lane should be allowed: %v", err)
+	}
+	if err := checkWIPLimit(wipFixture(WIPModeBlock, false), "doing-b", incoming); err != nil {
+		t.Errorf("lane with room should accept the card: %v", err)
+	}
+
+	// Shared: doing-a and doing-b hold 3 cards against a limit of 3
+	err = checkWIPLimit(wipFixture(WIPModeBlock, true), "doing-b", incoming)
+	if !errors.As(err, &wipErr) || wipErr.Limit != 3 || len(wipErr.LaneIDs) != 2 {
+		t.Errorf("expected shared WIP error, got %v", err)
+	}
+	if err := checkWIPLimit(wipFixture(WIPModeBlock, true), "doing-b", []PlannerCard{{ID: "c1"}}); err != nil {
+		t.Errorf("moving between split lanes should be allowed: %v", err)
+	}
+}
+
+func TestFlagWIPLimits(t *testing.T) {
+	p := wipFixture(WIPModeOff, false)
+	p.Lanes[0].Cards = append(p.Lanes[0].Cards, PlannerCard{ID: "c5"})
+	flagWIPLimits(p)
+	if p.Lanes[0].OverLimit {
+		t.Errorf("limits are ignored when off")
+	}
+
+	p.Settings.WIPMode = WIPModeWarn
+	flagWIPLimits(p)
+	if !p.Lanes[0].OverLimit || p.Lanes[0].WIPCount != 3 || p.Lanes[1].OverLimit || p.Lanes[2].WIPCount != 0 {
+		t.Errorf("unexpected flags: %+v", p.Lanes)
+	}
+
+	p = wipFixture(WIPModeWarn, true)
+	p.Lanes[1].Cards = append(p.Lanes[1].Cards, PlannerCard{ID: "c5"})
+	flagWIPLimits(p)
+	if !p.Lanes[0].OverLimit || !p.Lanes[1].OverLimit || p.Lanes[1].WIPCount != 4 {
+		t.Errorf("shared limit should flag every lane of the group: %+v", p.Lanes)
+	}
+}
+
+func TestWIPLimitWarning(t *testing.T) {
+	incoming := []PlannerCard{{ID: "c4"}}
+	if w := wipLimitWarning(wipFixture(WIPModeWarn, false), "doing-a", incoming); w == nil || w.Count != 3 || w.Limit != 2 {
+		t.Errorf("expected a warning for the full lane, got %v", w)
+	}
+	if w := wipLimitWarning(wipFixture(WIPModeBlock, false), "doing-a", incoming); w != nil {
+		t.Errorf("block mode rejects instead of warning, got %v", w)
+	}
+	if w := wipLimitWarning(wipFixture(WIPModeWarn, false), "doing-b", incoming); w != nil {
+		t.Errorf("lane with room should not warn, got %v", w)
+	}
+}
+
+func TestWIPLimitGuard(t *testing.T) {
+	incoming := []PlannerCard{{ID: "c4"}}
+	if g := wipLimitGuard(wipFixture(WIPModeWarn, false), "doing-a", incoming); g != nil {
+		t.Errorf("only block mode guards writes, got %v", g)
+	}
+	if g := wipLimitGuard(wipFixture(WIPModeBlock, false), "todo", incoming); g != nil {
+		t.Errorf("lanes without a limit need no guard, got %v", g)
+	}
+
+	g := wipLimitGuard(wipFixture(WIPModeBlock, true), "doing-b", incoming)
+	or := g["$expr"].(bson.M)["$or"].(bson.A)
+	underLimit := or[0].(bson.M)["$lte"].(bson.A)
+	if underLimit[1] != 3 {
+		t.Errorf("expected the shared limit of 3, got %v", underLimit[1])
+	}
+	count := underLimit[0].(bson.M)["$add"].(bson.A)
+	if count[1] != 1 {
+		t.Errorf("expected one incoming card counted, got %v", count[1])
+	}
+	filter := withWIPLimitGuard(bson.M{"lanes.id": "doing-b"}, g)
+	if _, ok := filter["$expr"]; !ok || filter["lanes.id"] != "doing-b" {
+		t.Errorf("expected the guard added to the filter, got %v", filter)
+	}
+	if filter := withWIPLimitGuard(bson.M{"lanes.id": "todo"}, nil); len(filter) != 1 {
+		t.Errorf("expected the filter unchanged without a guard, got %v", filter)
+	}
+}
@@ -0,0 +1,112 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+type PlannerCard struct {
+	ID     string                 `json:"id"`
+	Fields map[string]interface{} `json:"fields"`
+}
+
+type PlannerLane struct {
+	ID    string        `json:"id"`
+	Title string        `json:"title"`
+	Cards []PlannerCard `json:"cards"`
+}
+
+type Planner struct {
+	ID    string        `json:"id"`
+	Lanes []PlannerLane `json:"lanes"`
+}
+
+type newLane struct {
+	Title    string `json:"title"`
+	Position int    `json:"position"`
+}
+
+type newCard struct {
+	Title string `json:"title"`
+}
+
+func createPlanner(ctx context.Context, t *testing.T, title string) *Planner {
+	return doPostRequest[Planner](ctx, t, "/planner", map[string]string{"title": title})
+}
+
+func addLane(ctx context.Context, t *testing.T, plannerID, title string, position int) *PlannerLane {
+	return doPostRequest[PlannerLane](ctx, t, "/planner/"+plannerID+"/lane", newLane{Title: title, Position: position})
+}
+
+func addCard(ctx context.Context, t *testing.T, plannerID, laneID, title string) *PlannerCard {
+	return doPostRequest[PlannerCard](ctx, t, "/planner/"+plannerID+"/lane/"+laneID+"/card", newCard{Title: title})
+}
+
+func deletePlannerByID(ctx context.Context, t *testing.T, id string) {
+	url := fmt.Sprintf("%s/planner/%s", getAPIEndpoint(), id)
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, url, nil)
+	if err != nil {
+		t.Fatalf("failed to create DELETE request: %v", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("failed DELETE request: %v", err)
+	}
+	defer resp.Body.Close()
+}
+
+func plannerQuery(id, filter, sort string) string {
+	return "/planner/" + id + "?" + url.Values{"filter": {filter}, "sort": {sort}}.Encode()
+}
+
+func TestGetPlanner_FilterAndSort(t *testing.T) {
+	ctx := context.Background()
+	planner := createPlanner(ctx, t, "Filter test")
+	defer deletePlannerByID(ctx, t, planner.ID)
+
+	todo := addLane(ctx, t, planner.ID, "To Do", 1)
+	done := addLane(ctx, t, planner.ID, "Done", 2)
+	addCard(ctx, t, planner.ID, todo.ID, "Alpha login")
+	addCard(ctx, t, planner.ID, todo.ID, "Gamma")
+	addCard(ctx, t, planner.ID, todo.ID, "Beta login")
+	addCard(ctx, t, planner.ID, done.ID, "Delta login")
+
+	got := doGetRequest[Planner](ctx, t, plannerQuery(planner.ID, "-lane:Done login", "-title"))
+	titles := map[string][]interface{}{}
+	for _, lane := range got.Lanes {
+		for _, card := range lane.Cards {
+			titles[lane.ID] = append(titles[lane.ID], card.Fields["title"])
+		}
+	}
+	if len(titles[todo.ID]) != 2 || titles[todo.ID][0] != "Beta login" || titles[todo.ID][1] != "Alpha login" {
+		t.Errorf("expected Beta login and Alpha login in To Do, got %v", titles[todo.ID])
+	}
+	if len(titles[done.ID]) != 0 {
+		t.Errorf("expected no cards in Done, got %v", titles[done.ID])
+	}
+}
+
+func TestGetPlanner_InvalidFilter(t *testing.T) {
+	ctx := context.Background()
+	planner := createPlanner(ctx, t, "Invalid filter test")
+	defer deletePlannerByID(ctx, t, planner.ID)
+
+	for _, filter := range []string{"sprint:Nope", "lane:Nowhere", "colour:red"} {
+		url := getAPIEndpoint() + plannerQuery(planner.ID, filter, "")
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			t.Fatalf("failed to create GET request: %v", err)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("failed GET request: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("expected 400 for filter %q, got %s", filter, resp.Status)
+		}
+	}
+}